@@ -1,10 +1,19 @@
 package goeloquent
 
 import (
-	"database/sql"
+	"database/sql/driver"
 	"reflect"
+	"time"
 )
 
+/*
+AttributeChange the original and current value of a changed attribute
+*/
+type AttributeChange struct {
+	Old interface{}
+	New interface{}
+}
+
 /*
 GetOrigin Get the original attribute values.
 */
@@ -13,60 +22,203 @@ func (m *EloquentModel) GetOrigin() map[string]interface{} {
 }
 
 /*
-GetChanges Get the attributes that were changed.
+GetOriginal Get the original value of an attribute,field can be struct field name or db column name,nil for unknown fields.
+*/
+func (m *EloquentModel) GetOriginal(field string) interface{} {
+	f := m.resolveField(field)
+	if f == nil {
+		return nil
+	}
+	return m.Origin[f.Name]
+}
+
+/*
+GetChanges Get the attributes that were changed by the last save.
 */
 func (m *EloquentModel) GetChanges() map[string]interface{} {
 	return m.Changes
 }
 
 /*
-GetDirty Get the attributes that have been changed since the last sync.
+WasChanged Determine if the model or any of the given attributes were changed by the last save.
+
+ 1. user.WasChanged()
+ 2. user.WasChanged("Name", "email")
+*/
+func (m *EloquentModel) WasChanged(fields ...string) bool {
+	if len(fields) == 0 {
+		return len(m.Changes) > 0
+	}
+	for _, field := range fields {
+		f := m.resolveField(field)
+		if f == nil {
+			continue
+		}
+		if _, ok := m.Changes[f.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+IsClean Determine if the model or all of the given attributes are unchanged since the last sync.
+
+ 1. user.IsClean()
+ 2. user.IsClean("Name", "email")
+*/
+func (m *EloquentModel) IsClean(fields ...string) bool {
+	if len(fields) == 0 {
+		return len(m.GetDirty()) == 0
+	}
+	for _, field := range fields {
+		if m.IsDirty(field) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Diff Get the original and current values of the attributes that have been changed since the last sync.
+
+ 1. user.Diff() map[string]AttributeChange{"Name": {Old: "john", New: "jack"}}
+*/
+func (m *EloquentModel) Diff() map[string]AttributeChange {
+	diff := make(map[string]AttributeChange)
+	for name, value := range m.GetDirty() {
+		diff[name] = AttributeChange{Old: m.Origin[name], New: value}
+	}
+	return diff
+}
+
+/*
+GetDirty Get the attributes that have been changed since the last sync,
+nil is returned if the model can't be parsed,the error is recorded on Err and returned by Save
 */
 func (m *EloquentModel) GetDirty() map[string]interface{} {
-	parsed := GetParsedModel(reflect.Indirect(m.ModelPointer).Type())
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		m.addError(err)
+		return nil
+	}
 	dirty := make(map[string]interface{})
 	current := reflect.Indirect(m.ModelPointer)
-	for key, _ := range m.Origin {
-		fieldIndex := parsed.FieldsByStructName[key].Index
-		currentField := current.Field(fieldIndex)
-		var equal bool
-		switch m.Origin[key].(type) {
-		case sql.NullString:
-			c := currentField.Interface().(sql.NullString)
-			o := m.Origin[key].(sql.NullString)
-			equal = c.Valid == o.Valid && c.String == o.String
-		case sql.NullBool:
-			c := currentField.Interface().(sql.NullBool)
-			o := m.Origin[key].(sql.NullBool)
-			equal = c.Valid == o.Valid && c.Bool == o.Bool
-		case sql.NullFloat64:
-			c := currentField.Interface().(sql.NullFloat64)
-			o := m.Origin[key].(sql.NullFloat64)
-			equal = c.Valid == o.Valid && c.Float64 == o.Float64
-		case sql.NullInt32:
-			c := currentField.Interface().(sql.NullInt32)
-			o := m.Origin[key].(sql.NullInt32)
-			equal = c.Valid == o.Valid && c.Int32 == o.Int32
-		case sql.NullInt64:
-			c := currentField.Interface().(sql.NullInt64)
-			o := m.Origin[key].(sql.NullInt64)
-			equal = c.Valid == o.Valid && c.Int64 == o.Int64
-		case sql.NullTime:
-			c := currentField.Interface().(sql.NullTime)
-			o := m.Origin[key].(sql.NullTime)
-			equal = c.Valid == o.Valid && c.Time == c.Time
-		default:
-			//check currentField is comparable
-			if currentField.Comparable() {
-				equal = currentField.Interface() == m.Origin[key]
-			} else {
-				equal = true
-			}
-
-		}
-		if !equal {
+	for key := range m.Origin {
+		field := parsed.FieldsByStructName[key]
+		currentField := current.Field(field.Index)
+		if m.fieldIsDirty(field, currentField) {
 			dirty[key] = currentField.Interface()
 		}
 	}
 	return dirty
 }
+
+/*
+resolveField get the model field by struct field name or db column name,nil is returned for unknown fields
+*/
+func (m *EloquentModel) resolveField(name string) *Field {
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return nil
+	}
+	if field, ok := parsed.FieldsByStructName[name]; ok {
+		return field
+	}
+	if field, ok := parsed.FieldsByDbName[name]; ok {
+		return field
+	}
+	return nil
+}
+
+func (m *EloquentModel) fieldIsDirty(field *Field, current reflect.Value) bool {
+	if field.Cast != nil {
+		return !castEqual(field, current, m.Origin[field.Name])
+	}
+	return !attributeEqual(current.Interface(), m.Origin[field.Name])
+}
+
+/*
+originValue copy a field value for the original attributes,so in place changes of slices/maps/pointers are detected
+*/
+func originValue(field *Field, value reflect.Value) interface{} {
+	if field.Cast != nil {
+		return castOrigin(field, value)
+	}
+	return copyAttribute(value).Interface()
+}
+
+func copyAttribute(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyAttribute(v.Index(i)))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), copyAttribute(iter.Value()))
+		}
+		return cp
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type().Elem())
+		cp.Elem().Set(copyAttribute(v.Elem()))
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(copyAttribute(v.Elem()))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if cp.Field(i).CanSet() {
+				cp.Field(i).Set(copyAttribute(v.Field(i)))
+			}
+		}
+		return cp
+	}
+	return v
+}
+
+/*
+attributeEqual deep compare two attribute values,driver.Valuer values are compared by their database values
+*/
+func attributeEqual(current, origin interface{}) bool {
+	current, origin = valuerValue(current), valuerValue(origin)
+	if c, ok := current.(time.Time); ok {
+		o, ok := origin.(time.Time)
+		return ok && c.Equal(o)
+	}
+	return reflect.DeepEqual(current, origin)
+}
+
+func valuerValue(value interface{}) interface{} {
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return value
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	v, err := valuer.Value()
+	if err != nil {
+		return value
+	}
+	return v
+}
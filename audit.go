@@ -0,0 +1,230 @@
+package goeloquent
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+const (
+	AuditCreated  = "created"
+	AuditUpdated  = "updated"
+	AuditDeleted  = "deleted"
+	AuditRestored = "restored"
+)
+
+/*
+AuditTable the table audit rows are written to,it's on the same connection as the audited model
+
+	create table audits (
+		id bigint auto_increment primary key,
+		auditable_type varchar(255),
+		auditable_id varchar(255),
+		event varchar(32),
+		old_values text,
+		new_values text,
+		user_id varchar(255) null,
+		created_at datetime
+	)
+*/
+var AuditTable = "audits"
+
+/*
+Auditable models implement this to write an audit row on every create/update/delete/restore,
+the rows are written with the model's Tx if there is one,otherwise the change and its audit row are written in a new transaction,
+so they are committed or rolled back together
+*/
+type Auditable interface {
+	EloquentAuditExclude() []string //columns that should not be recorded,like password
+}
+
+type auditUserContextKey struct{}
+
+/*
+WithAuditUser return a context carrying the acting user recorded in audit rows
+
+ 1. user.WithContext(WithAuditUser(ctx, admin.ID)).Save()
+*/
+func WithAuditUser(ctx context.Context, user interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, auditUserContextKey{}, user)
+}
+
+/*
+AuditUserFromContext get the acting user set by WithAuditUser
+*/
+func AuditUserFromContext(ctx context.Context) (user interface{}, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	user = ctx.Value(auditUserContextKey{})
+	return user, user != nil
+}
+
+/*
+auditAttributes get the database values of all recorded columns
+*/
+func (m *EloquentModel) auditAttributes() (attrs map[string]interface{}, err error) {
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return nil, err
+	}
+	model := reflect.Indirect(m.ModelPointer)
+	attrs = make(map[string]interface{}, len(parsed.FieldsByDbName))
+	for column, field := range parsed.FieldsByDbName {
+		if attrs[column], err = fieldValue(field, model.Field(field.Index)); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+/*
+auditDiff get the old and new database values of the changed columns
+*/
+func (m *EloquentModel) auditDiff() (old, new map[string]interface{}, err error) {
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return nil, nil, err
+	}
+	old = make(map[string]interface{})
+	new = make(map[string]interface{})
+	for name, change := range m.Diff() {
+		field := parsed.FieldsByStructName[name]
+		if old[field.ColumnName], err = auditValue(field, change.Old); err != nil {
+			return nil, nil, err
+		}
+		if new[field.ColumnName], err = auditValue(field, change.New); err != nil {
+			return nil, nil, err
+		}
+	}
+	return
+}
+
+func auditValue(field *Field, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return fieldValue(field, reflect.ValueOf(value))
+}
+
+/*
+updateAuditEvent an update which clears deleted_at is a restore
+*/
+func (m *EloquentModel) updateAuditEvent(parsed *Model) string {
+	if !parsed.SoftDelete {
+		return AuditUpdated
+	}
+	field := parsed.FieldsByDbName[parsed.DeletedAt]
+	old, _ := auditValue(field, m.Origin[field.Name])
+	current, _ := fieldValue(field, reflect.Indirect(m.ModelPointer).Field(field.Index))
+	if m.IsDirty(field.Name) && old != nil && current == nil {
+		return AuditRestored
+	}
+	return AuditUpdated
+}
+
+/*
+needsAuditTransaction auditable models without a Tx need a transaction for the change and its audit row
+*/
+func (m *EloquentModel) needsAuditTransaction() bool {
+	_, ok := m.ModelPointer.Interface().(Auditable)
+	return ok && m.Tx == nil
+}
+
+/*
+inAuditTransaction run fn with a new transaction set as the model's Tx,it's committed if fn succeeds and rolled back otherwise
+*/
+func (m *EloquentModel) inAuditTransaction(fn func() (Result, error)) (res Result, err error) {
+	ctx := m.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	c, err := DB.Connection(parsed.ConnectionName)
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	tx, err := c.BeginTransactionContext(ctx, nil)
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	m.Tx = tx
+	defer func() {
+		m.Tx = nil
+	}()
+	if res, err = fn(); err != nil {
+		tx.Rollback()
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+/*
+writeAudit insert an audit row for auditable models,values are only collected for them
+*/
+func (m *EloquentModel) writeAudit(event string, values func() (old, new map[string]interface{}, err error)) error {
+	auditable, ok := m.ModelPointer.Interface().(Auditable)
+	if !ok {
+		return nil
+	}
+	old, new, err := values()
+	if err != nil {
+		return err
+	}
+	for _, column := range auditable.EloquentAuditExclude() {
+		delete(old, column)
+		delete(new, column)
+	}
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return err
+	}
+	oldValues, err := json.Marshal(old)
+	if err != nil {
+		return err
+	}
+	newValues, err := json.Marshal(new)
+	if err != nil {
+		return err
+	}
+	//use the morph alias if the model is registered in the morph map
+	auditableType := parsed.Name
+	if alias, err := GetMorphMap(parsed.Name); err == nil {
+		auditableType = alias
+	}
+	record := map[string]interface{}{
+		"auditable_type": auditableType,
+		"auditable_id":   reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Interface(),
+		"event":          event,
+		"old_values":     string(oldValues),
+		"new_values":     string(newValues),
+		"user_id":        nil,
+		"created_at":     time.Now(),
+	}
+	if user, ok := AuditUserFromContext(m.Context); ok {
+		record["user_id"] = user
+	}
+	var builder *Builder
+	if m.Tx != nil {
+		builder = m.Tx.Table(AuditTable)
+	} else {
+		c, err := DB.Connection(parsed.ConnectionName)
+		if err != nil {
+			return err
+		}
+		builder = c.Table(AuditTable)
+	}
+	if m.Context != nil {
+		builder.WithContext(m.Context)
+	}
+	_, err = builder.Insert(record)
+	return err
+}
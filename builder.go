@@ -3,6 +3,7 @@ package goeloquent
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -61,9 +62,9 @@ var DefaultBuilderChainFunc = func(builder *Builder) *Builder {
 
 // Builder Base query builder
 type Builder struct {
-	Connection *Connection   //database connection
-	Tx         *Transaction  //database transaction , if it is not nil ,use this to execute sql
-	Grammar    *MysqlGrammar //convert builder to sql
+	Connection *Connection  //database connection
+	Tx         *Transaction //database transaction , if it is not nil ,use this to execute sql
+	Grammar    Grammar      //convert builder to sql
 	//Processor   processors.IProcessor
 	PreSql    strings.Builder          //sql string builder
 	Bindings  map[string][]interface{} //available options (select,from,join,where,groupBy,having,order,union,unionOrder)
@@ -83,24 +84,25 @@ type Builder struct {
 	LimitNum        int
 	OffsetNum       int
 	IndexHint       []string //TODO: hint/force/ignore
-	//Unions           []Where
-	//UnionLimit       int
-	//UnionOffset      int
-	//UnionOrders      int
-	Components  map[string]struct{} //SelectComponents
-	LockMode    interface{}
-	Pretending  bool
-	PreparedSql string //compiled sql string
+	Unions          []Union
+	UnionLimit      int
+	UnionOffset     int
+	UnionOrders     []Order
+	Components      map[string]struct{} //SelectComponents
+	LockMode        interface{}
+	Pretending      bool
+	PreparedSql     string //compiled sql string
 	//Model                *eloquent.Model
 	Dest                 interface{} // scan dest
 	OnlyColumns          map[string]interface{}
 	ExceptColumns        map[string]interface{}
-	UseWrite             bool //TODO
+	UseWrite             bool //force the write connection for selects
 	BeforeQueryCallBacks []func(builder *Builder)
 	AfterQueryCallBacks  []func(builder *Builder)
 	DataMapping          map[string]interface{} //column type when use map as scan dest
 	Context              context.Context
-	Debug                bool //debug mode
+	Debug                bool  //debug mode
+	Err                  error //the first error met while building the query,it's returned by the query methods instead of running the query
 }
 
 const (
@@ -146,6 +148,8 @@ const (
 	TYPE_ORDER                    = "order"
 	TYPE_UNION                    = "union"
 	TYPE_UNION_ORDER              = "unionOrder"
+	TYPE_UNION_LIMIT              = "unionLimit"
+	TYPE_UNION_OFFSET             = "unionOffset"
 	TYPE_COLUMN                   = "column"
 	TYPE_AGGREGRATE               = "aggregrate"
 	TYPE_OFFSET                   = "offset"
@@ -166,6 +170,10 @@ type Order struct {
 	Column    interface{} //string or expression
 	RawSql    interface{}
 }
+type Union struct {
+	Query *Builder
+	All   bool //union all
+}
 type Having struct {
 	HavingType     string
 	HavingColumn   string
@@ -196,16 +204,50 @@ func NewQueryBuilder(c ...*Connection) *Builder {
 		Components: make(map[string]struct{}),
 		Bindings:   make(map[string][]interface{}),
 		Context:    context.Background(),
-		Grammar:    &MysqlGrammar{},
 	}
-	b.Grammar.SetBuilder(&b)
 	if len(c) > 0 && c[0] != nil {
 		b.Connection = c[0]
+		b.SetGrammar(NewGrammar(c[0].Config.Driver))
 		b.Grammar.SetTablePrefix(c[0].Config.Prefix)
+	} else {
+		b.SetGrammar(&MysqlGrammar{})
 	}
 	return &b
 }
 
+/*
+SetGrammar Set the grammar used to compile the query.
+*/
+func (b *Builder) SetGrammar(grammar Grammar) *Builder {
+	b.Grammar = grammar
+	b.Grammar.SetBuilder(b)
+	return b
+}
+
+/*
+SetConnection Set the connection of the query,the grammar is switched to the connection's driver
+*/
+func (b *Builder) SetConnection(c *Connection) *Builder {
+	b.Connection = c
+	if c != nil && NewGrammar(c.Config.Driver).GetDriver() != b.Grammar.GetDriver() {
+		prefix := b.Grammar.GetTablePrefix()
+		b.SetGrammar(NewGrammar(c.Config.Driver))
+		b.Grammar.SetTablePrefix(prefix)
+	}
+	return b
+}
+
+/*
+newNestedQuery Create a new query instance compiled by the same dialect for nested conditions.
+*/
+func (b *Builder) newNestedQuery() *Builder {
+	cb := NewQueryBuilder()
+	if b.Grammar.GetDriver() != cb.Grammar.GetDriver() {
+		cb.SetGrammar(NewGrammar(b.Grammar.GetDriver()))
+	}
+	return cb
+}
+
 /*
 Clone Clone the query.
 */
@@ -228,6 +270,10 @@ func Clone(original *Builder) *Builder {
 		Orders:          make([]Order, len(original.Orders)),
 		LimitNum:        original.LimitNum,
 		OffsetNum:       original.OffsetNum,
+		Unions:          make([]Union, len(original.Unions)),
+		UnionLimit:      original.UnionLimit,
+		UnionOffset:     original.UnionOffset,
+		UnionOrders:     make([]Order, len(original.UnionOrders)),
 		Components:      make(map[string]struct{}, len(original.Components)),
 		LockMode:        original.LockMode,
 		Pretending:      original.Pretending,
@@ -235,7 +281,9 @@ func Clone(original *Builder) *Builder {
 		Dest:            nil,
 		OnlyColumns:     make(map[string]interface{}, len(original.OnlyColumns)),
 		ExceptColumns:   make(map[string]interface{}, len(original.ExceptColumns)),
+		UseWrite:        original.UseWrite,
 		Context:         context.WithValue(original.Context, "parent", original),
+		Err:             original.Err,
 		DataMapping:     make(map[string]interface{}),
 	}
 	for key, _ := range original.Bindings {
@@ -249,6 +297,8 @@ func Clone(original *Builder) *Builder {
 	copy(newBuilder.Groups, original.Groups)
 	copy(newBuilder.Havings, original.Havings)
 	copy(newBuilder.Orders, original.Orders)
+	copy(newBuilder.Unions, original.Unions)
+	copy(newBuilder.UnionOrders, original.UnionOrders)
 	for key, _ := range original.Components {
 		newBuilder.Components[key] = original.Components[key]
 	}
@@ -264,16 +314,35 @@ func Clone(original *Builder) *Builder {
 	for key, _ := range original.DataMapping {
 		newBuilder.DataMapping[key] = original.DataMapping[key]
 	}
-	newBuilder.Grammar = &MysqlGrammar{
-		Prefix:  original.Grammar.GetTablePrefix(),
-		Builder: &newBuilder,
-	}
+	newBuilder.SetGrammar(NewGrammar(original.Grammar.GetDriver()))
+	newBuilder.Grammar.SetTablePrefix(original.Grammar.GetTablePrefix())
 	return &newBuilder
 }
+
+/*
+WithContext Set the context used to run the query,a nil ctx is replaced by context.Background()
+*/
 func (b *Builder) WithContext(ctx context.Context) *Builder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	b.Context = ctx
 	return b
 }
+
+/*
+AddError record an error met while building the query,only the first one is kept,
+the query methods return it instead of running the query
+
+ 1. DB.Conn("sqlite").Table("users").WhereJsonContains("options->languages", "en").Get(&users) returns an error instead of running the query
+ 2. DB.Model(&Post{}).Get(&posts) returns ErrTenantMissing if the tenant scope can't be applied
+*/
+func (b *Builder) AddError(err error) *Builder {
+	if b.Err == nil && err != nil {
+		b.Err = err
+	}
+	return b
+}
 func (b *Builder) Clone() *Builder {
 	return Clone(b)
 }
@@ -699,7 +768,7 @@ func (b *Builder) Where(params ...interface{}) *Builder {
 		} else {
 			boolean = BOOLEAN_AND
 		}
-		cb := NewQueryBuilder()
+		cb := b.newNestedQuery()
 		if b.FromTable != nil {
 			cb.From(b.FromTable)
 		}
@@ -712,7 +781,7 @@ func (b *Builder) Where(params ...interface{}) *Builder {
 		} else {
 			boolean = BOOLEAN_AND
 		}
-		cb := condition(NewQueryBuilder())
+		cb := condition(b.newNestedQuery())
 		return b.AddNestedWhereQuery(cb, boolean)
 	case Where:
 		b.Wheres = append(b.Wheres, condition)
@@ -741,7 +810,7 @@ func (b *Builder) Where(params ...interface{}) *Builder {
 		if paramsLength > 1 {
 			boolean = params[1].(string)
 		}
-		cb := NewQueryBuilder()
+		cb := b.newNestedQuery()
 		for k, v := range condition {
 			cb.Where(k, v)
 		}
@@ -1614,7 +1683,10 @@ ForSubQuery Create a new query instance for a sub-query.
 */
 func (b *Builder) ForSubQuery() *Builder {
 	cb := NewQueryBuilder(b.Connection)
-	cb.Grammar.Prefix = b.Grammar.Prefix
+	if cb.Grammar.GetDriver() != b.Grammar.GetDriver() {
+		cb.SetGrammar(NewGrammar(b.Grammar.GetDriver()))
+	}
+	cb.Grammar.SetTablePrefix(b.Grammar.GetTablePrefix())
 	return cb
 }
 
@@ -1726,9 +1798,14 @@ func (b *Builder) AddWhereExistsQuery(builder *Builder, boolean string, not bool
 }
 
 /*
-	TODO
+WhereJsonContains Add a "where json contains" clause to the query.
+value will be json encoded unless it is an Expression
 
-whereJsonContains Add a "where json contains" clause to the query.
+ 1. WhereJsonContains("options", []string{"en"})
+
+    mysql: select * from `users` where json_contains(`options`, ?)
+
+    postgres: select * from "users" where ("options")::jsonb @> $1
 */
 func (b *Builder) WhereJsonContains(column string, value interface{}, params ...interface{}) *Builder {
 	var boolean = BOOLEAN_AND
@@ -1751,6 +1828,13 @@ func (b *Builder) WhereJsonContains(column string, value interface{}, params ...
 		Not:     not,
 	})
 	b.Components[TYPE_WHERE] = struct{}{}
+	if _, ok := value.(Expression); !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return b.AddError(err)
+		}
+		b.AddBinding([]interface{}{string(encoded)}, TYPE_WHERE)
+	}
 	return b
 
 }
@@ -2108,18 +2192,18 @@ OrderBy Add an "order by" clause to the query.
 func (b *Builder) OrderBy(params ...interface{}) *Builder {
 	var order = ORDER_ASC
 	if r, ok := params[0].(Expression); ok {
-		b.Orders = append(b.Orders, Order{
+		b.addOrder(Order{
 			RawSql:    r,
 			OrderType: CONDITION_TYPE_RAW,
-		})
-		b.Components[TYPE_ORDER] = struct{}{}
+		}, nil)
 
 		return b
 	}
 	column := params[0]
+	var bindings []interface{}
 	if IsQueryable(params[0]) {
-		str, bindings := b.CreateSub(params[0])
-		b.AddBinding(bindings, TYPE_ORDER)
+		var str string
+		str, bindings = b.CreateSub(params[0])
 		column = Raw("(" + str + ")")
 	}
 	if len(params) > 1 {
@@ -2128,15 +2212,28 @@ func (b *Builder) OrderBy(params ...interface{}) *Builder {
 	if order != ORDER_ASC && order != ORDER_DESC {
 		panic(errors.New("wrong order direction: " + order))
 	}
-	b.Orders = append(b.Orders, Order{
+	b.addOrder(Order{
 		Direction: order,
 		Column:    column,
-	})
-	b.Components[TYPE_ORDER] = struct{}{}
+	}, bindings)
 
 	return b
 }
 
+/*
+addOrder add an order to the query,orders added after a union apply to the whole union
+*/
+func (b *Builder) addOrder(order Order, bindings []interface{}) {
+	if len(b.Unions) > 0 {
+		b.UnionOrders = append(b.UnionOrders, order)
+		b.AddBinding(bindings, TYPE_UNION_ORDER)
+		return
+	}
+	b.Orders = append(b.Orders, order)
+	b.Components[TYPE_ORDER] = struct{}{}
+	b.AddBinding(bindings, TYPE_ORDER)
+}
+
 /*
 OrderByDesc Add a descending "order by" clause to the query.
 
@@ -2152,12 +2249,10 @@ func (b *Builder) OrderByDesc(column string) *Builder {
 OrderByRaw Add a raw "order by" clause to the query.
 */
 func (b *Builder) OrderByRaw(sql string, bindings []interface{}) *Builder {
-	b.Orders = append(b.Orders, Order{
+	b.addOrder(Order{
 		OrderType: CONDITION_TYPE_RAW,
 		RawSql:    Raw(sql),
-	})
-	b.Components[TYPE_ORDER] = struct{}{}
-	b.AddBinding(bindings, TYPE_ORDER)
+	}, bindings)
 	return b
 }
 
@@ -2168,6 +2263,8 @@ func (b *Builder) ReOrder(params ...string) *Builder {
 	b.Orders = nil
 	b.Bindings["order"] = nil
 	delete(b.Components, TYPE_ORDER)
+	b.UnionOrders = nil
+	b.Bindings[TYPE_UNION_ORDER] = nil
 	length := len(params)
 	if length == 1 {
 		b.OrderBy(InterfaceToSlice(params[0]))
@@ -2181,6 +2278,10 @@ func (b *Builder) ReOrder(params ...string) *Builder {
 Limit Set the "limit" value of the query.
 */
 func (b *Builder) Limit(n int) *Builder {
+	if len(b.Unions) > 0 {
+		b.UnionLimit = int(math.Max(0, float64(n)))
+		return b
+	}
 	b.Components[TYPE_LIMIT] = struct{}{}
 	b.LimitNum = int(math.Max(0, float64(n)))
 	return b
@@ -2190,6 +2291,10 @@ func (b *Builder) Limit(n int) *Builder {
 Offset Set the "offset" value of the query.
 */
 func (b *Builder) Offset(n int) *Builder {
+	if len(b.Unions) > 0 {
+		b.UnionOffset = int(math.Max(0, float64(n)))
+		return b
+	}
 	b.OffsetNum = int(math.Max(0, float64(n)))
 	b.Components[TYPE_OFFSET] = struct{}{}
 	return b
@@ -2197,11 +2302,51 @@ func (b *Builder) Offset(n int) *Builder {
 
 /*
 Union Add a union statement to the query.
+query can be a *Builder,*EloquentBuilder,func(builder *Builder) or func(builder *Builder) *Builder,
+OrderBy/Limit/Offset called after Union apply to the whole union
+
+ 1. Table("users").Select("name").Union(Table("admins").Select("name"))
+
+    (select `name` from `users`) union (select `name` from `admins`)
+
+ 2. Table("users").Select("name").Union(func(builder *Builder) { builder.From("admins").Select("name") }).OrderBy("name").Limit(10)
+
+    (select `name` from `users`) union (select `name` from `admins`) order by `name` asc limit 10
 */
-//func (b *Builder) Union(n int) *Builder {
-//	b.OffsetNum = n
-//	return b
-//}
+func (b *Builder) Union(query interface{}, all ...bool) *Builder {
+	var union *Builder
+	switch q := query.(type) {
+	case *Builder:
+		union = q
+	case *EloquentBuilder:
+		union = q.Builder
+	case func(builder *Builder):
+		union = b.ForSubQuery()
+		q(union)
+	case func(builder *Builder) *Builder:
+		union = q(b.ForSubQuery())
+	default:
+		return b.AddError(errors.New("union query must be [*Builder],[*EloquentBuilder],[func(builder *Builder)] or [func(builder *Builder) *Builder]"))
+	}
+	b.Unions = append(b.Unions, Union{
+		Query: union,
+		All:   len(all) > 0 && all[0],
+	})
+	b.Components[TYPE_UNION] = struct{}{}
+	b.AddBinding(union.GetBindings(), TYPE_UNION)
+	return b
+}
+
+/*
+UnionAll Add a union all statement to the query.
+
+ 1. Table("users").Select("name").UnionAll(Table("admins").Select("name"))
+
+    (select `name` from `users`) union all (select `name` from `admins`)
+*/
+func (b *Builder) UnionAll(query interface{}) *Builder {
+	return b.Union(query, true)
+}
 
 /*
 Lock Lock the selected rows in the table for updating.
@@ -2264,6 +2409,22 @@ func (b *Builder) First(dest interface{}, columns ...interface{}) (result Result
 	return b.Get(dest, columns...)
 }
 
+/*
+FirstOrFail Execute the query and get the first result,return a QueryException wrapping ErrRecordNotFound if no rows match.
+*/
+func (b *Builder) FirstOrFail(dest interface{}, columns ...interface{}) (result Result, err error) {
+	result, err = b.First(dest, columns...)
+	return result, recordNotFound(result, err)
+}
+
+/*
+FindOrFail Execute a query for a single record by ID,return a QueryException wrapping ErrRecordNotFound if it doesn't exist.
+*/
+func (b *Builder) FindOrFail(dest interface{}, params ...interface{}) (result Result, err error) {
+	result, err = b.Find(dest, params...)
+	return result, recordNotFound(result, err)
+}
+
 /*
 RunSelect run the query as a "select" statement against the connection.
 */
@@ -2279,22 +2440,49 @@ func (b *Builder) RunSelect() (result Result, err error) {
 				Raw:      nil,
 			}, nil
 		}
-		if b.Tx != nil {
-			result, err = b.Tx.Select(b.PreparedSql, b.GetBindings(), b.Dest, b.DataMapping)
-		} else {
-			result, err = b.GetConnection().Select(b.PreparedSql, b.GetBindings(), b.Dest, b.DataMapping)
-		}
-		return
+		return b.runSelectStatement(b.Dest, b.DataMapping, false)
 	})
 
 	return
 }
 
+/*
+runSelectStatement run the prepared select on the transaction if there is one,
+on the write connection for locking reads,UseWritePdo or sticky reads,otherwise on a read connection
+*/
+func (b *Builder) runSelectStatement(dest interface{}, mapping map[string]interface{}, useWrite bool) (result Result, err error) {
+	if tx := b.transaction(); tx != nil {
+		return tx.SelectContext(b.Context, b.PreparedSql, b.GetBindings(), dest, mapping)
+	}
+	c := b.GetConnection()
+	if _, locking := b.Components[TYPE_LOCK]; locking || useWrite || b.UseWrite || (c.Config.Sticky && HasModifiedRecords(b.Context)) {
+		return c.SelectFromWriteConnectionContext(b.Context, b.PreparedSql, b.GetBindings(), dest, mapping)
+	}
+	return c.SelectContext(b.Context, b.PreparedSql, b.GetBindings(), dest, mapping)
+}
+
+/*
+UseWritePdo Use the write connection for the query.
+*/
+func (b *Builder) UseWritePdo() *Builder {
+	b.UseWrite = true
+	return b
+}
+
+/*
+GetConnection get the connection of the builder,the default connection is used if it's not set,
+nil is returned and the error is recorded on the builder if the default connection can't be made
+*/
 func (b *Builder) GetConnection() *Connection {
 	if b.Connection != nil {
 		return b.Connection
 	}
-	return DB.Connection(DefaultConnectionName)
+	c, err := DB.Connection(DefaultConnectionName)
+	if err != nil {
+		b.AddError(err)
+		return nil
+	}
+	return c
 }
 func (b *Builder) Run(query string, bindings []interface{}, callback func() (result Result, err error)) (result Result, err error) {
 	defer func() {
@@ -2310,6 +2498,13 @@ func (b *Builder) Run(query string, bindings []interface{}, callback func() (res
 			}
 		}
 	}()
+	if b.Tx == nil {
+		//make the connection before running so a connection error is returned like other building errors
+		b.GetConnection()
+	}
+	if b.Err != nil {
+		return Result{Sql: query, Bindings: bindings, Error: b.Err}, b.Err
+	}
 	start := time.Now()
 	result, err = callback()
 	result.Bindings = bindings
@@ -2327,8 +2522,7 @@ func (b *Builder) Exists() (exists bool, err error) {
 	b.ApplyBeforeQueryCallbacks()
 	var count int
 	_, err = b.Run(b.Grammar.CompileExists(), b.GetBindings(), func() (result Result, err error) {
-		result, err = b.GetConnection().Select(b.PreparedSql, b.GetBindings(), &count, nil)
-		return
+		return b.runSelectStatement(&count, nil, false)
 	})
 	if err != nil {
 		return false, err
@@ -2453,7 +2647,10 @@ func (b *Builder) FileterColumn(column string) bool {
 	return true
 }
 
-func PrepareInsertValues(values interface{}) []map[string]interface{} {
+/*
+PrepareInsertValues convert the values of an insert to maps,structs are converted by ExtractStruct
+*/
+func PrepareInsertValues(values interface{}) ([]map[string]interface{}, error) {
 	rv := reflect.ValueOf(values)
 	var items []map[string]interface{}
 	if rv.Kind() == reflect.Ptr {
@@ -2467,7 +2664,11 @@ func PrepareInsertValues(values interface{}) []map[string]interface{} {
 			switch eleType.Elem().Kind() {
 			case reflect.Struct:
 				for i := 0; i < rv.Len(); i++ {
-					items = append(items, ExtractStruct(rv.Index(i).Elem().Interface()))
+					item, err := ExtractStruct(rv.Index(i).Elem().Interface())
+					if err != nil {
+						return nil, err
+					}
+					items = append(items, item)
 				}
 			case reflect.Map:
 				for i := 0; i < rv.Len(); i++ {
@@ -2482,13 +2683,21 @@ func PrepareInsertValues(values interface{}) []map[string]interface{} {
 			}
 		} else if eleType.Kind() == reflect.Struct {
 			for i := 0; i < rv.Len(); i++ {
-				items = append(items, ExtractStruct(rv.Index(i).Interface()))
+				item, err := ExtractStruct(rv.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
 			}
 		}
 	} else if rv.Kind() == reflect.Struct {
-		items = append(items, ExtractStruct(rv.Interface()))
+		item, err := ExtractStruct(rv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
 	}
-	return items
+	return items, nil
 }
 
 /*
@@ -2496,7 +2705,10 @@ Insert new records into the database.
 values can be []map[string]interface{},map[string]interface{},struct,pointer of struct,pointer of slice of struct
 */
 func (b *Builder) Insert(values interface{}) (result Result, err error) {
-	items := PrepareInsertValues(values)
+	items, err := PrepareInsertValues(values)
+	if err != nil {
+		return Result{Error: err}, err
+	}
 	b.Prepare(values)
 	b.ApplyBeforeQueryCallbacks()
 	result, err = b.Run(b.Grammar.CompileInsert(items), b.GetBindings(), func() (result Result, err error) {
@@ -2510,10 +2722,13 @@ func (b *Builder) Insert(values interface{}) (result Result, err error) {
 				Raw:      nil,
 			}, nil
 		}
-		if b.Tx != nil {
-			result, err = b.Tx.Insert(b.PreparedSql, b.GetBindings())
+		if tx := b.transaction(); tx != nil {
+			result, err = tx.AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
 		} else {
-			result, err = b.GetConnection().Insert(b.PreparedSql, b.GetBindings())
+			result, err = b.GetConnection().AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		}
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
 		}
 		return
 	})
@@ -2523,22 +2738,53 @@ func (b *Builder) Insert(values interface{}) (result Result, err error) {
 
 /*
 InsertGetId Insert a new record and get the value of the primary key.
+sequence is the primary key column used by "returning" on postgres,default to id
 */
-func (b *Builder) InsertGetId(values interface{}) (int64, error) {
+func (b *Builder) InsertGetId(values interface{}, sequence ...string) (int64, error) {
 	b.ApplyBeforeQueryCallbacks()
-	insert, err := b.Insert(values)
+	if b.Grammar.GetDriver() != DriverPostgres {
+		insert, err := b.Insert(values)
+		if err != nil {
+			return 0, err
+		}
+		id, _ := insert.LastInsertId()
+		return id, nil
+	}
+	var id int64
+	var column string
+	if len(sequence) > 0 {
+		column = sequence[0]
+	}
+	items, err := PrepareInsertValues(values)
 	if err != nil {
 		return 0, err
 	}
-	id, _ := insert.LastInsertId()
-	return id, nil
+	b.Prepare(values)
+	_, err = b.Run(b.Grammar.CompileInsertGetId(items, column), b.GetBindings(), func() (result Result, err error) {
+		if b.Pretending {
+			return Result{
+				Sql:      b.PreparedSql,
+				Bindings: b.GetBindings(),
+			}, nil
+		}
+		result, err = b.runSelectStatement(&id, nil, true)
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
+		}
+		return
+	})
+	b.ApplyAfterQueryCallbacks()
+	return id, err
 }
 
 /*
 InsertOrIgnore Insert a new record and get the value of the primary key.
 */
 func (b *Builder) InsertOrIgnore(values interface{}) (result Result, err error) {
-	items := PrepareInsertValues(values)
+	items, err := PrepareInsertValues(values)
+	if err != nil {
+		return Result{Error: err}, err
+	}
 
 	b.ApplyBeforeQueryCallbacks()
 	result, err = b.Run(b.Grammar.CompileInsertOrIgnore(items), b.GetBindings(), func() (result Result, err error) {
@@ -2552,10 +2798,13 @@ func (b *Builder) InsertOrIgnore(values interface{}) (result Result, err error)
 				Raw:      nil,
 			}, nil
 		}
-		if b.Tx != nil {
-			result, err = b.Tx.Insert(b.PreparedSql, b.GetBindings())
+		if tx := b.transaction(); tx != nil {
+			result, err = tx.AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
 		} else {
-			result, err = b.GetConnection().Insert(b.PreparedSql, b.GetBindings())
+			result, err = b.GetConnection().AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		}
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
 		}
 		return
 	})
@@ -2583,10 +2832,13 @@ func (b *Builder) Update(v map[string]interface{}) (result Result, err error) {
 				Raw:      nil,
 			}, nil
 		}
-		if b.Tx != nil {
-			result, err = b.Tx.Update(b.PreparedSql, b.GetBindings())
+		if tx := b.transaction(); tx != nil {
+			result, err = tx.AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
 		} else {
-			result, err = b.GetConnection().Update(b.PreparedSql, b.GetBindings())
+			result, err = b.GetConnection().AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		}
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
 		}
 		return
 	})
@@ -2634,27 +2886,60 @@ func (b *Builder) Model(model ...interface{}) *EloquentBuilder {
 }
 
 /*
-Upsert Insert new records or update the existing one
+Upsert Insert new records or update the existing ones.
+values accepts the same types as Insert,uniqueColumns identify the records("on conflict" target on postgres/sqlite),
+updateColumns can be nil(update every inserted column except the unique ones),[]string(update with the inserted values)
+or map[string]interface{}(update with the given values),the records are inserted with InsertOrIgnore if there is nothing to update
+
+ 1. Upsert([]map[string]interface{}{{"email": "foo@bar.com", "votes": 1}}, []string{"email"}, []string{"votes"})
+
+    insert into `users` (`email`, `votes`) values (?, ?) on duplicate key update `votes` = values(`votes`)
+
+ 2. Upsert(map[string]interface{}{"email": "foo@bar.com"}, []string{"email"}, map[string]interface{}{"votes": Raw("votes + 1")})
+
+    insert into `users` (`email`) values (?) on duplicate key update `votes` = votes + 1
 */
-//func (b *Builder) Upsert(values interface{}, uniqueColumns []string, updateColumns interface{}) (result Result, err error) {
-//	items := PrepareInsertValues(values)
-//	if b.FromTable == nil {
-//		b.Model = eloquent.GetParsedModel(values)
-//		b.From(b.Model.Table)
-//		b.Connection = goeloquent.DB.Connection(b.Model.ConnectionName)
-//		b.Grammar.SetTablePrefix(goeloquent.DB.Configs[b.Model.ConnectionName].Prefix)
-//	}
-//	b.ApplyAfterQueryCallbacks()
-//	b.PreparedSql = b.Grammar.CompileUpsert(items, uniqueColumns, updateColumns)
-//	if b.Pretending {
-//		return
-//	}
-//	if b.Tx != nil {
-//		return b.Tx.AffectingStatement(b.PreparedSql, b.GetBindings())
-//	} else {
-//		return b.Connection.AffectingStatement(b.PreparedSql, b.GetBindings())
-//	}
-//}
+func (b *Builder) Upsert(values interface{}, uniqueColumns []string, updateColumns interface{}) (result Result, err error) {
+	items, err := PrepareInsertValues(values)
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	if len(items) == 0 {
+		return
+	}
+	if updateColumns == nil {
+		columns := upsertUpdateColumns(items, uniqueColumns)
+		if len(columns) == 0 {
+			return b.InsertOrIgnore(items)
+		}
+		updateColumns = columns
+	}
+	b.Prepare(values)
+	b.ApplyBeforeQueryCallbacks()
+	result, err = b.Run(b.Grammar.CompileUpsert(items, uniqueColumns, updateColumns), b.GetBindings(), func() (result Result, err error) {
+		if b.Pretending {
+			return Result{
+				Sql:      b.PreparedSql,
+				Bindings: b.GetBindings(),
+				Count:    0,
+				Error:    nil,
+				Time:     0,
+				Raw:      nil,
+			}, nil
+		}
+		if tx := b.transaction(); tx != nil {
+			result, err = tx.AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		} else {
+			result, err = b.GetConnection().AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		}
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
+		}
+		return
+	})
+	b.ApplyAfterQueryCallbacks()
+	return
+}
 
 /*
 Decrement Decrement a column's value by a given amount.
@@ -2718,10 +3003,13 @@ func (b *Builder) Delete(id ...interface{}) (result Result, err error) {
 				Raw:      nil,
 			}, nil
 		}
-		if b.Tx != nil {
-			result, err = b.Tx.Delete(b.PreparedSql, b.GetBindings())
+		if tx := b.transaction(); tx != nil {
+			result, err = tx.AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
 		} else {
-			result, err = b.GetConnection().Delete(b.PreparedSql, b.GetBindings())
+			result, err = b.GetConnection().AffectingStatementContext(b.Context, b.PreparedSql, b.GetBindings())
+		}
+		if err == nil {
+			RecordsHaveBeenModified(b.Context)
 		}
 		return
 	})
@@ -2755,7 +3043,7 @@ Get Execute the query as a "select" statement.
 
     var usersMap []map[string]interface{}
 
-    DB.Connection("default").Table("users").Get(&usersMap, "id", "user_name")
+    DB.MustConnection("default").Table("users").Get(&usersMap, "id", "user_name")
 
     select id,user_name from users
 */
@@ -2832,6 +3120,13 @@ func (b *Builder) Reset(targets ...string) *Builder {
 			delete(b.Bindings, TYPE_SELECT)
 			delete(b.Components, TYPE_WHERE)
 			b.Columns = nil
+		case TYPE_UNION_ORDER:
+			delete(b.Bindings, TYPE_UNION_ORDER)
+			b.UnionOrders = nil
+		case TYPE_UNION_LIMIT:
+			b.UnionLimit = 0
+		case TYPE_UNION_OFFSET:
+			b.UnionOffset = 0
 		default:
 			panic("unknown component name: " + componentName)
 		}
@@ -2917,7 +3212,7 @@ columns is the database table fileds to be selected,default is *
 */
 func (b *Builder) Paginate(items interface{}, perPage, currentPage int64, columns ...interface{}) (*Paginator, error) {
 	if len(b.Groups) > 0 || len(b.Havings) > 0 {
-		panic("having/group pagination not supported")
+		return nil, errors.New("having/group pagination not supported")
 	}
 	p := &Paginator{
 		Items:       items,
@@ -2925,13 +3220,7 @@ func (b *Builder) Paginate(items interface{}, perPage, currentPage int64, column
 		PerPage:     perPage,
 		CurrentPage: currentPage,
 	}
-	cb := CloneWithout(b, TYPE_COLUMN, TYPE_ORDER, TYPE_OFFSET, TYPE_LIMIT)
-	for k, v := range b.GetRawBindings() {
-		cb.Bindings[k] = v
-	}
-	delete(cb.Bindings, TYPE_SELECT)
-	delete(cb.Bindings, TYPE_ORDER)
-	_, err := cb.Count(&p.Total)
+	_, err := b.cloneForPaginationCount().Count(&p.Total)
 	if err != nil {
 		return nil, err
 	}
@@ -2943,11 +3232,9 @@ func (b *Builder) Paginate(items interface{}, perPage, currentPage int64, column
 }
 func (b *Builder) PaginateUsingPaginator(p *Paginator, columns ...interface{}) (*Paginator, error) {
 	if len(b.Groups) > 0 || len(b.Havings) > 0 {
-		panic("having/group pagination not supported")
+		return nil, errors.New("having/group pagination not supported")
 	}
-	cb := CloneWithout(b, TYPE_COLUMN, TYPE_ORDER, TYPE_OFFSET, TYPE_LIMIT)
-	cb.Bindings = b.GetRawBindings()
-	_, err := cb.Count(&p.Total)
+	_, err := b.cloneForPaginationCount().Count(&p.Total)
 	if err != nil {
 		return nil, err
 	}
@@ -2981,14 +3268,24 @@ GetCountForPagination Get the count of the total records for the paginator.
 */
 func (b *Builder) GetCountForPagination() (int64, error) {
 	var c int64
-	_, err := b.CloneWithout(TYPE_COLUMN, TYPE_ORDER, TYPE_OFFSET, TYPE_LIMIT).
-		CloneWithoutBindings(TYPE_SELECT, TYPE_ORDER).Count(&c)
+	_, err := b.cloneForPaginationCount().Count(&c)
 	return c, err
 }
 
+/*
+cloneForPaginationCount Clone the query for counting the total records,
+a union query keeps its columns and the whole union is counted
+*/
+func (b *Builder) cloneForPaginationCount() *Builder {
+	if len(b.Unions) > 0 {
+		return b.CloneWithout(TYPE_UNION_ORDER, TYPE_UNION_LIMIT, TYPE_UNION_OFFSET)
+	}
+	return b.CloneWithout(TYPE_COLUMN, TYPE_ORDER, TYPE_OFFSET, TYPE_LIMIT).CloneWithoutBindings(TYPE_SELECT, TYPE_ORDER)
+}
+
 func (b *Builder) Chunk(dest interface{}, chunkSize int64, callback func(dest interface{}) error) (err error) {
 	if len(b.Orders) == 0 {
-		panic(errors.New("must specify an orderby clause when using Chunk method"))
+		return errors.New("must specify an orderby clause when using Chunk method")
 	}
 	var page int64 = 1
 	var count int64 = 0
@@ -3003,6 +3300,10 @@ func (b *Builder) Chunk(dest interface{}, chunkSize int64, callback func(dest in
 		if err != nil {
 			return
 		}
+		//stop between chunks once the context is cancelled
+		if err = b.Context.Err(); err != nil {
+			return
+		}
 		if count != chunkSize {
 			break
 		} else {
@@ -3030,7 +3331,7 @@ func (b *Builder) ChunkById(dest interface{}, chunkSize int64, callback func(des
 	if isMap {
 		if extra == nil || len(extra) == 0 {
 			if len(b.Orders) == 0 {
-				panic(errors.New("must specify an orderby clause when using ChunkById method"))
+				return errors.New("must specify an orderby clause when using ChunkById method")
 			} else {
 				column = b.Orders[0].Column.(string)
 			}
@@ -3038,7 +3339,9 @@ func (b *Builder) ChunkById(dest interface{}, chunkSize int64, callback func(des
 			column = extra[0]
 		}
 	} else {
-		item = GetParsedModel(dest)
+		if item, err = ParseModel(dest); err != nil {
+			return
+		}
 		column = item.PrimaryKey.ColumnName
 	}
 
@@ -3056,6 +3359,10 @@ func (b *Builder) ChunkById(dest interface{}, chunkSize int64, callback func(des
 		if err != nil {
 			return
 		}
+		//stop between chunks once the context is cancelled
+		if err = b.Context.Err(); err != nil {
+			return
+		}
 		if count != chunkSize {
 			break
 		} else {
@@ -3140,16 +3447,31 @@ func (b *Builder) Mapping(mapping map[string]interface{}) *Builder {
 	b.DataMapping = m
 	return b
 }
-func ExtractStruct(target interface{}) map[string]interface{} {
+
+/*
+ExtractStruct convert a struct to a map of column => value,zero fields of models are skipped
+*/
+func ExtractStruct(target interface{}) (map[string]interface{}, error) {
 	tv := reflect.Indirect(reflect.ValueOf(target))
 	tt := tv.Type()
 	result := make(map[string]interface{}, tv.NumField())
 	if tt.Kind() == reflect.Struct {
-		m := GetParsedModel(tt)
+		m, err := ParseModel(tt)
+		if err != nil {
+			return nil, err
+		}
 		for column, f := range m.FieldsByDbName {
 			keyIndex := f.Index
 			if !tv.Field(keyIndex).IsZero() {
-				result[column] = tv.Field(keyIndex).Interface()
+				if f.Cast != nil {
+					v, err := fieldValue(f, tv.Field(keyIndex))
+					if err != nil {
+						return nil, err
+					}
+					result[column] = v
+				} else {
+					result[column] = tv.Field(keyIndex).Interface()
+				}
 			}
 		}
 	} else {
@@ -3158,7 +3480,7 @@ func ExtractStruct(target interface{}) map[string]interface{} {
 			result[key] = tv.Field(i).Interface()
 		}
 	}
-	return result
+	return result, nil
 }
 func (b *Builder) QualifyColumn(column interface{}) string {
 	if e, ok := column.(Expression); ok {
@@ -3172,7 +3494,11 @@ func (b *Builder) QualifyColumn(column interface{}) string {
 }
 func (b *Builder) Prepare(dest interface{}) {
 	if b.FromTable == nil {
-		m := GetParsedModel(dest)
+		m, err := ParseModel(dest)
+		if err != nil {
+			b.AddError(err)
+			return
+		}
 		b.From(m.Table)
 	}
 }
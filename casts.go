@@ -0,0 +1,368 @@
+package goeloquent
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CastJson      = "json"
+	CastSet       = "set"
+	CastUnix      = "unix"
+	CastDecimal   = "decimal"
+	CastEncrypted = "encrypted"
+)
+
+/*
+Caster converts a model field between its database value and its struct value,
+register it with RegisterCaster and use it with a tag like `goelo:"column:settings;cast:json"`,
+args after the cast name are available in Field.CastArgs,`cast:decimal:2` => []string{"2"}
+*/
+type Caster interface {
+	Get(field *Field, dest reflect.Value, value interface{}) error // set the scanned database value to the model field,dest is the zero value when value is NULL
+	Set(field *Field, src reflect.Value) (interface{}, error)      // convert the model field to a database value
+}
+
+var RegisteredCasters sync.Map //name:Caster
+
+func init() {
+	RegisterCaster(CastJson, jsonCaster{})
+	RegisterCaster(CastSet, setCaster{})
+	RegisterCaster(CastUnix, unixCaster{})
+	RegisterCaster(CastDecimal, decimalCaster{})
+	RegisterCaster(CastEncrypted, encryptedCaster{})
+}
+
+/*
+RegisterCaster register a custom cast,it should be called before the models using it are parsed
+*/
+func RegisterCaster(name string, caster Caster) {
+	RegisteredCasters.Store(name, caster)
+}
+
+var encryptionAEAD cipher.AEAD
+
+/*
+SetEncryptionKey set the AES key used by the encrypted cast,the key should be 16, 24, or 32 bytes
+*/
+func SetEncryptionKey(key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	encryptionAEAD = aead
+	return nil
+}
+
+/*
+castScanner scan a column into a casted model field
+*/
+type castScanner struct {
+	field *Field
+	dest  reflect.Value
+}
+
+func (s *castScanner) Scan(value interface{}) error {
+	//models may be reused between rows,start from the zero value
+	s.dest.Set(reflect.Zero(s.dest.Type()))
+	if value == nil {
+		return nil
+	}
+	if err := s.field.Cast.Get(s.field, s.dest, value); err != nil {
+		return fmt.Errorf("cast column %s: %w", s.field.ColumnName, err)
+	}
+	return nil
+}
+
+/*
+scanArg get the scan destination of a model field
+*/
+func scanArg(field *Field, value reflect.Value) interface{} {
+	if field.Cast != nil {
+		return &castScanner{field: field, dest: value}
+	}
+	return value.Addr().Interface()
+}
+
+/*
+fieldValue get the database value of a model field,an error is returned if the cast fails
+*/
+func fieldValue(field *Field, value reflect.Value) (interface{}, error) {
+	if field.Cast != nil {
+		v, err := field.Cast.Set(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("cast column %s: %w", field.ColumnName, err)
+		}
+		return v, nil
+	}
+	v := value.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		v, _ = valuer.Value()
+	}
+	return v, nil
+}
+
+/*
+castOrigin copy a casted field by converting it to the database value and back,so changes to maps/slices/pointers are detected
+*/
+func castOrigin(field *Field, value reflect.Value) interface{} {
+	if field.Cast == nil {
+		return value.Interface()
+	}
+	v, err := field.Cast.Set(field, value)
+	if err != nil {
+		return value.Interface()
+	}
+	cp := reflect.New(value.Type()).Elem()
+	if err = (&castScanner{field: field, dest: cp}).Scan(v); err != nil {
+		return value.Interface()
+	}
+	return cp.Interface()
+}
+
+/*
+castEqual compare a casted field with its original value,values are equal if they are deep equal or have the same database value
+*/
+func castEqual(field *Field, current reflect.Value, origin interface{}) bool {
+	if reflect.DeepEqual(current.Interface(), origin) {
+		return true
+	}
+	if origin == nil {
+		return false
+	}
+	c, err := field.Cast.Set(field, current)
+	if err != nil {
+		return false
+	}
+	o, err := field.Cast.Set(field, reflect.ValueOf(origin))
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(c, o)
+}
+
+func castBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("unsupported database value %T", value)
+}
+
+/*
+jsonCaster store structs/maps/slices as json
+*/
+type jsonCaster struct{}
+
+func (jsonCaster) Get(field *Field, dest reflect.Value, value interface{}) error {
+	b, err := castBytes(value)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, dest.Addr().Interface())
+}
+func (jsonCaster) Set(field *Field, src reflect.Value) (interface{}, error) {
+	switch src.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+		if src.IsNil() {
+			return nil, nil
+		}
+	}
+	b, err := json.Marshal(src.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+/*
+setCaster store a []string as a comma separated string
+*/
+type setCaster struct{}
+
+func (setCaster) Get(field *Field, dest reflect.Value, value interface{}) error {
+	b, err := castBytes(value)
+	if err != nil {
+		return err
+	}
+	if dest.Type() != reflect.TypeOf([]string{}) {
+		return fmt.Errorf("set cast needs []string,got %s", dest.Type())
+	}
+	if len(b) > 0 {
+		dest.Set(reflect.ValueOf(strings.Split(string(b), ",")))
+	}
+	return nil
+}
+func (setCaster) Set(field *Field, src reflect.Value) (interface{}, error) {
+	items, ok := src.Interface().([]string)
+	if !ok {
+		return nil, fmt.Errorf("set cast needs []string,got %s", src.Type())
+	}
+	return strings.Join(items, ","), nil
+}
+
+/*
+unixCaster store a time.Time as unix seconds,the zero time is stored as NULL
+*/
+type unixCaster struct{}
+
+func (unixCaster) Get(field *Field, dest reflect.Value, value interface{}) error {
+	var seconds int64
+	switch v := value.(type) {
+	case int64:
+		seconds = v
+	case float64:
+		seconds = int64(v)
+	case []byte, string:
+		b, _ := castBytes(v)
+		var err error
+		if seconds, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported database value %T", value)
+	}
+	if dest.Type() != reflect.TypeOf(time.Time{}) {
+		return fmt.Errorf("unix cast needs time.Time,got %s", dest.Type())
+	}
+	dest.Set(reflect.ValueOf(time.Unix(seconds, 0)))
+	return nil
+}
+func (unixCaster) Set(field *Field, src reflect.Value) (interface{}, error) {
+	t, ok := src.Interface().(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unix cast needs time.Time,got %s", src.Type())
+	}
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.Unix(), nil
+}
+
+/*
+decimalCaster keep decimal columns as strings to avoid float rounding,`cast:decimal:2` formats them with 2 decimal places,
+an empty string is stored as NULL
+*/
+type decimalCaster struct{}
+
+func (decimalCaster) format(field *Field, value interface{}) (string, error) {
+	var r *big.Rat
+	switch v := value.(type) {
+	case int64:
+		r = new(big.Rat).SetInt64(v)
+	case float64:
+		r = new(big.Rat)
+		if r.SetFloat64(v) == nil {
+			return "", fmt.Errorf("invalid decimal %v", v)
+		}
+	case []byte, string:
+		b, _ := castBytes(v)
+		var ok bool
+		if r, ok = new(big.Rat).SetString(string(b)); !ok {
+			return "", fmt.Errorf("invalid decimal %s", b)
+		}
+		if len(field.CastArgs) == 0 {
+			return string(b), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported database value %T", value)
+	}
+	if len(field.CastArgs) == 0 {
+		if f, ok := value.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return r.RatString(), nil
+	}
+	scale, err := strconv.Atoi(field.CastArgs[0])
+	if err != nil {
+		return "", err
+	}
+	return r.FloatString(scale), nil
+}
+func (c decimalCaster) Get(field *Field, dest reflect.Value, value interface{}) error {
+	if dest.Kind() != reflect.String {
+		return fmt.Errorf("decimal cast needs string,got %s", dest.Type())
+	}
+	s, err := c.format(field, value)
+	if err != nil {
+		return err
+	}
+	dest.SetString(s)
+	return nil
+}
+func (c decimalCaster) Set(field *Field, src reflect.Value) (interface{}, error) {
+	if src.Kind() != reflect.String {
+		return nil, fmt.Errorf("decimal cast needs string,got %s", src.Type())
+	}
+	if src.String() == "" {
+		return nil, nil
+	}
+	return c.format(field, src.String())
+}
+
+/*
+encryptedCaster store a string encrypted by AES-GCM with the key set by SetEncryptionKey,the database value is base64(nonce + ciphertext)
+*/
+type encryptedCaster struct{}
+
+func (encryptedCaster) Get(field *Field, dest reflect.Value, value interface{}) error {
+	if encryptionAEAD == nil {
+		return errors.New("encryption key not set")
+	}
+	if dest.Kind() != reflect.String {
+		return fmt.Errorf("encrypted cast needs string,got %s", dest.Type())
+	}
+	b, err := castBytes(value)
+	if err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(string(b))
+	if err != nil {
+		return err
+	}
+	size := encryptionAEAD.NonceSize()
+	if len(data) < size {
+		return errors.New("invalid encrypted value")
+	}
+	plain, err := encryptionAEAD.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return err
+	}
+	dest.SetString(string(plain))
+	return nil
+}
+func (encryptedCaster) Set(field *Field, src reflect.Value) (interface{}, error) {
+	if encryptionAEAD == nil {
+		return nil, errors.New("encryption key not set")
+	}
+	if src.Kind() != reflect.String {
+		return nil, fmt.Errorf("encrypted cast needs string,got %s", src.Type())
+	}
+	nonce := make([]byte, encryptionAEAD.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(encryptionAEAD.Seal(nonce, nonce, []byte(src.String()), nil)), nil
+}
@@ -1,28 +1,35 @@
 package goeloquent
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type Driver string
 
 const (
 	DriverMysql               Driver = "mysql"
+	DriverPostgres            Driver = "postgres"
+	DriverSqlite              Driver = "sqlite"
 	GlobalScopeWithoutTrashed        = "WithoutTrashed"
 	ColumnDeletedAt                  = "DELETED_AT"
 	ColumnCreatedAt                  = "CREATED_AT"
 	ColumnUpdatedAt                  = "UPDATED_AT"
 	ColumnPrimaryKey                 = "primaryKey"
 	WithAggregate                    = "Aggregate"
+	CastTagName                      = "cast"
 )
 
 type DBConfig struct {
 	Driver          Driver
 	Name            string
-	ReadHost        []string
-	WriteHost       []string
+	ReadHost        []string //selects go to a random read host
+	WriteHost       []string //writes,locking reads and transactions go to the write host
+	Sticky          bool     //reads after a write in the same StickyContext go to the write host
 	Host            string
 	Port            string
 	Database        string
@@ -46,8 +53,13 @@ type DBConfig struct {
 	IsolationLevel  string
 	// pgsql
 	Sslmode   string
+	Schema    string
 	TLS       string
 	EnableLog bool
+	//logging
+	Logger             *slog.Logger  //slog.Default() if nil
+	SlowQueryThreshold time.Duration //statements slower than this are logged at warn level,0 to disable
+	RedactColumns      []string      //bindings of these columns are replaced in log records,like password
 	//perf
 	//interpolateParams TODO
 }
@@ -84,7 +96,14 @@ type Model struct {
 	CreatedAt                  string                       //database create timestamp column name
 	DeletedAt                  string                       //database delete timestamp column name
 	SoftDelete                 bool                         //has soft delete
+	Tenant                     string                       //database tenant column name
 	GlobalScopes               map[string]ScopeFunc         //registered global scopes
+	LocalScopes                map[string]reflect.Value     //scope name => model Scope{Name} method
+	Accessors                  map[string]reflect.Method    //studly attribute name => model Get{Name}Attribute method
+	Mutators                   map[string]reflect.Method    //struct field name => model Set{Name}Attribute method
+	Hidden                     map[string]struct{}          //keys excluded from ToMap/ToJSON
+	Visible                    map[string]struct{}          //only these keys are included in ToMap/ToJSON if it's not empty
+	Appends                    map[string]struct{}          //computed attributes appended to ToMap/ToJSON by their accessors
 	Guards                     map[string]struct{}          //guarded model fields when use Save(map[string]interface{})/Fill(map[string]interface{})
 	Fillables                  map[string]struct{}          //fillable model fields when use Save(map[string]interface{})/Fill(map[string]interface{})
 	EagerRelations             map[string]RelationFunc      //eager loaded relations
@@ -99,6 +118,8 @@ type Field struct {
 	IndirectFieldType reflect.Type
 	Tag               reflect.StructTag
 	TagSettings       map[string]string
+	Cast              Caster   //cast between the database value and the field value
+	CastArgs          []string //cast args,`cast:decimal:2` => []string{"2"}
 }
 
 /*
@@ -119,6 +140,9 @@ func Parse(modelType reflect.Type) (model *Model, err error) {
 		EagerRelations:          make(map[string]RelationFunc),
 		EagerRelationAggregates: make(map[string]RelationAggregate),
 		GlobalScopes:            make(map[string]ScopeFunc),
+		LocalScopes:             make(map[string]reflect.Value),
+		Accessors:               make(map[string]reflect.Method),
+		Mutators:                make(map[string]reflect.Method),
 		Aggregates:              make(map[string]string),
 	}
 	if t, ok := modelValue.Interface().(TableName); ok {
@@ -138,24 +162,30 @@ func Parse(modelType reflect.Type) (model *Model, err error) {
 
 	for i := 0; i < modelType.NumField(); i++ {
 		if _, ok := modelType.Field(i).Tag.Lookup(EloquentTagName); ok || modelType.Field(i).Name == EloquentName {
-			model.ParseField(modelType.Field(i))
+			if _, err = model.ParseField(modelType.Field(i)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	funcs := map[string]string{
-		EventSaving:     EventSaving,
-		EventSaved:      EventSaved,
-		EventCreating:   EventCreating,
-		EventCreated:    EventCreated,
-		EventUpdating:   EventUpdating,
-		EventUpdated:    EventUpdated,
-		EventDeleteing:  EventDeleteing,
-		EventDeleted:    EventDeleted,
-		EventRetrieving: EventRetrieving,
-		EventRetrieved:  EventRetrieved,
-		EventBooting:    EventBooting,
-		EventBoot:       EventBoot,
-		EventBooted:     EventBooted,
+		EventSaving:        EventSaving,
+		EventSaved:         EventSaved,
+		EventCreating:      EventCreating,
+		EventCreated:       EventCreated,
+		EventUpdating:      EventUpdating,
+		EventUpdated:       EventUpdated,
+		EventDeleteing:     EventDeleteing,
+		EventDeleted:       EventDeleted,
+		EventRestoring:     EventRestoring,
+		EventRestored:      EventRestored,
+		EventForceDeleting: EventForceDeleting,
+		EventForceDeleted:  EventForceDeleted,
+		EventRetrieving:    EventRetrieving,
+		EventRetrieved:     EventRetrieved,
+		EventBooting:       EventBooting,
+		EventBoot:          EventBoot,
+		EventBooted:        EventBooted,
 	}
 	ptrReciver := reflect.PtrTo(modelType)
 	for i := 0; i < ptrReciver.NumMethod(); i++ {
@@ -164,8 +194,24 @@ func Parse(modelType reflect.Type) (model *Model, err error) {
 		}
 
 		if ptrReciver.Method(i).Name == EloquentAddGlobalScopes {
-			res := modelValue.MethodByName(EloquentAddGlobalScopes).Call([]reflect.Value{})
-			model.GlobalScopes = res[0].Interface().(map[string]ScopeFunc)
+			//merge so the soft delete scope is kept
+			for name, scope := range modelValue.Interface().(IGlobalScopes).EloquentAddGlobalScopes() {
+				model.GlobalScopes[name] = scope
+			}
+		}
+		model.parseLocalScope(ptrReciver.Method(i), modelValue.Method(i))
+		model.parseAttributeMethod(ptrReciver.Method(i))
+		if ptrReciver.Method(i).Name == EloquentGetHidden {
+			res := modelValue.MethodByName(EloquentGetHidden).Call([]reflect.Value{})
+			model.Hidden = res[0].Interface().(map[string]struct{})
+		}
+		if ptrReciver.Method(i).Name == EloquentGetVisible {
+			res := modelValue.MethodByName(EloquentGetVisible).Call([]reflect.Value{})
+			model.Visible = res[0].Interface().(map[string]struct{})
+		}
+		if ptrReciver.Method(i).Name == EloquentGetAppends {
+			res := modelValue.MethodByName(EloquentGetAppends).Call([]reflect.Value{})
+			model.Appends = res[0].Interface().(map[string]struct{})
 		}
 		if ptrReciver.Method(i).Name == EloquentGetFillable {
 			res := modelValue.MethodByName(EloquentGetFillable).Call([]reflect.Value{})
@@ -173,7 +219,7 @@ func Parse(modelType reflect.Type) (model *Model, err error) {
 		}
 		if ptrReciver.Method(i).Name == EloquentGetGuarded {
 			if model.Fillables != nil && len(model.Fillables) > 0 {
-				panic("can not use guarded with fillable")
+				return nil, fmt.Errorf("can not use guarded with fillable in model:%s", model.Name)
 			}
 			res := modelValue.MethodByName(EloquentGetGuarded).Call([]reflect.Value{})
 			model.Guards = res[0].Interface().(map[string]struct{})
@@ -195,14 +241,19 @@ func Parse(modelType reflect.Type) (model *Model, err error) {
 	if model.PrimaryKey == nil {
 		model.PrimaryKey = model.FieldsByDbName["id"]
 	}
+	for name := range model.Appends {
+		if _, ok := model.Accessors[ToStudlyCase(name)]; !ok {
+			return nil, fmt.Errorf("accessor %s%s%s for appended attribute %s on model %s not found", AccessorPrefix, ToStudlyCase(name), AttributeSuffix, name, model.Name)
+		}
+	}
 
 	return model, err
 }
 
 /*
-ParseField parse model field to Model.Field
+ParseField parse model field to Model.Field,an error is returned for unknown tags,casts and relation methods
 */
-func (m *Model) ParseField(field reflect.StructField) *Field {
+func (m *Model) ParseField(field reflect.StructField) (*Field, error) {
 	modelField := &Field{
 		Name:              field.Name,
 		ColumnName:        field.Name,
@@ -238,6 +289,19 @@ func (m *Model) ParseField(field reflect.StructField) *Field {
 				m.GlobalScopes[GlobalScopeWithoutTrashed] = func(builder *EloquentBuilder) *EloquentBuilder {
 					return builder.WhereNull(builder.QualifyColumn(m.DeletedAt))
 				}
+			case CastTagName:
+				castArgs := strings.SplitN(value, ":", 2)
+				caster, ok := RegisteredCasters.Load(castArgs[0])
+				if !ok {
+					return nil, fmt.Errorf("no registered caster found for %s", castArgs[0])
+				}
+				modelField.Cast = caster.(Caster)
+				if len(castArgs) > 1 {
+					modelField.CastArgs = strings.Split(castArgs[1], ",")
+				}
+			case ColumnTenant:
+				m.Tenant = modelField.ColumnName
+				m.GlobalScopes[GlobalScopeTenant] = tenantScope(m)
 
 			case string(RelationHasMany),
 				string(RelationHasOne),
@@ -254,7 +318,7 @@ func (m *Model) ParseField(field reflect.StructField) *Field {
 				v := reflect.New(m.ModelType)
 				m.Relations[field.Name] = v.MethodByName(methodName)
 				if !m.Relations[field.Name].IsValid() {
-					panic(fmt.Sprintf("relation method %s on model %s not found", methodName, m.Name))
+					return nil, fmt.Errorf("relation method %s on model %s not found", methodName, m.Name)
 				}
 
 			case WithAggregate:
@@ -263,7 +327,7 @@ func (m *Model) ParseField(field reflect.StructField) *Field {
 					m.Aggregates[value] = modelField.Name
 				}
 			default:
-				panic(fmt.Sprintf("unknown tag %s in model:%s field:%s", key, m.Name, field.Name))
+				return nil, fmt.Errorf("unknown tag %s in model:%s field:%s", key, m.Name, field.Name)
 			}
 		}
 
@@ -276,39 +340,58 @@ func (m *Model) ParseField(field reflect.StructField) *Field {
 		m.PivotFieldIndex = EloquentModelPivotFieldIndex
 	}
 
-	return modelField
+	return modelField, nil
 }
-func GetRegisteredModel(name string) reflect.Value {
+
+/*
+GetRegisteredModel get a model registered by RegisterModels
+*/
+func GetRegisteredModel(name string) (reflect.Value, error) {
 	v, ok := RegisteredModelsMap.Load(name)
 	if !ok {
-		panic(fmt.Sprintf("no registered model found for %s", name))
+		return reflect.Value{}, fmt.Errorf("no registered model found for %s", name)
+	}
+	return v.(reflect.Value), nil
+}
+
+/*
+MustGetRegisteredModel same as GetRegisteredModel but panic if the model isn't registered
+*/
+func MustGetRegisteredModel(name string) reflect.Value {
+	v, err := GetRegisteredModel(name)
+	if err != nil {
+		panic(err.Error())
 	}
-	return v.(reflect.Value)
+	return v
 }
 func GetParsed(name string) (interface{}, bool) {
 	return ParsedModelsMap.Load(name)
 }
 
 /*
-GetParsedModel get parsed ModelConfig from cache or parse it
+ParseModel get parsed ModelConfig from cache or parse it,an error is returned if the model can't be parsed
  1. if model is a string, get model by name from cache
  2. if model is a reflect.Type, get model by name from cache
 */
-func GetParsedModel(model interface{}) *Model {
+func ParseModel(model interface{}) (*Model, error) {
 	var target reflect.Type
 	switch t := model.(type) {
 	case *Model:
-		return t
+		return t, nil
 	case Model:
-		return &t
+		return &t, nil
 	case reflect.Type:
 		target = t
 	case string:
-		target = GetRegisteredModel(t).Type()
+		v, err := GetRegisteredModel(t)
+		if err != nil {
+			return nil, err
+		}
+		target = v.Type()
 	default:
 		value := reflect.ValueOf(model)
 		if value.Kind() != reflect.Ptr {
-			panic("must be a pointer")
+			return nil, errors.New("must be a pointer")
 		}
 		modelValue := reflect.Indirect(value)
 
@@ -326,10 +409,24 @@ func GetParsedModel(model interface{}) *Model {
 	name := target.PkgPath() + "." + target.Name()
 	i, ok := GetParsed(name)
 	if ok {
-		return i.(*Model)
+		return i.(*Model), nil
+	}
+	parsed, err := Parse(target)
+	if err != nil {
+		return nil, err
 	}
-	parsed, _ := Parse(target)
 	ParsedModelsMap.Store(name, parsed)
-	return parsed
+	return parsed, nil
 
 }
+
+/*
+MustParseModel same as ParseModel but panic if the model can't be parsed
+*/
+func MustParseModel(model interface{}) *Model {
+	parsed, err := ParseModel(model)
+	if err != nil {
+		panic(err.Error())
+	}
+	return parsed
+}
@@ -1,33 +1,115 @@
 package goeloquent
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
 type Connection struct {
-	DB             *sql.DB
+	DB             *sql.DB   //write connection,used for everything when there is no read connection
+	ReadDBs        []*sql.DB //read connections,one is picked randomly for each select
 	Config         *DBConfig
 	ConnectionName string
 }
 
+type recordsModifiedKey struct{}
+
+/*
+StickyContext returns a context which remembers writes made with it.
+When DBConfig.Sticky is on,selects using this context after a write are sent to the write connection
+so the request can read its own writes before they are replicated.
+
+	ctx := goeloquent.StickyContext(r.Context())
+*/
+func StickyContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recordsModifiedKey{}, &atomic.Bool{})
+}
+
+/*
+RecordsHaveBeenModified mark the sticky context as written
+*/
+func RecordsHaveBeenModified(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if modified, ok := ctx.Value(recordsModifiedKey{}).(*atomic.Bool); ok {
+		modified.Store(true)
+	}
+}
+
+/*
+HasModifiedRecords determine if records have been modified with the sticky context
+*/
+func HasModifiedRecords(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	modified, ok := ctx.Value(recordsModifiedKey{}).(*atomic.Bool)
+	return ok && modified.Load()
+}
+
+/*
+GetReadDB get a random read connection,fallback to the write connection
+*/
+func (c *Connection) GetReadDB() *sql.DB {
+	if len(c.ReadDBs) == 0 {
+		return c.DB
+	}
+	return c.ReadDBs[rand.Intn(len(c.ReadDBs))]
+}
+
+/*
+Select run a select statement against a read connection
+*/
 func (c *Connection) Select(query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
+	return c.SelectContext(context.Background(), query, bindings, dest, mapping)
+}
+
+/*
+SelectContext run a select statement against a read connection,the query is cancelled with ctx
+*/
+func (c *Connection) SelectContext(ctx context.Context, query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
+	return c.runSelect(ctx, c.GetReadDB(), query, bindings, dest, mapping)
+}
+
+/*
+SelectFromWriteConnection run a select statement against the write connection
+*/
+func (c *Connection) SelectFromWriteConnection(query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
+	return c.SelectFromWriteConnectionContext(context.Background(), query, bindings, dest, mapping)
+}
+
+/*
+SelectFromWriteConnectionContext run a select statement against the write connection,the query is cancelled with ctx
+*/
+func (c *Connection) SelectFromWriteConnectionContext(ctx context.Context, query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
+	return c.runSelect(ctx, c.DB, query, bindings, dest, mapping)
+}
+
+func (c *Connection) runSelect(ctx context.Context, db *sql.DB, query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
 	var stmt *sql.Stmt
 	var rows *sql.Rows
 	now := time.Now()
-	stmt, err = c.DB.Prepare(query)
+	defer func() {
+		c.logQuery(ctx, query, bindings, now, &result, err)
+	}()
+	stmt, err = db.PrepareContext(ctx, query)
 	result.Sql = query
 	result.Bindings = bindings
 	if err != nil {
+		err = c.queryError(query, bindings, err)
 		result.Error = err
 		return
 	}
 	defer stmt.Close()
-	rows, err = stmt.Query(bindings...)
+	DB.FireEvent(EventStatementPrepared, StatementPreparedEvent{ConnectionName: c.ConnectionName, Sql: query, Bindings: bindings})
+	rows, err = stmt.QueryContext(ctx, bindings...)
 	if err != nil {
+		err = c.queryError(query, bindings, err)
 		result.Error = err
-
 		return
 	}
 	defer rows.Close()
@@ -39,7 +121,8 @@ func (c *Connection) Select(query string, bindings []interface{}, dest interface
 	result.Bindings = bindings
 	result.Time = time.Since(now)
 	if result.Error != nil {
-		err = errors.New(result.Error.Error())
+		err = c.queryError(query, bindings, result.Error)
+		result.Error = err
 	}
 	DB.FireEvent(EventExecuted, result)
 
@@ -47,41 +130,93 @@ func (c *Connection) Select(query string, bindings []interface{}, dest interface
 }
 
 func (c *Connection) BeginTransaction() (*Transaction, error) {
-	begin, err := c.DB.Begin()
-	if err != nil {
-		return nil, errors.New(err.Error())
-	}
-	tx := &Transaction{
-		Tx:             begin,
-		ConnectionName: c.ConnectionName,
-	}
-	DB.FireEvent(EventTransactionBegin, tx)
-	return tx, nil
+	return c.BeginTransactionContext(context.Background(), nil)
+}
+
+/*
+BeginTransactionContext start a transaction with ctx,the transaction is rolled back when ctx is done,
+queries built from tx.Query() or made with tx.GetContext() use the transaction,
+if ctx already carries a transaction of this connection a savepoint is created in it instead
+*/
+func (c *Connection) BeginTransactionContext(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return c.beginTransaction(ctx, opts)
 }
 func (c *Connection) Transaction(closure TxClosure) (res interface{}, err error) {
-	begin, err := c.DB.Begin()
+	return c.TransactionContext(context.Background(), closure)
+}
+
+/*
+TransactionContext execute the closure within a transaction started with ctx,
+it's rolled back if the closure returns an error or panics,
+if ctx already carries a transaction of this connection the closure runs in a savepoint so transactions can be nested
+
+ 1. c.TransactionContext(ctx, func(tx *Transaction) (Result, error) { return createOrder(tx.GetContext()) }) createOrder can start its own transaction
+*/
+func (c *Connection) TransactionContext(ctx context.Context, closure TxClosure) (res interface{}, err error) {
+	tx, err := c.beginTransaction(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if result := recover(); result != nil {
-			if e, ok := result.(error); ok {
-				err = e
-			} else {
-				err = errors.New("error occurred during transaction")
-			}
-			_ = begin.Rollback()
-			DB.FireEvent(EventTransactionRollback, err)
-		} else {
-			err = begin.Commit()
-			DB.FireEvent(EventTransactionCommitted, err)
+	return runTransaction(tx, closure)
+}
+
+/*
+TransactionWithRetry execute the closure within a transaction,re-run it up to attempts times when it fails on a deadlock or a lock wait timeout
+*/
+func (c *Connection) TransactionWithRetry(closure TxClosure, attempts int, backoff time.Duration) (res interface{}, err error) {
+	return c.TransactionWithRetryContext(context.Background(), closure, attempts, backoff)
+}
+
+/*
+TransactionWithRetryContext execute the closure within a transaction started with ctx,
+when it fails on an error the connection's ErrorInspector classifies as a deadlock or a lock wait timeout
+the transaction is rolled back and the closure is re-run after a jittered exponential backoff,
+up to attempts runs in total,the closure must be safe to run more than once,
+a transaction nested in the one carried by ctx is not retried because the error aborts the outermost transaction
+
+ 1. c.TransactionWithRetry(closure, 3, 50*time.Millisecond) waits 25-50ms then 50-100ms between the runs
+*/
+func (c *Connection) TransactionWithRetryContext(ctx context.Context, closure TxClosure, attempts int, backoff time.Duration) (res interface{}, err error) {
+	if TransactionFromContext(ctx, c.ConnectionName) != nil {
+		return c.TransactionContext(ctx, closure)
+	}
+	for attempt := 1; ; attempt++ {
+		res, err = c.TransactionContext(ctx, closure)
+		if err == nil || attempt >= attempts || !c.CausedByConcurrencyError(err) {
+			return
 		}
-	}()
-	tx := &Transaction{
-		Tx:             begin,
-		ConnectionName: c.ConnectionName,
+		if waitErr := sleepContext(ctx, retryBackoff(backoff, attempt)); waitErr != nil {
+			return
+		}
+	}
+}
+
+/*
+retryBackoff backoff doubled for every attempt,with a random jitter of up to half of it
+*/
+func retryBackoff(backoff time.Duration, attempt int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	if attempt > 10 {
+		attempt = 10
+	}
+	wait := backoff << (attempt - 1)
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	return closure(tx)
 }
 
 func (c *Connection) Insert(query string, bindings []interface{}) (result Result, err error) {
@@ -96,18 +231,31 @@ func (c *Connection) Delete(query string, bindings []interface{}) (result Result
 }
 
 func (c *Connection) AffectingStatement(query string, bindings []interface{}) (result Result, err error) {
+	return c.AffectingStatementContext(context.Background(), query, bindings)
+}
+
+/*
+AffectingStatementContext run a statement on the write connection and return the raw result,the query is cancelled with ctx
+*/
+func (c *Connection) AffectingStatementContext(ctx context.Context, query string, bindings []interface{}) (result Result, err error) {
 	result.Bindings = bindings
 	result.Sql = query
 	now := time.Now()
-	stmt, errP := c.DB.Prepare(query)
+	defer func() {
+		c.logQuery(ctx, query, bindings, now, &result, err)
+	}()
+	stmt, errP := c.DB.PrepareContext(ctx, query)
 	if errP != nil {
 		err = errP
+		err = c.queryError(query, bindings, err)
 		result.Error = err
 		return
 	}
 	defer stmt.Close()
-	rawResult, err := stmt.Exec(bindings...)
+	DB.FireEvent(EventStatementPrepared, StatementPreparedEvent{ConnectionName: c.ConnectionName, Sql: query, Bindings: bindings})
+	rawResult, err := stmt.ExecContext(ctx, bindings...)
 	if err != nil {
+		err = c.queryError(query, bindings, err)
 		result.Error = err
 		return
 	}
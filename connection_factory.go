@@ -1,31 +1,90 @@
 package goeloquent
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 )
 
 type ConnectionFactory struct {
 }
 type Connector interface {
-	connect(config *DBConfig) *Connection
+	connect(config *DBConfig) (*Connection, error)
 }
 
-func (f ConnectionFactory) Make(config *DBConfig) *Connection {
+func (f ConnectionFactory) Make(config *DBConfig) (*Connection, error) {
 	return f.MakeConnection(config)
 }
-func (f ConnectionFactory) MakeConnection(config *DBConfig) *Connection {
+func (f ConnectionFactory) MakeConnection(config *DBConfig) (*Connection, error) {
 	return f.CreateConnection(config)
 }
-func (f ConnectionFactory) CreateConnection(config *DBConfig) *Connection {
+
+/*
+CreateConnection create a connection based on the configuration,an error is returned if the driver is unsupported or the database can't be reached
+*/
+func (f ConnectionFactory) CreateConnection(config *DBConfig) (*Connection, error) {
+	connector, err := f.CreateConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.ReadHost) > 0 || len(config.WriteHost) > 0 {
+		return f.createReadWriteConnection(connector, config)
+	}
+	return connector.connect(config)
+}
+
+/*
+CreateConnector Create a connector instance based on the configuration.
+*/
+func (f ConnectionFactory) CreateConnector(config *DBConfig) (Connector, error) {
 	switch config.Driver {
 	case DriverMysql:
-		connector := MysqlConnector{}
-		conn := connector.connect(config)
-		return conn
+		return MysqlConnector{}, nil
+	case DriverPostgres:
+		return PostgresConnector{}, nil
+	case DriverSqlite:
+		return SqliteConnector{}, nil
 	case "":
-		panic("a driver must be specified")
+		return nil, errors.New("a driver must be specified")
 	default:
-		panic(fmt.Sprintf("unsupported driver:%s", config.Driver))
+		return nil, fmt.Errorf("unsupported driver:%s", config.Driver)
+	}
+}
+
+/*
+createReadWriteConnection Create a connection which writes to the WriteHost and reads from every ReadHost,
+reads go to the writer when ReadHost is empty,the opened databases are closed if any host can't be reached
+*/
+func (f ConnectionFactory) createReadWriteConnection(connector Connector, config *DBConfig) (*Connection, error) {
+	conn, err := connector.connect(f.getHostConfig(config, config.WriteHost))
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range config.ReadHost {
+		reader, err := connector.connect(f.getHostConfig(config, []string{host}))
+		if err != nil {
+			for _, db := range conn.ReadDBs {
+				db.Close()
+			}
+			conn.DB.Close()
+			return nil, err
+		}
+		conn.ReadDBs = append(conn.ReadDBs, reader.DB)
+	}
+	conn.Config = config
+	return conn, nil
+}
+
+/*
+getHostConfig copy the config with Host replaced by one of the given hosts(picked randomly),
+Host is kept when hosts is empty
+*/
+func (f ConnectionFactory) getHostConfig(config *DBConfig, hosts []string) *DBConfig {
+	c := *config
+	c.ReadHost = nil
+	c.WriteHost = nil
+	if len(hosts) > 0 {
+		c.Host = hosts[rand.Intn(len(hosts))]
 	}
-	return nil
+	return &c
 }
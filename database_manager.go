@@ -1,22 +1,29 @@
 package goeloquent
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 type DatabaseManager struct {
 	Connections map[string]*Connection
 	Factory     ConnectionFactory
 	Configs     map[string]*DBConfig
-	Listeners   map[string][]interface{}
+	Events      *Dispatcher
+
+	queryLogMu     sync.Mutex
+	loggingQueries bool
+	queryLog       []Log
 }
 
 var ParsedModelsMap sync.Map          //pkg+modelname:*eloquent.Model , get parsed model config
 var RegisteredModelsMap sync.Map      //name:reflect.Value
 var RegisteredMorphModelsMap sync.Map //model pointer:alias , when save relation to db, convert models.User => users
 var RegisteredDBMap sync.Map          //alias:model pointer , when query relation from db, convert users => models.User
+var BootedModelsMap sync.Map          //model name:struct{} , models whose boot events are fired
 func init() {
 	ParsedModelsMap = sync.Map{}
 	RegisteredModelsMap = sync.Map{}
@@ -25,52 +32,67 @@ func init() {
 }
 
 /*
-Connection get a connection pointer by name
+Connection get a connection pointer by name,it's made on first use,
+an error is returned if the connection isn't configured or the database can't be reached,the next call tries again
 */
-func (dm *DatabaseManager) Connection(connectionName string) *Connection {
-	connection, ok := dm.Connections[connectionName]
-	if !ok {
-		dm.Connections[connectionName] = dm.MakeConnection(connectionName)
-		connection, _ = dm.Connections[connectionName]
+func (dm *DatabaseManager) Connection(connectionName string) (*Connection, error) {
+	if connection, ok := dm.Connections[connectionName]; ok {
+		return connection, nil
+	}
+	return dm.MakeConnection(connectionName)
+}
+
+/*
+MustConnection get a connection pointer by name,panic if it can't be made
+
+ 1. DB.MustConnection("chat").Table("messages")
+*/
+func (dm *DatabaseManager) MustConnection(connectionName string) *Connection {
+	connection, err := dm.Connection(connectionName)
+	if err != nil {
+		panic(err.Error())
 	}
 	return connection
 }
 
 /*
-Conn get a connection by name
+Conn get a connection by name,panic if it can't be made
 */
 func (dm *DatabaseManager) Conn(connectionName string) Connection {
-	return *(dm.Connection(connectionName))
+	return *(dm.MustConnection(connectionName))
 }
 
 func (dm *DatabaseManager) getDefaultConnection() (defaultConnectionName string) {
 	defaultConnectionName = DefaultConnectionName
 	return
 }
-func (dm *DatabaseManager) Listen(eventName string, listener interface{}) {
-	if _, ok := dm.Listeners[eventName]; !ok {
-		dm.Listeners[eventName] = []interface{}{}
-	}
-	dm.Listeners[eventName] = append(dm.Listeners[eventName], listener)
-}
-func (dm *DatabaseManager) FireEvent(eventName string, params ...interface{}) {
-	if _, ok := dm.Listeners[eventName]; !ok {
-		return
-	}
-	for _, listener := range dm.Listeners[eventName] {
-		switch eventName {
-		case EventOpened:
-			listener.(func(map[string]DBConfig))(params[0].(map[string]DBConfig))
-		case EventConnectionCreated:
-			listener.(func(*Connection))(params[0].(*Connection))
-		case EventExecuted:
-			listener.(func(result Result))(params[0].(Result))
-		case EventTransactionBegin:
-		case EventTransactionCommitted:
-		case EventTransactionRollback:
-			listener.(func(err error))(params[0].(error))
-		}
+
+var dispatcherMu sync.Mutex
+
+/*
+Dispatcher get the event dispatcher,it's created on first use if the manager isn't made by Open
+*/
+func (dm *DatabaseManager) Dispatcher() *Dispatcher {
+	dispatcherMu.Lock()
+	defer dispatcherMu.Unlock()
+	if dm.Events == nil {
+		dm.Events = NewDispatcher()
 	}
+	return dm.Events
+}
+
+/*
+Listen register a listener for an event,call Unsubscribe on the returned subscription to remove it,see Dispatcher.Listen
+*/
+func (dm *DatabaseManager) Listen(eventName string, listener interface{}) *Subscription {
+	return dm.Dispatcher().Listen(eventName, listener)
+}
+
+/*
+FireEvent call the listeners of an event with its payload
+*/
+func (dm *DatabaseManager) FireEvent(eventName string, payload interface{}) error {
+	return dm.Dispatcher().Fire(eventName, payload)
 }
 
 /*
@@ -83,17 +105,20 @@ func (dm *DatabaseManager) GetConfig(name string) *DBConfig {
 /*
 MakeConnection make a connection by name
 */
-func (dm *DatabaseManager) MakeConnection(connectionName string) *Connection {
+func (dm *DatabaseManager) MakeConnection(connectionName string) (*Connection, error) {
 	config, ok := dm.Configs[connectionName]
 	if !ok {
-		panic(fmt.Sprintf("Database connection %s not configured.", connectionName))
+		return nil, fmt.Errorf("Database connection %s not configured.", connectionName)
 	}
 
-	conn := dm.Factory.Make(config)
+	conn, err := dm.Factory.Make(config)
+	if err != nil {
+		return nil, err
+	}
 	conn.ConnectionName = connectionName
 	dm.Connections[connectionName] = conn
-	dm.FireEvent(EventConnectionCreated, conn)
-	return conn
+	dm.FireEvent(EventConnectionCreated, ConnectionCreatedEvent{Connection: conn})
+	return conn, nil
 }
 
 /*
@@ -106,50 +131,99 @@ func (dm *DatabaseManager) Table(params ...string) *Builder {
 }
 
 /*
-Model get a eloquent builder and set model
+Model get a eloquent builder and set model,errors met while parsing the model or making its connection are returned by the query methods
 
 1. Mode(&User{})
 
 2. Mode("User")
 */
 func (dm *DatabaseManager) Model(model ...interface{}) *EloquentBuilder {
-
-	c := dm.Connection(DefaultConnectionName)
-	if len(model) == 0 {
-		return c.Model()
-	}
-	return c.Model(model[0])
-
+	return NewEloquentBuilder(model...)
 }
 func (dm *DatabaseManager) Select(query string, bindings []interface{}, dest interface{}) (Result, error) {
-	return dm.Connection(DefaultConnectionName).Select(query, bindings, dest, nil)
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Select(query, bindings, dest, nil)
 }
 func (dm *DatabaseManager) Insert(query string, bindings []interface{}) (Result, error) {
-	return dm.Connection(DefaultConnectionName).Insert(query, bindings)
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Insert(query, bindings)
 }
 func (dm *DatabaseManager) Update(query string, bindings []interface{}) (Result, error) {
-	return dm.Connection(DefaultConnectionName).Update(query, bindings)
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Update(query, bindings)
 }
 func (dm *DatabaseManager) Delete(query string, bindings []interface{}) (Result, error) {
-	return dm.Connection(DefaultConnectionName).Delete(query, bindings)
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Delete(query, bindings)
 }
 func (dm *DatabaseManager) Statement(query string, bindings []interface{}) (Result, error) {
-	return dm.Connection(DefaultConnectionName).AffectingStatement(query, bindings)
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.AffectingStatement(query, bindings)
 }
 func (dm *DatabaseManager) Query() *Builder {
 	defaultConn := dm.getDefaultConnection()
-	c := dm.Connection(defaultConn)
+	c, err := dm.Connection(defaultConn)
+	if err != nil {
+		return NewQueryBuilder().AddError(err)
+	}
 	return NewQueryBuilder(c)
 }
 
-//func (dm *DatabaseManager) Transaction(closure TxClosure) (interface{}, error) {
-//	ic := dm.Connections[DefaultConnectionName]
-//	return (*ic).Transaction(closure)
-//}
-//func (dm *DatabaseManager) BeginTransaction() (*interfaces.ITransaction, error) {
-//	ic := dm.Connections[DefaultConnectionName]
-//	return (*ic).BeginTransaction()
-//}
+/*
+Transaction execute the closure within a transaction on the default connection
+*/
+func (dm *DatabaseManager) Transaction(closure TxClosure) (interface{}, error) {
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return nil, err
+	}
+	return c.Transaction(closure)
+}
+
+/*
+TransactionContext execute the closure within a transaction on the default connection,
+it runs in a savepoint if ctx already carries a transaction of the default connection
+*/
+func (dm *DatabaseManager) TransactionContext(ctx context.Context, closure TxClosure) (interface{}, error) {
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return nil, err
+	}
+	return c.TransactionContext(ctx, closure)
+}
+
+/*
+TransactionWithRetry execute the closure within a transaction on the default connection,re-run it on deadlocks and lock wait timeouts
+*/
+func (dm *DatabaseManager) TransactionWithRetry(closure TxClosure, attempts int, backoff time.Duration) (interface{}, error) {
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return nil, err
+	}
+	return c.TransactionWithRetry(closure, attempts, backoff)
+}
+func (dm *DatabaseManager) BeginTransaction() (*Transaction, error) {
+	c, err := dm.Connection(DefaultConnectionName)
+	if err != nil {
+		return nil, err
+	}
+	return c.BeginTransaction()
+}
 
 func (dm *DatabaseManager) Create(model interface{}) (res Result, err error) {
 	return dm.Save(model)
@@ -160,7 +234,10 @@ func (dm *DatabaseManager) Init(model interface{}) {
 func (dm *DatabaseManager) Save(modelP interface{}) (res Result, err error) {
 	//TODO: use connection as proxy
 	//TODO: batch create/update/delete
-	parsed := GetParsedModel(modelP)
+	parsed, err := ParseModel(modelP)
+	if err != nil {
+		return Result{Error: err}, err
+	}
 	model := reflect.Indirect(reflect.ValueOf(modelP))
 	if parsed.IsEloquent {
 		ininted := !model.Field(parsed.EloquentModelFieldIndex).IsZero()
@@ -176,7 +253,7 @@ func (dm *DatabaseManager) Save(modelP interface{}) (res Result, err error) {
 	return
 }
 func (dm *DatabaseManager) Boot(modelP interface{}) *EloquentModel {
-	parsed := GetParsedModel(modelP)
+	parsed := MustParseModel(modelP)
 	model := reflect.Indirect(reflect.ValueOf(modelP))
 	if parsed.IsEloquent {
 		ininted := !model.Field(parsed.EloquentModelFieldIndex).IsZero()
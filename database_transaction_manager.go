@@ -1 +1,237 @@
 package goeloquent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+type transactionContextKey struct {
+	connection string
+}
+
+type currentTransactionContextKey struct{}
+
+/*
+WithTransaction return a context carrying the transaction,queries on its connection made with the context join the transaction,
+contexts of transactions from BeginTransactionContext/TransactionContext already carry them
+*/
+func WithTransaction(ctx context.Context, tx *Transaction) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, currentTransactionContextKey{}, tx)
+	return context.WithValue(ctx, transactionContextKey{connection: tx.ConnectionName}, tx)
+}
+
+/*
+CurrentTransaction get the innermost transaction carried by the context on any connection,nil if there isn't one
+*/
+func CurrentTransaction(ctx context.Context) *Transaction {
+	if ctx == nil {
+		return nil
+	}
+	tx, _ := ctx.Value(currentTransactionContextKey{}).(*Transaction)
+	return tx
+}
+
+/*
+TransactionFromContext get the transaction of a connection carried by the context,nil if there isn't one
+*/
+func TransactionFromContext(ctx context.Context, connectionName string) *Transaction {
+	if ctx == nil {
+		return nil
+	}
+	tx, _ := ctx.Value(transactionContextKey{connection: connectionName}).(*Transaction)
+	return tx
+}
+
+/*
+TransactionLevel 1 for a database transaction,2 and above for savepoints nested in it
+*/
+func (t *Transaction) TransactionLevel() int {
+	return t.Level
+}
+
+/*
+IsNested determine if the transaction is a savepoint in another transaction
+*/
+func (t *Transaction) IsNested() bool {
+	return t.Parent != nil
+}
+
+func (t *Transaction) savepointName() string {
+	return "trans" + strconv.Itoa(t.Level)
+}
+
+/*
+beginTransaction begin a database transaction,or a savepoint if ctx carries a transaction of the connection
+*/
+func (c *Connection) beginTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if parent := TransactionFromContext(ctx, c.ConnectionName); parent != nil {
+		return parent.beginSavepoint(ctx)
+	}
+	begin, err := c.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, c.classifyError(err)
+	}
+	tx := &Transaction{
+		Tx:             begin,
+		ConnectionName: c.ConnectionName,
+		Connection:     c,
+		Level:          1,
+	}
+	tx.Context = WithTransaction(ctx, tx)
+	DB.FireEvent(EventTransactionBegin, TransactionBeginEvent{Transaction: tx})
+	return tx, nil
+}
+
+/*
+beginSavepoint create a savepoint in the transaction,it's released on Commit and rolled back to on Rollback
+*/
+func (t *Transaction) beginSavepoint(ctx context.Context) (*Transaction, error) {
+	if ctx == nil {
+		ctx = t.GetContext()
+	}
+	nested := &Transaction{
+		Tx:             t.Tx,
+		ConnectionName: t.ConnectionName,
+		Connection:     t.Connection,
+		Level:          t.Level + 1,
+		Parent:         t,
+	}
+	nested.Context = WithTransaction(ctx, nested)
+	if _, err := t.Tx.ExecContext(ctx, "SAVEPOINT "+nested.savepointName()); err != nil {
+		return nil, err
+	}
+	return nested, nil
+}
+
+/*
+runTransaction run the closure in tx,tx is rolled back if the closure returns an error or panics,otherwise committed
+*/
+func runTransaction(tx *Transaction, closure TxClosure) (res interface{}, err error) {
+	defer func() {
+		if result := recover(); result != nil {
+			if e, ok := result.(error); ok {
+				err = e
+			} else {
+				err = errors.New("error occurred during transaction")
+			}
+			_ = tx.Rollback()
+		} else if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	return closure(tx)
+}
+
+/*
+transaction get the transaction the builder runs in,its own Tx or the transaction of its connection carried by its context
+*/
+func (b *Builder) transaction() *Transaction {
+	if b.Tx != nil {
+		return b.Tx
+	}
+	if b.Context == nil {
+		return nil
+	}
+	c := b.GetConnection()
+	if c == nil {
+		return nil
+	}
+	return TransactionFromContext(b.Context, c.ConnectionName)
+}
+
+/*
+AfterCommit run fn after the outermost transaction commits,
+callbacks added in a savepoint are dropped if the savepoint rolls back
+
+ 1. tx.AfterCommit(func() { publish(order) })
+*/
+func (t *Transaction) AfterCommit(fn func()) {
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+	t.afterCommit = append(t.afterCommit, fn)
+}
+
+/*
+AfterRollback run fn after the transaction or the savepoint it's added in rolls back
+*/
+func (t *Transaction) AfterRollback(fn func()) {
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+	t.afterRollback = append(t.afterRollback, fn)
+}
+
+/*
+AfterCommit run fn after the transaction carried by ctx commits,run it immediately if there is no transaction
+
+ 1. DB.AfterCommit(ctx, func() { publish(order) })
+*/
+func (dm *DatabaseManager) AfterCommit(ctx context.Context, fn func()) {
+	if tx := CurrentTransaction(ctx); tx != nil {
+		tx.AfterCommit(fn)
+		return
+	}
+	fn()
+}
+
+/*
+mergeCallbacks move the callbacks of a released savepoint to the transaction it's created in
+*/
+func (t *Transaction) mergeCallbacks(nested *Transaction) {
+	commit, rollback := nested.takeCallbacks()
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+	t.afterCommit = append(t.afterCommit, commit...)
+	t.afterRollback = append(t.afterRollback, rollback...)
+}
+
+func (t *Transaction) takeCallbacks() (commit []func(), rollback []func()) {
+	t.callbacksMu.Lock()
+	defer t.callbacksMu.Unlock()
+	commit, rollback = t.afterCommit, t.afterRollback
+	t.afterCommit, t.afterRollback = nil, nil
+	return
+}
+
+func (t *Transaction) runAfterCommit() {
+	commit, _ := t.takeCallbacks()
+	for _, fn := range commit {
+		fn()
+	}
+}
+
+func (t *Transaction) runAfterRollback() {
+	_, rollback := t.takeCallbacks()
+	for _, fn := range rollback {
+		fn()
+	}
+}
+
+/*
+deferredEventTransaction get the transaction a model event should wait for,
+nil if the event should fire now
+*/
+func (m *EloquentModel) deferredEventTransaction(eventName string) *Transaction {
+	switch eventName {
+	case EventCreated, EventUpdated, EventSaved, EventDeleted, EventRestored, EventForceDeleted:
+	default:
+		return nil
+	}
+	if model, ok := m.ModelPointer.Interface().(AfterCommitEvents); !ok || !model.EloquentEventsAfterCommit() {
+		return nil
+	}
+	if m.Tx != nil {
+		return m.Tx
+	}
+	return TransactionFromContext(m.Context, MustParseModel(reflect.Indirect(m.ModelPointer).Type()).ConnectionName)
+}
@@ -0,0 +1,211 @@
+package goeloquent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+/*
+Dispatcher a concurrency safe registry of event listeners and model observers,
+listeners and observers can be added and removed from multiple goroutines while events are fired
+*/
+type Dispatcher struct {
+	mu        sync.RWMutex
+	listeners map[string][]*Subscription //event name:listeners
+	observers map[string][]*Subscription //model name:observers
+}
+
+/*
+Subscription a registered listener or observer,returned by Listen and Observe
+
+ 1. sub := DB.Listen(EventExecuted, func(result Result) {...}); sub.Unsubscribe()
+*/
+type Subscription struct {
+	dispatcher *Dispatcher
+	key        string
+	isObserver bool
+	handler    reflect.Value
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+/*
+observerEvents model events an observer can handle with a method named after the event without the Eloquent prefix
+*/
+var observerEvents = []string{
+	EventBooting, EventBooted,
+	EventRetrieving, EventRetrieved,
+	EventSaving, EventSaved,
+	EventCreating, EventCreated,
+	EventUpdating, EventUpdated,
+	EventDeleteing, EventDeleted,
+	EventRestoring, EventRestored,
+	EventForceDeleting, EventForceDeleted,
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		listeners: make(map[string][]*Subscription),
+		observers: make(map[string][]*Subscription),
+	}
+}
+
+/*
+Listen register a listener for an event,the listener must be a func(payload) or func(payload) error,
+payload is the event's type in EventPayloads,a listener with a wrong signature panics here instead of when the event fires
+
+ 1. Listen(EventExecuted, func(result Result) {...})
+ 2. Listen(EventTransactionCommitted, func(e TransactionCommittedEvent) {...})
+ 3. Listen(EventSaving, func(model interface{}) error {...}) return an error to abort the save
+*/
+func (d *Dispatcher) Listen(eventName string, listener interface{}) *Subscription {
+	handler := reflect.ValueOf(listener)
+	checkListener(eventName, handler)
+	sub := &Subscription{dispatcher: d, key: eventName, handler: handler}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners[eventName] = appendSubscription(d.listeners[eventName], sub)
+	return sub
+}
+
+/*
+Observe register an observer for a model,see DatabaseManager.Observe,
+an observer method named after a model event with a wrong signature panics here instead of being skipped when the event fires
+*/
+func (d *Dispatcher) Observe(model interface{}, observer interface{}) *Subscription {
+	if observer == nil {
+		panic("observer can't be nil")
+	}
+	parsed := MustParseModel(model)
+	handler := reflect.ValueOf(observer)
+	checkObserver(handler)
+	sub := &Subscription{dispatcher: d, key: parsed.Name, isObserver: true, handler: handler}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.observers[parsed.Name] = appendSubscription(d.observers[parsed.Name], sub)
+	return sub
+}
+
+/*
+Unsubscribe remove the listener or observer,it's safe to call more than once
+*/
+func (s *Subscription) Unsubscribe() {
+	d := s.dispatcher
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	registry := d.listeners
+	if s.isObserver {
+		registry = d.observers
+	}
+	subs := registry[s.key]
+	kept := make([]*Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if sub != s {
+			kept = append(kept, sub)
+		}
+	}
+	if len(kept) == 0 {
+		delete(registry, s.key)
+	} else {
+		registry[s.key] = kept
+	}
+}
+
+/*
+Fire call the listeners of an event in the order they are registered,stop at the first error
+*/
+func (d *Dispatcher) Fire(eventName string, payload interface{}) error {
+	for _, sub := range d.snapshot(d.listeners, eventName) {
+		if err := sub.call(eventName, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+FireModelEvent call the observers of the model,the listeners of the event and the listeners of EventALL,stop at the first error
+*/
+func (d *Dispatcher) FireModelEvent(eventName string, model interface{}) error {
+	method := strings.TrimPrefix(eventName, "Eloquent")
+	parsed, err := ParseModel(model)
+	if err != nil {
+		return err
+	}
+	for _, sub := range d.snapshot(d.observers, parsed.Name) {
+		fn := sub.handler.MethodByName(method)
+		if !fn.IsValid() {
+			continue
+		}
+		handler, ok := fn.Interface().(func(interface{}) error)
+		if !ok {
+			continue
+		}
+		if err := handler(model); err != nil {
+			return err
+		}
+	}
+	if err := d.Fire(eventName, model); err != nil {
+		return err
+	}
+	return d.Fire(EventALL, ModelEvent{Name: eventName, Model: model})
+}
+
+/*
+snapshot get the subscriptions of a key,slices are never modified in place so it's safe to range over it without the lock
+*/
+func (d *Dispatcher) snapshot(registry map[string][]*Subscription, key string) []*Subscription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return registry[key]
+}
+
+func (s *Subscription) call(eventName string, payload interface{}) error {
+	param := s.handler.Type().In(0)
+	arg := reflect.ValueOf(payload)
+	if !arg.IsValid() {
+		arg = reflect.Zero(param)
+	} else if !arg.Type().AssignableTo(param) {
+		panic(fmt.Sprintf("listener for %s needs %s,got %s", eventName, param, arg.Type()))
+	}
+	out := s.handler.Call([]reflect.Value{arg})
+	if len(out) > 0 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
+
+func appendSubscription(subs []*Subscription, sub *Subscription) []*Subscription {
+	//copy so snapshots taken by Fire are not changed
+	result := make([]*Subscription, len(subs), len(subs)+1)
+	copy(result, subs)
+	return append(result, sub)
+}
+
+func checkListener(eventName string, handler reflect.Value) {
+	if !handler.IsValid() || handler.Kind() != reflect.Func || handler.IsNil() {
+		panic(fmt.Sprintf("listener for %s must be a func", eventName))
+	}
+	t := handler.Type()
+	if t.NumIn() != 1 || t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		panic(fmt.Sprintf("listener for %s must be func(payload) or func(payload) error,got %s", eventName, t))
+	}
+	if payload, ok := EventPayloads[eventName]; ok && !payload.AssignableTo(t.In(0)) {
+		panic(fmt.Sprintf("listener for %s must accept %s,got %s", eventName, payload, t.In(0)))
+	}
+}
+
+func checkObserver(handler reflect.Value) {
+	for _, eventName := range observerEvents {
+		name := strings.TrimPrefix(eventName, "Eloquent")
+		method := handler.MethodByName(name)
+		if !method.IsValid() {
+			continue
+		}
+		if _, ok := method.Interface().(func(interface{}) error); !ok {
+			panic(fmt.Sprintf("observer method %s of %s must be func(model interface{}) error,got %s", name, handler.Type(), method.Type()))
+		}
+	}
+}
@@ -26,11 +26,12 @@ var DefaultConstraint = func(builder *EloquentBuilder) *EloquentBuilder {
 
 type EloquentBuilder struct {
 	*Builder
-	BaseModel     *Model
-	EagerLoad     map[string]func(builder *EloquentBuilder) *EloquentBuilder
-	RemovedScopes map[string]struct{}
-	Pivots        []string
-	PivotWheres   []Where
+	BaseModel      *Model
+	EagerLoad      map[string]func(builder *EloquentBuilder) *EloquentBuilder
+	RemovedScopes  map[string]struct{}
+	TenantBypassed bool //tenant scope is bypassed by WithoutTenant
+	Pivots         []string
+	PivotWheres    []Where
 
 	BeforeQueryCallBacks []func(*EloquentBuilder)
 	AfterQueryCallBacks  []func(*EloquentBuilder)
@@ -149,7 +150,12 @@ Prepare check table,connection,model config before executing the query
 */
 func (b *EloquentBuilder) Prepare(dest interface{}) {
 	if b.BaseModel == nil {
-		b.BaseModel = GetParsedModel(dest)
+		parsed, err := ParseModel(dest)
+		if err != nil {
+			b.AddError(err)
+			return
+		}
+		b.BaseModel = parsed
 	}
 	if b.FromTable == nil {
 		if b.BaseModel.TableResolver != nil {
@@ -159,11 +165,16 @@ func (b *EloquentBuilder) Prepare(dest interface{}) {
 		}
 	}
 	if b.Connection == nil {
+		connectionName := b.BaseModel.ConnectionName
 		if b.BaseModel.ConnectionResolver != nil {
-			b.Connection = DB.Connection(b.BaseModel.ConnectionResolver(b))
-		} else {
-			b.Connection = DB.Connection(b.BaseModel.ConnectionName)
+			connectionName = b.BaseModel.ConnectionResolver(b)
 		}
+		c, err := DB.Connection(connectionName)
+		if err != nil {
+			b.AddError(err)
+			return
+		}
+		b.SetConnection(c)
 	}
 }
 func (b *EloquentBuilder) Get(dest interface{}, columns ...interface{}) (result Result, err error) {
@@ -171,6 +182,9 @@ func (b *EloquentBuilder) Get(dest interface{}, columns ...interface{}) (result
 	b.ApplyGlobalScopes()
 	//table resolver connection resolver
 	b.Prepare(dest)
+	if b.Err != nil {
+		return Result{Error: b.Err}, b.Err
+	}
 	d := reflect.TypeOf(dest).Elem()
 	if d.Kind() == reflect.Slice {
 		d = d.Elem()
@@ -185,6 +199,11 @@ func (b *EloquentBuilder) Get(dest interface{}, columns ...interface{}) (result
 				return Result{}, e
 			}
 		}
+		if DB != nil {
+			if e := DB.fireModelEvent(EventRetrieving, dest); e != nil {
+				return Result{}, e
+			}
+		}
 	}
 	if len(b.EagerLoad) == 0 {
 		if len(b.Pivots) > 0 {
@@ -198,14 +217,16 @@ func (b *EloquentBuilder) Get(dest interface{}, columns ...interface{}) (result
 
 	if err == nil && b.BaseModel.IsEloquent && d.Kind() == reflect.Struct {
 		BatchSync(b.Dest, result.Count > 0)
-		m := reflect.ValueOf(dest).MethodByName(EventRetrieved)
-		if m.IsValid() {
-			m.Call([]reflect.Value{})
+		if result.Count > 0 {
+			if err = b.fireRetrieved(dest); err != nil {
+				return
+			}
 		}
-
 	}
 	if len(b.EagerLoad) > 0 && result.Count > 0 {
-		b.EagerLoadRelations(dest)
+		if err = b.EagerLoadRelations(dest); err != nil {
+			result.Error = err
+		}
 	}
 	return
 }
@@ -226,6 +247,22 @@ func (b *EloquentBuilder) First(dest interface{}, columns ...interface{}) (resul
 	return b.Get(dest, columns...)
 }
 
+/*
+FindOrFail Find a model by its primary key,return a QueryException wrapping ErrRecordNotFound if it doesn't exist.
+*/
+func (b *EloquentBuilder) FindOrFail(dest interface{}, id interface{}) (result Result, err error) {
+	result, err = b.Find(dest, id)
+	return result, recordNotFound(result, err)
+}
+
+/*
+FirstOrFail Execute the query and get the first result,return a QueryException wrapping ErrRecordNotFound if no rows match.
+*/
+func (b *EloquentBuilder) FirstOrFail(dest interface{}, columns ...interface{}) (result Result, err error) {
+	result, err = b.First(dest, columns...)
+	return result, recordNotFound(result, err)
+}
+
 /*
 WhereKey Add a where clause on the primary key to the query.
 */
@@ -280,26 +317,36 @@ func (b *EloquentBuilder) FirstOrCreate(target interface{}, conditions ...map[st
 	return true, nil
 }
 
-func (b *EloquentBuilder) EagerLoadRelations(models interface{}) {
+/*
+EagerLoadRelations load the relations added by With for models,the first error met while loading them is returned
+*/
+func (b *EloquentBuilder) EagerLoadRelations(models interface{}) error {
 
 	var model *Model
+	var err error
 	//with reflect.Value of makeslice
 	if t, ok := models.(*reflect.Value); ok {
 		sliceEleType := t.Type().Elem()
-		model = GetParsedModel(sliceEleType.PkgPath() + "." + sliceEleType.Name())
+		model, err = ParseModel(sliceEleType.PkgPath() + "." + sliceEleType.Name())
 	} else {
-		model = GetParsedModel(models)
+		model, err = ParseModel(models)
+	}
+	if err != nil {
+		return err
 	}
 
 	//models = realDest.Interface()
 	for relationName, fn := range b.EagerLoad {
 		if !strings.Contains(relationName, ".") {
-			b.EagerLoadRelation(models, model, relationName, fn)
+			if err = b.EagerLoadRelation(models, model, relationName, fn); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, relationName string, constraints func(builder *EloquentBuilder) *EloquentBuilder) {
+func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, relationName string, constraints func(builder *EloquentBuilder) *EloquentBuilder) error {
 	if pos := strings.Index(relationName, ":"); pos != -1 {
 		relationName = relationName[0:pos]
 	}
@@ -326,13 +373,13 @@ func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, re
 			relation = relationTemp
 			builder = relationTemp.EloquentBuilder
 		case *HasOneThrough:
-			//relationTemp.FieldName = relationName
-			//relation = relationTemp
-			//builder = relationTemp.EloquentBuilder
+			relationTemp.FieldName = relationName
+			relation = relationTemp
+			builder = relationTemp.EloquentBuilder
 		case *HasManyThrough:
-			//relationTemp.FieldName = relationName
-			//relation = relationTemp
-			//builder = relationTemp.EloquentBuilder
+			relationTemp.FieldName = relationName
+			relation = relationTemp
+			builder = relationTemp.EloquentBuilder
 		case *MorphByManyRelation:
 			relationTemp.FieldName = relationName
 			relation = relationTemp
@@ -354,7 +401,7 @@ func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, re
 			relation = relationTemp
 			builder = relationTemp.EloquentBuilder
 		default:
-			panic(fmt.Sprintf(" relation : %s for model: %s didn't return a relationbuilder ", relationName, model.Name))
+			return fmt.Errorf("relation : %s for model: %s didn't return a relationbuilder", relationName, model.Name)
 		}
 		//load nested relations
 		wanted := relationName + "."
@@ -367,6 +414,14 @@ func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, re
 		if len(nestedRelation) > 0 {
 			builder.With(nestedRelation)
 		}
+		builder.WithContext(b.Context)
+		if b.Tx != nil {
+			builder.Tx = b.Tx
+		}
+		//the relation query couldn't be built,like a related model that can't be parsed
+		if builder.Err != nil {
+			return builder.Err
+		}
 		//make sure this line runs first so we clear previous wheres and then add dynamic constraints
 		relation.AddEagerConstraints(models)
 		builder.LoadPivotColumns(relation)
@@ -374,11 +429,14 @@ func (b *EloquentBuilder) EagerLoadRelation(models interface{}, model *Model, re
 		//dynamic constraints
 		builder = constraints(builder)
 
-		relationResults := builder.GetEager(relation)
+		relationResults, err := builder.GetEager(relation)
+		if err != nil {
+			return err
+		}
 		builder.Match(models, relationResults, relation, relationName)
-	} else {
-		panic(fmt.Sprintf(" relation : %s for model: %s didn't return a relationbuilder ", relationName, model.Name))
+		return nil
 	}
+	return fmt.Errorf("Relation method [%s] not found in model:[%s]", relationName, model.Name)
 }
 
 func (b *EloquentBuilder) Model(model interface{}) *EloquentBuilder {
@@ -389,23 +447,29 @@ func (b *EloquentBuilder) Model(model interface{}) *EloquentBuilder {
 SetModel set the model for the eloquent builder
 
 	Model should be a struct or a pointer to a struct with an embedded *eloquent.EloquentModel/*go-eloquent.EloquentModel interface
-
+	errors met while parsing the model or making its connection are returned by the query methods
 
 	e.g. SetModel(&User{})
 */
 func (b *EloquentBuilder) SetModel(model interface{}) *EloquentBuilder {
 	if model != nil {
-		if m, ok := model.(*Model); ok {
-			b.BaseModel = m
-		} else {
-			b.BaseModel = GetParsedModel(model)
+		parsed, err := ParseModel(model)
+		if err != nil {
+			b.AddError(err)
+			return b
 		}
+		b.BaseModel = parsed
 		if b.BaseModel.TableResolver == nil {
 			b.From(b.BaseModel.Table)
 		}
 	}
-	if b.Connection == nil && b.BaseModel.ConnectionResolver == nil {
-		b.Connection = DB.Connection(b.BaseModel.ConnectionName)
+	if b.BaseModel != nil && b.Connection == nil && b.BaseModel.ConnectionResolver == nil {
+		c, err := DB.Connection(b.BaseModel.ConnectionName)
+		if err != nil {
+			b.AddError(err)
+			return b
+		}
+		b.SetConnection(c)
 	}
 	return b
 
@@ -454,9 +518,9 @@ func (b *EloquentBuilder) WithPivot(columns ...string) *EloquentBuilder {
 
 func (b *EloquentBuilder) ApplyGlobalScopes() {
 	if b.BaseModel != nil && len(b.BaseModel.GlobalScopes) > 0 {
-		for name, scopeFunc := range b.BaseModel.GlobalScopes {
+		for _, name := range b.BaseModel.sortedGlobalScopes() {
 			if _, removed := b.RemovedScopes[name]; !removed {
-				b.callScope(scopeFunc)
+				b.callScope(b.BaseModel.GlobalScopes[name])
 			}
 		}
 	}
@@ -510,6 +574,19 @@ func (b *EloquentBuilder) UpdateOrCreate(target interface{}, conditions, values
 	return c == 1, nil
 
 }
+
+/*
+Update Update records matching the query,queries of models with a TENANT column are scoped to the tenant in the context
+
+ 1. DB.Model(&User{}).WithContext(WithTenant(ctx, 3)).Where("age", "<", 18).Update(map[string]interface{}{"status": 0})
+    update `users` set `status` = ? where `age` < ? and `users`.`tenant_id` = ?
+*/
+func (b *EloquentBuilder) Update(v map[string]interface{}) (Result, error) {
+	if err := b.applyTenantScope(); err != nil {
+		return Result{Error: err}, err
+	}
+	return b.Builder.Update(v)
+}
 func (b *EloquentBuilder) LoadPivotColumns(relation RelationI) {
 	switch relation.(type) {
 	case *BelongsToManyRelation:
@@ -526,40 +603,48 @@ func (b *EloquentBuilder) LoadPivotWheres(relation RelationI) {
 		WherePivots(r.EloquentBuilder, b.PivotWheres)
 	}
 }
-func (b *EloquentBuilder) GetEager(relationT RelationI) reflect.Value {
+
+/*
+GetEager get the results of an eager loaded relation,errors of the relation queries are returned
+*/
+func (b *EloquentBuilder) GetEager(relationT RelationI) (reflect.Value, error) {
 	switch relation := relationT.(type) {
 	case *BelongsToRelation,
 		*BelongsToManyRelation,
 		*HasOneRelation,
 		*HasManyRelation,
+		*HasOneThrough,
+		*HasManyThrough,
 		*MorphManyRelation,
 		*MorphOneRelation,
 		*MorphToManyRelation,
 		*MorphByManyRelation:
 		relationResults := reflect.MakeSlice(reflect.SliceOf(b.BaseModel.ModelType), 0, 10)
-		_, err := b.Get(&relationResults)
-		if err != nil {
-			panic(err.Error() + "\n " + b.PreparedSql)
+		if _, err := b.Get(&relationResults); err != nil {
+			return reflect.Value{}, err
 		}
-		return relationResults
+		return relationResults, nil
 	case *MorphToRelation:
 		morphto := relation
 		relationResults := map[string]reflect.Value{}
 		for key, keys := range morphto.Groups {
-			modelPointer := GetMorphDBMap(key)
+			modelPointer, err := GetMorphDBMap(key)
+			if err != nil {
+				return reflect.Value{}, err
+			}
 			models := reflect.MakeSlice(reflect.SliceOf(modelPointer.Type()), 0, 10)
 			nb := DB.Model(modelPointer.Type())
-			nb.Connection = b.Connection
+			nb.SetConnection(b.Connection)
 			nb.Tx = b.Tx
-			_, err := nb.WhereIn(morphto.RelatedModelIdColumn, keys).Get(&models)
-			if err != nil {
-				panic(err.Error())
+			nb.WithContext(b.Context)
+			if _, err = nb.WhereIn(morphto.RelatedModelIdColumn, keys).Get(&models); err != nil {
+				return reflect.Value{}, err
 			}
 			relationResults[key] = models
 		}
-		return reflect.ValueOf(relationResults)
+		return reflect.ValueOf(relationResults), nil
 	default:
-		panic(fmt.Sprintf("relation type %T not supported", relationT))
+		return reflect.Value{}, fmt.Errorf("relation type %T not supported", relationT)
 	}
 }
 func (b *EloquentBuilder) Match(models interface{}, relationResults reflect.Value, relationI RelationI, relationName string) {
@@ -582,8 +667,14 @@ func (b *EloquentBuilder) Match(models interface{}, relationResults reflect.Valu
 		relation := relationI.(*BelongsToManyRelation)
 		relation.RelationTypeName = Relations(relationName)
 		MatchBelongsToMany(models, relationResults, relation)
-	//case *HasManyThrough:
-	//case *HasOneThrough:
+	case *HasManyThrough:
+		relation := relationI.(*HasManyThrough)
+		relation.RelationTypeName = Relations(relationName)
+		MatchHasManyThrough(models, relationResults, relation)
+	case *HasOneThrough:
+		relation := relationI.(*HasOneThrough)
+		relation.RelationTypeName = Relations(relationName)
+		MatchHasOneThrough(models, relationResults, relation)
 	case *MorphOneRelation:
 		relation := relationI.(*MorphOneRelation)
 		relation.RelationTypeName = Relations(relationName)
@@ -1075,6 +1166,16 @@ func (b *EloquentBuilder) Offset(n int) *EloquentBuilder {
 	return b
 }
 
+func (b *EloquentBuilder) Union(query interface{}, all ...bool) *EloquentBuilder {
+	b.Builder.Union(query, all...)
+	return b
+}
+
+func (b *EloquentBuilder) UnionAll(query interface{}) *EloquentBuilder {
+	b.Builder.UnionAll(query)
+	return b
+}
+
 func (b *EloquentBuilder) Lock(lock ...interface{}) *EloquentBuilder {
 	b.Builder.Lock(lock...)
 	return b
@@ -1118,6 +1219,11 @@ func (b *EloquentBuilder) Pretend() *EloquentBuilder {
 	return b
 }
 
+func (b *EloquentBuilder) UseWritePdo() *EloquentBuilder {
+	b.Builder.UseWritePdo()
+	return b
+}
+
 func (b *EloquentBuilder) Tap(callback func(builder *EloquentBuilder) *EloquentBuilder) *EloquentBuilder {
 
 	return callback(b)
@@ -1144,7 +1250,7 @@ func (b *EloquentBuilder) Paginate(items interface{}, perPage, currentPage int64
 	b.ApplyGlobalScopes()
 	b.Prepare(items)
 	if len(b.Groups) > 0 || len(b.Havings) > 0 {
-		panic("having/group pagination not supported")
+		return nil, errors.New("having/group pagination not supported")
 	}
 	p := &Paginator{
 		Items:       items,
@@ -1152,13 +1258,7 @@ func (b *EloquentBuilder) Paginate(items interface{}, perPage, currentPage int64
 		PerPage:     perPage,
 		CurrentPage: currentPage,
 	}
-	cb := CloneWithout(b.Builder, TYPE_COLUMN, TYPE_ORDER, TYPE_OFFSET, TYPE_LIMIT)
-	for k, v := range b.GetRawBindings() {
-		cb.Bindings[k] = v
-	}
-	delete(cb.Bindings, TYPE_SELECT)
-	delete(cb.Bindings, TYPE_ORDER)
-	_, err := cb.Count(&p.Total)
+	_, err := b.cloneForPaginationCount().Count(&p.Total)
 	if err != nil {
 		return nil, err
 	}
@@ -1168,6 +1268,43 @@ func (b *EloquentBuilder) Paginate(items interface{}, perPage, currentPage int64
 	}
 	return p, nil
 }
+
+/*
+Upsert Insert new records or update the existing ones.
+values can be a pointer to a model,a pointer to a slice of models or maps for the builder's model,
+CreatedAt/UpdatedAt columns are filled when empty and UpdatedAt is always updated.
+A nil updateColumns updates every inserted column except the unique and CreatedAt columns
+
+	DB.Model(&User{}).Upsert(&users, []string{"email"}, []string{"name"})
+
+	insert into `users` (`email`, `name`, `created_at`, `updated_at`) values (?, ?, ?, ?) on duplicate key update `name` = values(`name`), `updated_at` = values(`updated_at`)
+*/
+func (b *EloquentBuilder) Upsert(values interface{}, uniqueColumns []string, updateColumns interface{}) (result Result, err error) {
+	var items []map[string]interface{}
+	switch v := values.(type) {
+	case map[string]interface{}:
+		items = []map[string]interface{}{v}
+	case []map[string]interface{}:
+		items = v
+	default:
+		b.Prepare(values)
+		rv := reflect.Indirect(reflect.ValueOf(values))
+		if rv.Kind() == reflect.Slice {
+			for i := 0; i < rv.Len(); i++ {
+				items = append(items, b.BaseModel.upsertAttributes(reflect.Indirect(rv.Index(i))))
+			}
+		} else {
+			items = append(items, b.BaseModel.upsertAttributes(rv))
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+	if b.BaseModel != nil {
+		updateColumns = b.BaseModel.addTimestampsToUpsert(items, uniqueColumns, updateColumns)
+	}
+	return b.Builder.Upsert(items, uniqueColumns, updateColumns)
+}
 func (b *EloquentBuilder) ForPage(page, perPage int64) *EloquentBuilder {
 
 	b.Offset(int((page - 1) * perPage)).Limit(int(perPage))
@@ -1176,7 +1313,7 @@ func (b *EloquentBuilder) ForPage(page, perPage int64) *EloquentBuilder {
 func (b *EloquentBuilder) Chunk(dest interface{}, chunkSize int64, callback func(dest interface{}) error) (err error) {
 
 	if len(b.Orders) == 0 {
-		panic(errors.New("must specify an orderby clause when using Chunk method"))
+		return errors.New("must specify an orderby clause when using Chunk method")
 	}
 	var page int64 = 1
 	var count int64 = 0
@@ -1191,6 +1328,10 @@ func (b *EloquentBuilder) Chunk(dest interface{}, chunkSize int64, callback func
 		if err != nil {
 			return
 		}
+		//stop between chunks once the context is cancelled
+		if err = b.Context.Err(); err != nil {
+			return
+		}
 		if count != chunkSize {
 			break
 		} else {
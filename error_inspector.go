@@ -0,0 +1,216 @@
+package goeloquent
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"io"
+	"modernc.org/sqlite"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+/*
+ErrorInspector classify driver errors,errors wrapped with %w are inspected too,
+messages are checked as well because some errors only keep the driver's message
+*/
+type ErrorInspector interface {
+	IsDeadlock(err error) bool        //deadlocks and serialization failures,the transaction can be retried
+	IsLockWaitTimeout(err error) bool //gave up waiting for a lock,the transaction can be retried
+	IsUniqueViolation(err error) bool
+	IsForeignKeyViolation(err error) bool
+	IsConnectionLost(err error) bool
+}
+
+var RegisteredErrorInspectors sync.Map //driver:ErrorInspector
+
+func init() {
+	RegisterErrorInspector(DriverMysql, MysqlErrorInspector{})
+	RegisterErrorInspector(DriverPostgres, PostgresErrorInspector{})
+	RegisterErrorInspector(DriverSqlite, SqliteErrorInspector{})
+}
+
+/*
+RegisterErrorInspector register an error inspector for a driver,the built-in ones can be replaced
+*/
+func RegisterErrorInspector(driver Driver, inspector ErrorInspector) {
+	RegisteredErrorInspectors.Store(driver, inspector)
+}
+
+/*
+GetErrorInspector get the error inspector of a driver,nil if there isn't one
+*/
+func GetErrorInspector(driver Driver) ErrorInspector {
+	if inspector, ok := RegisteredErrorInspectors.Load(driver); ok {
+		return inspector.(ErrorInspector)
+	}
+	return nil
+}
+
+/*
+CausedByConcurrencyError determine if the error is a deadlock or a lock wait timeout of the connection's driver
+*/
+func (c *Connection) CausedByConcurrencyError(err error) bool {
+	if err == nil || c.Config == nil {
+		return false
+	}
+	inspector := GetErrorInspector(c.Config.Driver)
+	return inspector != nil && (inspector.IsDeadlock(err) || inspector.IsLockWaitTimeout(err))
+}
+
+/*
+connectionLost errors every driver may return when the connection is broken
+*/
+func connectionLost(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNREFUSED) ||
+		messageContains(err, "bad connection", "broken pipe", "connection reset by peer", "connection refused")
+}
+
+func messageContains(err error, messages ...string) bool {
+	msg := err.Error()
+	for _, m := range messages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+MysqlErrorInspector 1213 deadlock,1205 lock wait timeout
+*/
+type MysqlErrorInspector struct{}
+
+func (MysqlErrorInspector) IsDeadlock(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == 1213
+	}
+	return messageContains(err, "Error 1213", "Deadlock found when trying to get lock")
+}
+func (MysqlErrorInspector) IsLockWaitTimeout(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == 1205
+	}
+	return messageContains(err, "Error 1205", "Lock wait timeout exceeded")
+}
+
+func (MysqlErrorInspector) IsUniqueViolation(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == 1062
+	}
+	return messageContains(err, "Error 1062", "Duplicate entry")
+}
+func (MysqlErrorInspector) IsForeignKeyViolation(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == 1451 || e.Number == 1452 || e.Number == 1216 || e.Number == 1217
+	}
+	return messageContains(err, "a foreign key constraint fails")
+}
+
+/*
+IsConnectionLost 2006 server has gone away,2013 lost connection during query
+*/
+func (MysqlErrorInspector) IsConnectionLost(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == 2006 || e.Number == 2013
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || connectionLost(err) || messageContains(err, "server has gone away", "Lost connection", "invalid connection")
+}
+
+/*
+PostgresErrorInspector 40P01 deadlock,40001 serialization failure,55P03 lock not available
+*/
+type PostgresErrorInspector struct{}
+
+func (PostgresErrorInspector) IsDeadlock(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		return e.Code == "40P01" || e.Code == "40001"
+	}
+	return messageContains(err, "deadlock detected", "could not serialize access")
+}
+func (PostgresErrorInspector) IsLockWaitTimeout(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		return e.Code == "55P03"
+	}
+	return messageContains(err, "could not obtain lock", "canceling statement due to lock timeout")
+}
+
+func (PostgresErrorInspector) IsUniqueViolation(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		return e.Code == "23505"
+	}
+	return messageContains(err, "duplicate key value violates unique constraint")
+}
+func (PostgresErrorInspector) IsForeignKeyViolation(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		return e.Code == "23503"
+	}
+	return messageContains(err, "violates foreign key constraint")
+}
+
+/*
+IsConnectionLost class 08 connection exceptions,57P01 admin shutdown
+*/
+func (PostgresErrorInspector) IsConnectionLost(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		return e.Code.Class() == "08" || e.Code == "57P01"
+	}
+	return connectionLost(err) || messageContains(err, "server closed the connection unexpectedly")
+}
+
+/*
+SqliteErrorInspector SQLITE_BUSY and SQLITE_LOCKED,sqlite has no deadlock detection
+*/
+type SqliteErrorInspector struct{}
+
+func (SqliteErrorInspector) IsDeadlock(err error) bool {
+	return false
+}
+func (SqliteErrorInspector) IsLockWaitTimeout(err error) bool {
+	var e *sqlite.Error
+	if errors.As(err, &e) {
+		//extended result codes keep the primary code in the lowest byte
+		code := e.Code() & 0xff
+		return code == 5 || code == 6
+	}
+	return messageContains(err, "database is locked", "database table is locked")
+}
+
+/*
+IsUniqueViolation SQLITE_CONSTRAINT_UNIQUE,SQLITE_CONSTRAINT_PRIMARYKEY
+*/
+func (SqliteErrorInspector) IsUniqueViolation(err error) bool {
+	//the primary result code is returned unless extended result codes are enabled
+	var e *sqlite.Error
+	if errors.As(err, &e) && (e.Code() == 2067 || e.Code() == 1555) {
+		return true
+	}
+	return messageContains(err, "UNIQUE constraint failed")
+}
+
+/*
+IsForeignKeyViolation SQLITE_CONSTRAINT_FOREIGNKEY
+*/
+func (SqliteErrorInspector) IsForeignKeyViolation(err error) bool {
+	//the primary result code is returned unless extended result codes are enabled
+	var e *sqlite.Error
+	if errors.As(err, &e) && (e.Code() == 787) {
+		return true
+	}
+	return messageContains(err, "FOREIGN KEY constraint failed")
+}
+func (SqliteErrorInspector) IsConnectionLost(err error) bool {
+	return errors.Is(err, driver.ErrBadConn)
+}
@@ -1,21 +1,27 @@
 package goeloquent
 
+import "reflect"
+
 const (
-	EventSaving      = "EloquentSaving"
-	EventSaved       = "EloquentSaved"
-	EventCreating    = "EloquentCreating"
-	EventCreated     = "EloquentCreated"
-	EventUpdating    = "EloquentUpdating"
-	EventUpdated     = "EloquentUpdated"
-	EventDeleteing   = "EloquentDeleting"
-	EventDeleted     = "EloquentDeleted"
-	EventRetrieved   = "EloquentRetrieved"
-	EventRetrieving  = "EloquentRetrieving"
-	EventALL         = "EloquentEventALL"
-	EventInitialized = "EventInitialized"
-	EventBooting     = "EloquentBooting"
-	EventBoot        = "EventBoot"
-	EventBooted      = "EloquentBooted"
+	EventSaving        = "EloquentSaving"
+	EventSaved         = "EloquentSaved"
+	EventCreating      = "EloquentCreating"
+	EventCreated       = "EloquentCreated"
+	EventUpdating      = "EloquentUpdating"
+	EventUpdated       = "EloquentUpdated"
+	EventDeleteing     = "EloquentDeleting"
+	EventDeleted       = "EloquentDeleted"
+	EventRestoring     = "EloquentRestoring"
+	EventRestored      = "EloquentRestored"
+	EventForceDeleting = "EloquentForceDeleting"
+	EventForceDeleted  = "EloquentForceDeleted"
+	EventRetrieved     = "EloquentRetrieved"
+	EventRetrieving    = "EloquentRetrieving"
+	EventALL           = "EloquentEventALL"
+	EventInitialized   = "EventInitialized"
+	EventBooting       = "EloquentBooting"
+	EventBoot          = "EventBoot"
+	EventBooted        = "EloquentBooted"
 
 	EventOpened            = "EventOpened"
 	EventConnectionCreated = "EventConnectionCreated"
@@ -28,6 +34,114 @@ const (
 	EventErrorArised          = "EventErrorArised"
 )
 
+/*
+OpenedEvent payload of EventOpened
+*/
+type OpenedEvent struct {
+	Configs map[string]DBConfig
+}
+
+/*
+ConnectionCreatedEvent payload of EventConnectionCreated
+*/
+type ConnectionCreatedEvent struct {
+	Connection *Connection
+}
+
+/*
+StatementPreparedEvent payload of EventStatementPrepared,fired before the statement is executed
+*/
+type StatementPreparedEvent struct {
+	ConnectionName string
+	Sql            string
+	Bindings       []interface{}
+	Transaction    *Transaction //nil if the statement is not in a transaction
+}
+
+/*
+TransactionBeginEvent payload of EventTransactionBegin
+*/
+type TransactionBeginEvent struct {
+	Transaction *Transaction
+}
+
+/*
+TransactionCommittedEvent payload of EventTransactionCommitted,Error is the error returned by commit
+*/
+type TransactionCommittedEvent struct {
+	Transaction *Transaction
+	Error       error
+}
+
+/*
+TransactionRollbackEvent payload of EventTransactionRollback,Error is the error that caused the rollback or returned by rollback
+*/
+type TransactionRollbackEvent struct {
+	Transaction *Transaction
+	Error       error
+}
+
+/*
+QueryErrorEvent payload of EventErrorArised,fired when preparing,executing or scanning a statement fails
+*/
+type QueryErrorEvent struct {
+	ConnectionName string
+	Sql            string
+	Bindings       []interface{}
+	Error          error
+}
+
+/*
+ModelEvent payload of EventALL,listeners of EventALL receive every model event
+*/
+type ModelEvent struct {
+	Name  string
+	Model interface{}
+}
+
+/*
+EventPayloads the payload type of each event,listeners must accept it,model events pass the model pointer as interface{}
+*/
+var EventPayloads = map[string]reflect.Type{
+	EventOpened:               reflect.TypeOf(OpenedEvent{}),
+	EventConnectionCreated:    reflect.TypeOf(ConnectionCreatedEvent{}),
+	EventStatementPrepared:    reflect.TypeOf(StatementPreparedEvent{}),
+	EventExecuted:             reflect.TypeOf(Result{}),
+	EventTransactionBegin:     reflect.TypeOf(TransactionBeginEvent{}),
+	EventTransactionCommitted: reflect.TypeOf(TransactionCommittedEvent{}),
+	EventTransactionRollback:  reflect.TypeOf(TransactionRollbackEvent{}),
+	EventErrorArised:          reflect.TypeOf(QueryErrorEvent{}),
+	EventALL:                  reflect.TypeOf(ModelEvent{}),
+	EventSaving:               modelPayload,
+	EventSaved:                modelPayload,
+	EventCreating:             modelPayload,
+	EventCreated:              modelPayload,
+	EventUpdating:             modelPayload,
+	EventUpdated:              modelPayload,
+	EventDeleteing:            modelPayload,
+	EventDeleted:              modelPayload,
+	EventRestoring:            modelPayload,
+	EventRestored:             modelPayload,
+	EventForceDeleting:        modelPayload,
+	EventForceDeleted:         modelPayload,
+	EventRetrieving:           modelPayload,
+	EventRetrieved:            modelPayload,
+	EventInitialized:          modelPayload,
+	EventBooting:              modelPayload,
+	EventBoot:                 modelPayload,
+	EventBooted:               modelPayload,
+}
+
+var modelPayload = reflect.TypeOf((*interface{})(nil)).Elem()
+
+/*
+AfterCommitEvents models implement this to defer their created/updated/saved/deleted/restored/force deleted events
+until the outermost transaction commits,the events are dropped if it rolls back and their errors are ignored
+*/
+type AfterCommitEvents interface {
+	EloquentEventsAfterCommit() bool
+}
+
 type ISaving interface {
 	EloquentSaving() error
 }
@@ -52,6 +166,18 @@ type IDeleting interface {
 type IDeleted interface {
 	EloquentDeleted() error
 }
+type IRestoring interface {
+	EloquentRestoring() error
+}
+type IRestored interface {
+	EloquentRestored() error
+}
+type IForceDeleting interface {
+	EloquentForceDeleting() error
+}
+type IForceDeleted interface {
+	EloquentForceDeleted() error
+}
 type IRetrieving interface {
 	EloquentRetrieving() error
 }
@@ -1 +1,135 @@
 package goeloquent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+Grammar compiles a Builder into the sql dialect of a database driver.
+
+Dialects embed MysqlGrammar and override the parts that differ, MysqlGrammar
+dispatches overridable calls through the builder's grammar so the overrides take effect.
+*/
+type Grammar interface {
+	SetTablePrefix(prefix string)
+	GetTablePrefix() string
+	SetBuilder(builder *Builder)
+	GetBuilder() *Builder
+	GetDriver() Driver
+
+	CompileSelect() string
+	CompileExists() string
+	CompileInsert(values []map[string]interface{}) string
+	CompileInsertOrIgnore(values []map[string]interface{}) string
+	CompileInsertGetId(values []map[string]interface{}, sequence string) string
+	CompileUpdate(value map[string]interface{}) string
+	CompileDelete() string
+	CompileUpsert(values []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) string
+	CompileComponentJoins() string
+	CompileComponentWheres() string
+	CompileWhere(w Where) string
+	CompileLock() string
+	CompileRandom(seed ...int) string
+
+	Wrap(value interface{}, prefixAlias ...bool) string
+	WrapTable(tableName interface{}) string
+	WrapValue(value string) string
+	WrapUnion(sql string) string
+}
+
+/*
+NewGrammar create a grammar for the given driver,mysql grammar is used when driver is empty
+*/
+func NewGrammar(driver Driver) Grammar {
+	switch driver {
+	case DriverMysql, "":
+		return &MysqlGrammar{}
+	case DriverPostgres:
+		return &PostgresGrammar{}
+	case DriverSqlite:
+		return &SqliteGrammar{}
+	default:
+		panic(fmt.Sprintf("unsupported driver:%s", driver))
+	}
+}
+
+/*
+splitJsonPath split a json selector column into the column and the path segments
+
+meta->address->city => meta, [address city]
+*/
+func splitJsonPath(column string) (string, []string) {
+	parts := strings.Split(strings.ReplaceAll(column, "->>", "->"), "->")
+	var path []string
+	for _, segment := range parts[1:] {
+		path = append(path, strings.Trim(segment, `'"`))
+	}
+	return parts[0], path
+}
+
+/*
+upsertUpdateColumns the columns an upsert updates when no update columns are given,
+every inserted column except the unique ones,sorted so the sql doesn't change between runs
+*/
+func upsertUpdateColumns(values []map[string]interface{}, uniqueColumns []string) []string {
+	unique := make(map[string]struct{}, len(uniqueColumns))
+	for _, column := range uniqueColumns {
+		unique[column] = struct{}{}
+	}
+	columns := make([]string, 0, len(values[0]))
+	for column := range values[0] {
+		if _, ok := unique[column]; !ok {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+/*
+sortedColumns the keys of update values sorted so the sql and its bindings don't change between runs
+*/
+func sortedColumns(values map[string]interface{}) []string {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+/*
+compileOnConflictUpsert compile an "insert ... on conflict do update" statement shared by postgres and sqlite.
+*/
+func compileOnConflictUpsert(m *MysqlGrammar, values []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) string {
+	b := m.GetBuilder()
+	g := m.grammar()
+	sql := m.CompileInsert(values)
+	var unique []interface{}
+	for _, column := range uniqueColumns {
+		unique = append(unique, column)
+	}
+	sql = fmt.Sprintf("%s on conflict (%s) do update set ", sql, m.columnize(unique))
+
+	var columns []string
+	switch t := updateColumns.(type) {
+	case nil:
+		for _, column := range upsertUpdateColumns(values, uniqueColumns) {
+			columns = append(columns, fmt.Sprintf("%s = %s.%s", g.Wrap(column), g.WrapValue("excluded"), g.Wrap(column)))
+		}
+	case []string:
+		for _, column := range t {
+			columns = append(columns, fmt.Sprintf("%s = %s.%s", g.Wrap(column), g.WrapValue("excluded"), g.Wrap(column)))
+		}
+	case map[string]interface{}:
+		for _, column := range sortedColumns(t) {
+			columns = append(columns, fmt.Sprintf("%s = %s", g.Wrap(column), m.parameter(t[column])))
+			b.AddBinding([]interface{}{t[column]}, TYPE_INSERT)
+		}
+	default:
+		b.AddError(fmt.Errorf("upsert update columns must be nil,[]string or map[string]interface{},got %T", t))
+	}
+	return sql + strings.Join(columns, ", ")
+}
@@ -14,7 +14,13 @@ var DB *DatabaseManager
 
 type MacroFunc = func(builder *Builder, params ...interface{}) *Builder
 
-func Open(config map[string]DBConfig) *DatabaseManager {
+/*
+Open make a database manager and connect to the default connection,DB is set once it succeeds,
+an error is returned if the default connection isn't configured or the database can't be reached so the caller can retry
+
+ 1. db, err := Open(map[string]DBConfig{"default": config})
+*/
+func Open(config map[string]DBConfig) (*DatabaseManager, error) {
 	var configP = make(map[string]*DBConfig)
 	for name := range config {
 		c := config[name]
@@ -23,18 +29,31 @@ func Open(config map[string]DBConfig) *DatabaseManager {
 	db := DatabaseManager{
 		Configs:     configP,
 		Connections: make(map[string]*Connection),
-		Listeners:   make(map[string][]interface{}),
+		Events:      NewDispatcher(),
+	}
+	if _, err := db.Connection(DefaultConnectionName); err != nil {
+		return nil, err
 	}
-	db.Connection("default")
 	DB = &db
-	db.FireEvent(EventOpened, config)
-	return DB
+	db.FireEvent(EventOpened, OpenedEvent{Configs: config})
+	return DB, nil
+}
+
+/*
+MustOpen same as Open but panic if the default connection can't be made
+*/
+func MustOpen(config map[string]DBConfig) *DatabaseManager {
+	db, err := Open(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return db
 }
-func (dm DatabaseManager) AddConfig(name string, config *DBConfig) DatabaseManager {
+func (dm *DatabaseManager) AddConfig(name string, config *DBConfig) *DatabaseManager {
 	DB.Configs[name] = config
 	return dm
 }
-func (dm DatabaseManager) GetConfigs() map[string]*DBConfig {
+func (dm *DatabaseManager) GetConfigs() map[string]*DBConfig {
 	return DB.Configs
 }
 func RegistMorphMap(morphMap map[string]interface{}) {
@@ -47,12 +66,26 @@ func RegistMorphMap(morphMap map[string]interface{}) {
 	}
 }
 
-func GetMorphDBMap(name string) reflect.Value {
+/*
+GetMorphDBMap get the model registered by RegistMorphMap for a morph type,an error is returned if the type isn't registered
+*/
+func GetMorphDBMap(name string) (reflect.Value, error) {
 	v, ok := RegisteredDBMap.Load(name)
 	if !ok {
-		panic(fmt.Sprintf("no registered model found for %s", name))
+		return reflect.Value{}, fmt.Errorf("no registered model found for morph type %s", name)
+	}
+	return v.(reflect.Value), nil
+}
+
+/*
+MustGetMorphDBMap same as GetMorphDBMap but panic if the type isn't registered
+*/
+func MustGetMorphDBMap(name string) reflect.Value {
+	v, err := GetMorphDBMap(name)
+	if err != nil {
+		panic(err.Error())
 	}
-	return v.(reflect.Value)
+	return v
 }
 func RegisterModels(models []interface{}) {
 	for _, m := range models {
@@ -63,13 +96,13 @@ func RegisterModels(models []interface{}) {
 	}
 }
 
-func (DatabaseManager) Raw(connectionName ...string) *sql.DB {
+func (*DatabaseManager) Raw(connectionName ...string) *sql.DB {
 
 	if len(connectionName) > 0 {
-		c := DB.Connection(connectionName[0])
+		c := DB.MustConnection(connectionName[0])
 		return (*c).GetDB()
 	} else {
-		c := DB.Connection("default")
+		c := DB.MustConnection("default")
 		return (*c).GetDB()
 	}
 }
@@ -0,0 +1,363 @@
+package goeloquent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+/*
+SyncResult the related ids changed by Sync/SyncWithoutDetaching/Toggle
+*/
+type SyncResult struct {
+	Attached []interface{}
+	Detached []interface{}
+	Updated  []interface{}
+}
+
+/*
+pivotTable describes the pivot table of a many-to-many relation so BelongsToMany/MorphToMany/MorphByMany can share the writes
+*/
+type pivotTable struct {
+	relation        *Relation
+	table           string // pivot table,role_users
+	foreignPivotKey string // pivot column references the self model,role_users.role_id
+	relatedPivotKey string // pivot column references the related model,role_users.user_id
+	parentKey       string // self model column,roles.id
+	morphType       string // pivot column holds the morph type for polymorphic relations,tagables.tagable_type
+	morphClass      string // morph type value
+	withTimestamps  bool   // set created_at/updated_at on the pivot table
+	createdAt       string // pivot created_at column,created_at if empty
+	updatedAt       string // pivot updated_at column,updated_at if empty
+
+	tx *Transaction // transaction started by Sync/Toggle
+}
+
+/*
+newPivotStatement get a builder for the pivot table,it runs inside the self model's transaction if there is one
+*/
+func (p *pivotTable) newPivotStatement() *Builder {
+	if p.tx != nil {
+		return p.tx.Table(p.table)
+	}
+	if tx := p.relation.selfTx(); tx != nil {
+		return tx.Table(p.table)
+	}
+	parsed, err := ParseModel(p.relation.RelatedModel)
+	if err != nil {
+		return NewQueryBuilder().AddError(err)
+	}
+	c, err := DB.Connection(parsed.ConnectionName)
+	if err != nil {
+		return NewQueryBuilder().AddError(err)
+	}
+	builder := c.Table(p.table)
+	if ctx := p.context(); ctx != nil {
+		builder.WithContext(ctx)
+	}
+	return builder
+}
+
+func (p *pivotTable) context() context.Context {
+	if eloquentModel := p.relation.selfEloquentModel(); eloquentModel != nil {
+		return eloquentModel.Context
+	}
+	return nil
+}
+
+/*
+inTransaction run fn in a new transaction if the self model isn't in one,so statements of Sync/Toggle are committed or rolled back together
+*/
+func (p *pivotTable) inTransaction(fn func() error) error {
+	if p.tx != nil || p.relation.selfTx() != nil {
+		return fn()
+	}
+	ctx := p.context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parsed, err := ParseModel(p.relation.RelatedModel)
+	if err != nil {
+		return err
+	}
+	c, err := DB.Connection(parsed.ConnectionName)
+	if err != nil {
+		return err
+	}
+	tx, err := c.BeginTransactionContext(ctx, nil)
+	if err != nil {
+		return err
+	}
+	p.tx = tx
+	defer func() {
+		p.tx = nil
+	}()
+	if err = fn(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+/*
+newPivotQuery get a builder for the pivot records of the self model
+*/
+func (p *pivotTable) newPivotQuery() *Builder {
+	builder := p.newPivotStatement()
+	builder.Where(p.foreignPivotKey, p.relation.GetSelfKey(p.parentKey))
+	if p.morphType != "" {
+		builder.Where(p.morphType, p.morphClass)
+	}
+	return builder
+}
+
+/*
+pivotRecord build a pivot record for the related id
+*/
+func (p *pivotTable) pivotRecord(id interface{}, attributes ...map[string]interface{}) map[string]interface{} {
+	record := map[string]interface{}{
+		p.foreignPivotKey: p.relation.GetSelfKey(p.parentKey),
+		p.relatedPivotKey: id,
+	}
+	if p.morphType != "" {
+		record[p.morphType] = p.morphClass
+	}
+	if p.withTimestamps {
+		now := time.Now()
+		record[p.createdAtColumn()] = now
+		record[p.updatedAtColumn()] = now
+	}
+	for _, attrs := range attributes {
+		for column, value := range attrs {
+			record[column] = value
+		}
+	}
+	return record
+}
+
+func (p *pivotTable) createdAtColumn() string {
+	if p.createdAt != "" {
+		return p.createdAt
+	}
+	return "created_at"
+}
+func (p *pivotTable) updatedAtColumn() string {
+	if p.updatedAt != "" {
+		return p.updatedAt
+	}
+	return "updated_at"
+}
+
+/*
+parsePivotIds normalize ids to a slice of ids and their own pivot attributes
+
+ 1. 1
+ 2. []int{1,2}
+ 3. map[int]map[string]interface{}{1: {"status": 1}, 2: {"status": 0}}
+*/
+func parsePivotIds(ids interface{}) (keys []interface{}, attributes map[string]map[string]interface{}) {
+	attributes = make(map[string]map[string]interface{})
+	if ids == nil {
+		return
+	}
+	v := reflect.ValueOf(ids)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			keys = append(keys, v.Index(i).Interface())
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.Interface())
+			if attrs, ok := v.MapIndex(key).Interface().(map[string]interface{}); ok {
+				attributes[fmt.Sprint(key.Interface())] = attrs
+			}
+		}
+		//map keys are unordered,sort them so the statements are stable
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+	default:
+		keys = append(keys, ids)
+	}
+	return
+}
+
+/*
+currentIds get the related ids currently attached to the self model
+*/
+func (p *pivotTable) currentIds() (ids []interface{}, err error) {
+	_, err = p.newPivotQuery().Pluck(&ids, p.relatedPivotKey)
+	for i, id := range ids {
+		if b, ok := id.([]byte); ok {
+			ids[i] = string(b)
+		}
+	}
+	return
+}
+
+func (p *pivotTable) attach(keys []interface{}, attributes map[string]map[string]interface{}, extra ...map[string]interface{}) (result Result, err error) {
+	if len(keys) == 0 {
+		return
+	}
+	//records with their own attributes may have different columns,insert them one by one
+	if len(attributes) > 0 {
+		for _, key := range keys {
+			result, err = p.newPivotStatement().Insert(p.pivotRecord(key, append(extra, attributes[fmt.Sprint(key)])...))
+			if err != nil {
+				return
+			}
+		}
+		return
+	}
+	records := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, p.pivotRecord(key, extra...))
+	}
+	return p.newPivotStatement().Insert(records)
+}
+
+func (p *pivotTable) Attach(ids interface{}, attributes ...map[string]interface{}) (Result, error) {
+	keys, attrs := parsePivotIds(ids)
+	return p.attach(keys, attrs, attributes...)
+}
+
+func (p *pivotTable) Detach(ids ...interface{}) (Result, error) {
+	builder := p.newPivotQuery()
+	if len(ids) > 0 {
+		var keys []interface{}
+		if len(ids) == 1 {
+			keys, _ = parsePivotIds(ids[0])
+		} else {
+			keys = ids
+		}
+		if len(keys) == 0 {
+			return Result{}, nil
+		}
+		builder.WhereIn(p.relatedPivotKey, keys)
+	}
+	return builder.Delete()
+}
+
+func (p *pivotTable) UpdateExistingPivot(id interface{}, attributes map[string]interface{}) (Result, error) {
+	values := make(map[string]interface{}, len(attributes)+1)
+	for column, value := range attributes {
+		values[column] = value
+	}
+	if p.withTimestamps {
+		values[p.updatedAtColumn()] = time.Now()
+	}
+	return p.newPivotQuery().Where(p.relatedPivotKey, id).Update(values)
+}
+
+func (p *pivotTable) Sync(ids interface{}, detaching bool) (changes SyncResult, err error) {
+	err = p.inTransaction(func() error {
+		changes, err = p.sync(ids, detaching)
+		return err
+	})
+	return
+}
+
+func (p *pivotTable) sync(ids interface{}, detaching bool) (changes SyncResult, err error) {
+	current, err := p.currentIds()
+	if err != nil {
+		return
+	}
+	keys, attributes := parsePivotIds(ids)
+	existed := make(map[string]bool, len(current))
+	for _, id := range current {
+		existed[fmt.Sprint(id)] = true
+	}
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[fmt.Sprint(key)] = true
+	}
+
+	if detaching {
+		var detach []interface{}
+		for _, id := range current {
+			if !wanted[fmt.Sprint(id)] {
+				detach = append(detach, id)
+			}
+		}
+		if len(detach) > 0 {
+			if _, err = p.Detach(detach); err != nil {
+				return
+			}
+			changes.Detached = detach
+		}
+	}
+
+	var attach []interface{}
+	attachAttributes := make(map[string]map[string]interface{})
+	for _, key := range keys {
+		k := fmt.Sprint(key)
+		if !existed[k] {
+			attach = append(attach, key)
+			if attrs, ok := attributes[k]; ok {
+				attachAttributes[k] = attrs
+			}
+			continue
+		}
+		if attrs, ok := attributes[k]; ok && len(attrs) > 0 {
+			var res Result
+			if res, err = p.UpdateExistingPivot(key, attrs); err != nil {
+				return
+			}
+			if affected, e := res.RowsAffected(); e == nil && affected > 0 {
+				changes.Updated = append(changes.Updated, key)
+			}
+		}
+	}
+	if _, err = p.attach(attach, attachAttributes); err != nil {
+		return
+	}
+	changes.Attached = attach
+	return
+}
+
+func (p *pivotTable) Toggle(ids interface{}) (changes SyncResult, err error) {
+	err = p.inTransaction(func() error {
+		changes, err = p.toggle(ids)
+		return err
+	})
+	return
+}
+
+func (p *pivotTable) toggle(ids interface{}) (changes SyncResult, err error) {
+	current, err := p.currentIds()
+	if err != nil {
+		return
+	}
+	existed := make(map[string]bool, len(current))
+	for _, id := range current {
+		existed[fmt.Sprint(id)] = true
+	}
+	keys, attributes := parsePivotIds(ids)
+	var attach, detach []interface{}
+	attachAttributes := make(map[string]map[string]interface{})
+	for _, key := range keys {
+		k := fmt.Sprint(key)
+		if existed[k] {
+			detach = append(detach, key)
+		} else {
+			attach = append(attach, key)
+			if attrs, ok := attributes[k]; ok {
+				attachAttributes[k] = attrs
+			}
+		}
+	}
+	if len(detach) > 0 {
+		if _, err = p.Detach(detach); err != nil {
+			return
+		}
+		changes.Detached = detach
+	}
+	if _, err = p.attach(attach, attachAttributes); err != nil {
+		return
+	}
+	changes.Attached = attach
+	return
+}
@@ -15,6 +15,9 @@ type JoinBuilder struct {
 
 func NewJoin(parent *Builder, joinType string, table interface{}) *JoinBuilder {
 	b := NewQueryBuilder(parent.Connection).From(table)
+	if b.Grammar.GetDriver() != parent.Grammar.GetDriver() {
+		b.SetGrammar(NewGrammar(parent.Grammar.GetDriver()))
+	}
 	b.IsJoin = true
 	return &JoinBuilder{
 		JoinType: joinType,
@@ -1,12 +1,233 @@
 package goeloquent
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 )
 
+/*
+RedactedBinding replaces bindings of DBConfig.RedactColumns in log records
+*/
+const RedactedBinding = "[redacted]"
+
 type Log struct {
-	SQL      string
-	Bindings []interface{}
-	Result   Result
-	Time     time.Duration
+	SQL            string
+	Bindings       []interface{}
+	Result         Result
+	Time           time.Duration
+	ConnectionName string
+	Rows           int64 //rows returned by a select or affected by a statement
+	Error          error
+}
+
+/*
+EnableQueryLog collect every statement in memory until DisableQueryLog is called,bindings are kept as they are
+
+ 1. DB.EnableQueryLog(); user.Save(); DB.GetQueryLog()[0].SQL insert into `users` ...
+*/
+func (dm *DatabaseManager) EnableQueryLog() {
+	dm.queryLogMu.Lock()
+	defer dm.queryLogMu.Unlock()
+	dm.loggingQueries = true
+}
+
+/*
+DisableQueryLog stop collecting statements,the collected ones are kept
+*/
+func (dm *DatabaseManager) DisableQueryLog() {
+	dm.queryLogMu.Lock()
+	defer dm.queryLogMu.Unlock()
+	dm.loggingQueries = false
+}
+
+/*
+GetQueryLog get the statements collected since EnableQueryLog
+*/
+func (dm *DatabaseManager) GetQueryLog() []Log {
+	dm.queryLogMu.Lock()
+	defer dm.queryLogMu.Unlock()
+	logs := make([]Log, len(dm.queryLog))
+	copy(logs, dm.queryLog)
+	return logs
+}
+
+/*
+FlushQueryLog clear the collected statements
+*/
+func (dm *DatabaseManager) FlushQueryLog() {
+	dm.queryLogMu.Lock()
+	defer dm.queryLogMu.Unlock()
+	dm.queryLog = nil
+}
+
+func (dm *DatabaseManager) appendQueryLog(log Log) {
+	dm.queryLogMu.Lock()
+	defer dm.queryLogMu.Unlock()
+	if dm.loggingQueries {
+		dm.queryLog = append(dm.queryLog, log)
+	}
+}
+
+/*
+logQuery record a statement in the query log and emit a slog record,
+every statement is logged at info level when DBConfig.EnableLog is on,failed ones at error level,
+statements slower than DBConfig.SlowQueryThreshold are logged at warn level even if EnableLog is off
+*/
+func (c *Connection) logQuery(ctx context.Context, query string, bindings []interface{}, start time.Time, result *Result, err error) {
+	if err == nil {
+		err = result.Error
+	}
+	log := Log{
+		SQL:            query,
+		Bindings:       bindings,
+		Result:         *result,
+		Time:           time.Since(start),
+		ConnectionName: c.ConnectionName,
+		Rows:           result.Count,
+		Error:          err,
+	}
+	if result.Raw != nil {
+		if affected, e := result.Raw.RowsAffected(); e == nil {
+			log.Rows = affected
+		}
+	}
+	if DB != nil {
+		DB.appendQueryLog(log)
+	}
+	if c.Config == nil {
+		return
+	}
+	level, msg := slog.LevelInfo, "query"
+	slow := c.Config.SlowQueryThreshold > 0 && log.Time >= c.Config.SlowQueryThreshold
+	switch {
+	case err != nil:
+		level, msg = slog.LevelError, "query failed"
+	case slow:
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !c.Config.EnableLog && !slow {
+		return
+	}
+	logger := c.Config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := []slog.Attr{
+		slog.String("connection", log.ConnectionName),
+		slog.String("sql", query),
+		slog.Any("bindings", RedactBindings(query, bindings, c.Config.RedactColumns)),
+		slog.Duration("duration", log.Time),
+		slog.Int64("rows", log.Rows),
+	}
+	if err != nil {
+		//the sql and unredacted bindings of a QueryException are not repeated
+		var exception *QueryException
+		if errors.As(err, &exception) {
+			err = exception.Err
+		}
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+var insertColumnsRegex = regexp.MustCompile(`(?is)^\s*insert\s+(?:ignore\s+)?into\s+\S+\s*\(([^)]*)\)\s*values`)
+
+var redactKeywords = map[string]struct{}{
+	"and": {}, "or": {}, "not": {}, "in": {}, "like": {}, "ilike": {}, "is": {}, "null": {}, "between": {},
+	"set": {}, "where": {}, "values": {}, "select": {}, "from": {}, "on": {}, "as": {}, "when": {}, "then": {},
+	"else": {}, "case": {}, "limit": {}, "offset": {}, "having": {}, "exists": {}, "any": {}, "all": {}, "escape": {},
+}
+
+/*
+RedactBindings replace the bindings of sensitive columns with RedactedBinding,
+a binding belongs to the insert column at its position or to the last column before its placeholder,
+it's best effort,bindings of raw expressions may not be matched
+
+ 1. RedactBindings("update users set password = ? where id = ?", []interface{}{"secret", 1}, []string{"password"}) []interface{}{"[redacted]", 1}
+*/
+func RedactBindings(query string, bindings []interface{}, columns []string) []interface{} {
+	if len(columns) == 0 || len(bindings) == 0 {
+		return bindings
+	}
+	sensitive := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		sensitive[strings.ToLower(column)] = struct{}{}
+	}
+	var insertColumns []string
+	valuesEnd := -1
+	if match := insertColumnsRegex.FindStringSubmatchIndex(query); match != nil {
+		for _, column := range strings.Split(query[match[2]:match[3]], ",") {
+			insertColumns = append(insertColumns, unquoteColumn(column))
+		}
+		valuesEnd = match[1]
+	}
+	redacted := make([]interface{}, len(bindings))
+	copy(redacted, bindings)
+
+	index, inserted, depth := 0, 0, 0
+	lastColumn := ""
+	inValues := valuesEnd >= 0
+	for i := 0; i < len(query) && index < len(bindings); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			//skip string literals
+			for i++; i < len(query) && query[i] != '\''; i++ {
+			}
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == '?' || (ch == '$' && i+1 < len(query) && unicode.IsDigit(rune(query[i+1]))):
+			column := lastColumn
+			if inValues && i > valuesEnd && len(insertColumns) > 0 {
+				column = insertColumns[inserted%len(insertColumns)]
+				inserted++
+			}
+			if _, ok := sensitive[column]; ok {
+				redacted[index] = RedactedBinding
+			}
+			index++
+			for i+1 < len(query) && unicode.IsDigit(rune(query[i+1])) {
+				i++
+			}
+		case isIdentifierChar(ch) || ch == '"' || ch == '`':
+			start := i
+			for i+1 < len(query) && (isIdentifierChar(query[i+1]) || query[i+1] == '"' || query[i+1] == '`' || query[i+1] == '.') {
+				i++
+			}
+			word := unquoteColumn(query[start : i+1])
+			if inValues && i > valuesEnd && depth == 0 && word != "" {
+				//on duplicate key update,on conflict,returning
+				inValues = false
+			}
+			if _, ok := redactKeywords[word]; !ok && word != "" && !unicode.IsDigit(rune(word[0])) {
+				lastColumn = word
+			}
+		}
+	}
+	return redacted
+}
+
+func isIdentifierChar(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+/*
+unquoteColumn "users"."password" => password
+*/
+func unquoteColumn(column string) string {
+	column = strings.TrimSpace(column)
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+	return strings.ToLower(strings.Trim(column, "\"`[] "))
 }
@@ -3,10 +3,10 @@ package goeloquent
 import (
 	"context"
 	"database/sql"
-	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 )
@@ -33,6 +33,9 @@ const (
 	EloquentGetGuarded                = "EloquentGetGuarded"
 	EloquentGetWithRelations          = "EloquentGetWithRelations"
 	EloquentGetWithRelationAggregates = "EloquentGetWithRelationAggregates"
+	EloquentGetHidden                 = "EloquentGetHidden"
+	EloquentGetVisible                = "EloquentGetVisible"
+	EloquentGetAppends                = "EloquentGetAppends"
 )
 
 /*
@@ -56,9 +59,9 @@ func BatchSync(models interface{}, exists ...bool) {
 		item := realModels.Type().Elem()
 		var parsed *Model
 		if item.Kind() == reflect.Ptr {
-			parsed = GetParsedModel(item.Elem())
+			parsed = MustParseModel(item.Elem())
 		} else {
-			parsed = GetParsedModel(item)
+			parsed = MustParseModel(item)
 		}
 		if parsed.IsEloquent {
 			for i := 0; i < realModels.Len(); i++ {
@@ -77,7 +80,7 @@ func BatchSync(models interface{}, exists ...bool) {
 			}
 		}
 	} else if realModels.Type().Kind() == reflect.Struct {
-		parsed := GetParsedModel(realModels.Type())
+		parsed := MustParseModel(realModels.Type())
 		if parsed.IsEloquent {
 			model := realModels
 			newModel := reflect.ValueOf(NewEloquentModel(model.Addr().Interface(), exist))
@@ -114,6 +117,7 @@ type EloquentModel struct {
 	Tx                 *Transaction           `json:"-"` //use same transaction
 	Context            context.Context        `json:"-"`
 	WasRecentlyCreated bool                   `json:"-"`
+	Err                error                  `json:"-"` //the first error met while filling the model,the next Save returns and clears it instead of saving
 	//RelationLoaded     map[string]interface{} //todo consider add a map of loaded relation Result to make debug much easier
 }
 
@@ -121,7 +125,7 @@ type EloquentModel struct {
 Init set modelPointer after initialized
 */
 func Init(modelPointer interface{}) {
-	config := GetParsedModel(modelPointer)
+	config := MustParseModel(modelPointer)
 	model := reflect.Indirect(reflect.ValueOf(modelPointer))
 	elo := model.Field(config.EloquentModelFieldIndex).Elem()
 	isBooted := elo.IsValid() && elo.Field(0).Interface().(bool)
@@ -137,7 +141,7 @@ func InitModelInTx(modelPointer interface{}, tx *Transaction, exists ...bool) *E
 
 func InitModel(modelPointer interface{}, exists ...bool) *EloquentModel {
 	m := reflect.Indirect(reflect.ValueOf(modelPointer))
-	parsed := GetParsedModel(modelPointer)
+	parsed := MustParseModel(modelPointer)
 	e := NewEloquentModel(modelPointer, exists...)
 	m.Field(parsed.EloquentModelFieldIndex).Set(reflect.ValueOf(e))
 	if len(parsed.DefaultAttributes) > 0 {
@@ -159,6 +163,8 @@ func NewEloquentModel(modelPointer interface{}, exists ...bool) *EloquentModel {
 	m.Exists = exists[0]
 	m.IsBooted = true
 	m.SyncOrigin()
+	m.BootIfNotBooted()
+	m.FireModelEvent(EventInitialized, nil)
 	return &m
 }
 
@@ -172,7 +178,10 @@ func Fill(target interface{}, values ...map[string]interface{}) error {
 		}
 		return nil
 	}
-	parsed := GetParsedModel(target)
+	parsed, err := ParseModel(target)
+	if err != nil {
+		return err
+	}
 	if !parsed.IsEloquent {
 		return errors.New(fmt.Sprintf("target: %s is not eloquent model", parsed.Name))
 	}
@@ -188,13 +197,18 @@ func Fill(target interface{}, values ...map[string]interface{}) error {
 
 }
 
+/*
+BootIfNotBooted fire the boot events the first time a model of this type is made
+*/
 func (m *EloquentModel) BootIfNotBooted() {
-	if !m.IsBooted {
-		//m.FireModelEvent(EventBooting,nil)
+	parsed := MustParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if _, booted := BootedModelsMap.LoadOrStore(parsed.Name, struct{}{}); !booted {
+		m.FireModelEvent(EventBooting, nil)
 		m.Booting()
 		m.Boot()
+		m.FireModelEvent(EventBoot, nil)
 		m.Booted()
-		//m.FireModelEvent(EventBooted,nil)
+		m.FireModelEvent(EventBooted, nil)
 	}
 }
 func (m *EloquentModel) Booting() {
@@ -214,21 +228,15 @@ func (m *EloquentModel) WithContext(ctx context.Context) *EloquentModel {
 //	func (m *EloquentModel) IsEager() bool {
 //		return !m.Exists
 //	}
-func (m *EloquentModel) IsDirty(structFieldName string) bool {
-	current := reflect.Indirect(m.ModelPointer)
-	parsed := GetParsedModel(reflect.Indirect(m.ModelPointer).Type())
-	fieldIndex := parsed.FieldsByStructName[structFieldName].Index
-	keyValue := current.Field(fieldIndex)
-
-	if keyValue.IsZero() {
-		return !reflect.ValueOf(m.Origin[structFieldName]).IsZero()
-	}
-	if keyValue.Comparable() {
-		return keyValue.Interface() != m.Origin[structFieldName]
-	} else {
-		//if it's not comparable, we will assume it's updated and update it every time
-		return true
+/*
+IsDirty Determine if the attribute has been changed since the last sync,field can be struct field name or db column name,unknown fields are never dirty.
+*/
+func (m *EloquentModel) IsDirty(field string) bool {
+	f := m.resolveField(field)
+	if f == nil {
+		return false
 	}
+	return m.fieldIsDirty(f, reflect.Indirect(m.ModelPointer).Field(f.Index))
 }
 
 /*
@@ -241,10 +249,10 @@ u.Only("Name").Save(&u)
 u.IsDirty() = false
 */
 func (m *EloquentModel) SyncOrigin() {
-	parsed := GetParsedModel(reflect.Indirect(m.ModelPointer).Type())
+	parsed := MustParseModel(reflect.Indirect(m.ModelPointer).Type())
 	model := reflect.Indirect(m.ModelPointer)
 	for _, field := range parsed.FieldsByDbName {
-		m.Origin[field.Name] = model.Field(field.Index).Interface()
+		m.Origin[field.Name] = originValue(field, model.Field(field.Index))
 	}
 	//reset after create/update
 	m.OnlyColumns = nil
@@ -256,7 +264,10 @@ Save save the model to the database
 */
 func (m *EloquentModel) Save(ps ...interface{}) (res Result, err error) {
 	if len(ps) > 0 && reflect.ValueOf(m).IsNil() {
-		parsed := GetParsedModel(reflect.Indirect(reflect.ValueOf(ps[0])).Type())
+		parsed, err := ParseModel(reflect.Indirect(reflect.ValueOf(ps[0])).Type())
+		if err != nil {
+			return Result{Error: err}, err
+		}
 		e := NewEloquentModel(ps[0])
 		reflect.ValueOf(ps[0]).Elem().Field(parsed.EloquentModelFieldIndex).Set(reflect.ValueOf(e))
 		return e.Save()
@@ -265,9 +276,25 @@ func (m *EloquentModel) Save(ps ...interface{}) (res Result, err error) {
 	if reflect.ValueOf(m).IsNil() {
 		panic("call Init(&model) first,or set modelPointer by call Save(&model)")
 	}
+	if err = m.Err; err != nil {
+		//the error is reported once,the model can be saved after the values are corrected
+		m.Err = nil
+		return Result{Error: err}, err
+	}
+	if m.needsAuditTransaction() {
+		return m.inAuditTransaction(func() (Result, error) {
+			return m.Save()
+		})
+	}
 	var saved map[string]interface{}
-	parsed := GetParsedModel(reflect.Indirect(m.ModelPointer).Type())
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return Result{Error: err}, err
+	}
 	builder := DB.Model(parsed).WithContext(m.Context)
+	if m.Tx != nil {
+		builder.Tx = m.Tx
+	}
 	if eventErr := m.FireModelEvent(EventSaving, builder); eventErr != nil {
 		return Result{Error: eventErr}, eventErr
 	}
@@ -276,23 +303,46 @@ func (m *EloquentModel) Save(ps ...interface{}) (res Result, err error) {
 			return Result{Error: eventErr}, eventErr
 		}
 		//TODO:SetDefaults
-		saved = m.GetAttributesForCreate()
-		res, err = builder.Insert(saved)
-		if err != nil {
-			return
-		}
-		id, err1 := res.LastInsertId()
-		if err1 == nil {
-			if parsed.PrimaryKey != nil {
-				reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Set(reflect.ValueOf(id))
+		if saved, err = m.GetAttributesForCreate(); err != nil {
+			return Result{Error: err}, err
+		}
+		//mysql generates the primary key for 0,other drivers would store it as is
+		if parsed.PrimaryKey != nil && builder.Grammar.GetDriver() != DriverMysql && reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).IsZero() {
+			delete(saved, parsed.PrimaryKey.ColumnName)
+		}
+		if builder.Grammar.GetDriver() == DriverPostgres && parsed.PrimaryKey != nil {
+			//postgres doesn't support LastInsertId,read it from "returning"
+			var id int64
+			id, err = builder.InsertGetId(saved, parsed.PrimaryKey.ColumnName)
+			if err != nil {
+				return
 			}
+			res = Result{Sql: builder.PreparedSql, Bindings: builder.GetBindings()}
+			reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Set(reflect.ValueOf(id))
 		} else {
-			err = err1
-			return
+			res, err = builder.Insert(saved)
+			if err != nil {
+				return
+			}
+			id, err1 := res.LastInsertId()
+			if err1 == nil {
+				if parsed.PrimaryKey != nil {
+					reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Set(reflect.ValueOf(id))
+				}
+			} else {
+				err = err1
+				return
+			}
 		}
 		m.Exists = true
 		m.Changes = m.GetDirty()
 		m.WasRecentlyCreated = true
+		if err = m.writeAudit(AuditCreated, func() (old, new map[string]interface{}, err error) {
+			new, err = m.auditAttributes()
+			return map[string]interface{}{}, new, err
+		}); err != nil {
+			return
+		}
 		if eventErr := m.FireModelEvent(EventCreated, builder); eventErr != nil {
 			return Result{Error: eventErr}, eventErr
 		}
@@ -300,9 +350,23 @@ func (m *EloquentModel) Save(ps ...interface{}) (res Result, err error) {
 		if eventErr := m.FireModelEvent(EventUpdating, builder); eventErr != nil {
 			return Result{Error: eventErr}, eventErr
 		}
+		if saved, err = m.GetAttributesForUpdate(); err != nil {
+			return Result{Error: err}, err
+		}
+		//models without updated_at have nothing to update when they are clean,
+		//running the update would compile "update ... set  where ..." and fail instead of saving nothing
+		if len(saved) > 0 {
+			res, err = builder.Where(parsed.PrimaryKey.ColumnName, reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Interface()).Update(saved)
+			if err != nil {
+				return
+			}
+		}
 		m.Changes = m.GetDirty()
-		saved = m.GetAttributesForUpdate()
-		res, err = builder.Where(parsed.PrimaryKey.ColumnName, reflect.Indirect(m.ModelPointer).Field(parsed.PrimaryKey.Index).Interface()).Update(saved)
+		if len(m.Changes) > 0 {
+			if err = m.writeAudit(m.updateAuditEvent(parsed), m.auditDiff); err != nil {
+				return
+			}
+		}
 		if eventErr := m.FireModelEvent(EventUpdated, builder); eventErr != nil {
 			return Result{Error: eventErr}, eventErr
 		}
@@ -316,6 +380,108 @@ func (m *EloquentModel) Save(ps ...interface{}) (res Result, err error) {
 
 }
 
+/*
+Push Save the model and all of its loaded relations in one transaction,uses the model's Tx if there is one,
+related models that are not inited yet are inited and created,their foreign keys are not set automatically.
+
+	user.Posts[0].Title = "foo"
+	user.Phone.Tel = "123456"
+	user.Posts = append(user.Posts, Post{Title: "bar", UserId: user.ID})
+	user.Push()
+*/
+func (m *EloquentModel) Push() (err error) {
+	if m.Tx != nil {
+		return m.push(m.Tx)
+	}
+	ctx := m.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parsed := MustParseModel(reflect.Indirect(m.ModelPointer).Type())
+	c, err := DB.Connection(parsed.ConnectionName)
+	if err != nil {
+		return
+	}
+	tx, err := c.BeginTransactionContext(ctx, nil)
+	if err != nil {
+		return
+	}
+	if err = m.push(tx); err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit()
+}
+
+func (m *EloquentModel) push(tx *Transaction) (err error) {
+	previous := m.Tx
+	m.Tx = tx
+	defer func() {
+		m.Tx = previous
+	}()
+	if _, err = m.Save(); err != nil {
+		return
+	}
+	model := reflect.Indirect(m.ModelPointer)
+	parsed, err := ParseModel(model.Type())
+	if err != nil {
+		return
+	}
+	relations := make([]string, 0, len(parsed.Relations))
+	for name := range parsed.Relations {
+		relations = append(relations, name)
+	}
+	sort.Strings(relations)
+	for _, name := range relations {
+		if err = pushRelated(model.Field(parsed.FieldsByStructName[name].Index), tx); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/*
+pushRelated push a loaded relation field,models not retrieved or initialized are skipped
+*/
+func pushRelated(value reflect.Value, tx *Transaction) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return pushRelated(value.Elem(), tx)
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if err := pushRelated(value.Index(i), tx); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		parsed, err := ParseModel(value.Type())
+		if err != nil {
+			return err
+		}
+		if !parsed.IsEloquent {
+			return nil
+		}
+		e, _ := value.Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel)
+		if e == nil || !e.IsBooted {
+			//models added to a relation field by hand are inited and saved as new ones,zero values are relations not loaded
+			if value.IsZero() || !value.CanAddr() {
+				return nil
+			}
+			Init(value.Addr().Interface())
+			e = value.Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel)
+		}
+		//eagerly loaded models may be copied into the relation field,point to the one user can modify
+		if value.CanAddr() {
+			e.ModelPointer = value.Addr()
+		}
+		return e.push(tx)
+	}
+	return nil
+}
+
 /*
 Create Save a new model and return the instance.
 */
@@ -327,18 +493,52 @@ func (m *EloquentModel) Create() (Result, error) {
 Delete Delete the model from the database.
 */
 func (m *EloquentModel) Delete() (res Result, err error) {
-	parsed := GetParsedModel(reflect.Indirect(m.ModelPointer).Type())
+	return m.delete(false)
+}
+
+/*
+delete Delete the model,soft deletable models are only marked as deleted unless force is true
+*/
+func (m *EloquentModel) delete(force bool) (res Result, err error) {
+	if m.needsAuditTransaction() {
+		return m.inAuditTransaction(func() (Result, error) {
+			return m.delete(force)
+		})
+	}
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return Result{Error: err}, err
+	}
 	b := DB.Model(parsed.ModelType).WithContext(m.Context)
+	if m.Tx != nil {
+		b.Tx = m.Tx
+	}
 	b.Where(parsed.PrimaryKey.ColumnName, m.ModelPointer.Elem().Field(parsed.PrimaryKey.Index).Interface())
+	if err = b.applyTenantScope(); err != nil {
+		return Result{Error: err}, err
+	}
 	if eventErr := m.FireModelEvent(EventDeleteing, b); eventErr != nil {
 		return Result{Error: eventErr}, eventErr
 	}
-	if parsed.SoftDelete {
-		b.Update(map[string]interface{}{
-			parsed.DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
-		})
+	var old map[string]interface{}
+	if _, ok := m.ModelPointer.Interface().(Auditable); ok {
+		if old, err = m.auditAttributes(); err != nil {
+			return Result{Error: err}, err
+		}
+	}
+	if parsed.SoftDelete && !force {
+		res, err = m.runSoftDelete(b)
 	} else {
-		res, err = b.Delete()
+		res, err = b.Builder.Delete()
+		m.Exists = false
+	}
+	if err != nil {
+		return
+	}
+	if err = m.writeAudit(AuditDeleted, func() (map[string]interface{}, map[string]interface{}, error) {
+		return old, map[string]interface{}{}, nil
+	}); err != nil {
+		return
 	}
 	if eventErr := m.FireModelEvent(EventDeleted, b); eventErr != nil {
 		return Result{Error: eventErr}, eventErr
@@ -348,7 +548,7 @@ func (m *EloquentModel) Delete() (res Result, err error) {
 func (m *EloquentModel) Mute(events ...string) *EloquentModel {
 	for i := 0; i < len(events); i++ {
 		if events[i] == EventALL {
-			m.Muted = strings.Join([]string{EventSaving, EventSaved, EventCreating, EventCreated, EventUpdating, EventUpdated, EventDeleteing, EventDeleted, EventRetrieved, EventRetrieving}, ",")
+			m.Muted = strings.Join([]string{EventSaving, EventSaved, EventCreating, EventCreated, EventUpdating, EventUpdated, EventDeleteing, EventDeleted, EventRestoring, EventRestored, EventForceDeleting, EventForceDeleted, EventRetrieved, EventRetrieving}, ",")
 			break
 		}
 		m.Muted = m.Muted + "," + events[i]
@@ -359,10 +559,13 @@ func (m *EloquentModel) Mute(events ...string) *EloquentModel {
 /*
 GetAttributesForUpdate Get the attributes that should be updated.
 */
-func (m *EloquentModel) GetAttributesForUpdate() (attrs map[string]interface{}) {
+func (m *EloquentModel) GetAttributesForUpdate() (attrs map[string]interface{}, err error) {
 	model := reflect.Indirect(m.ModelPointer)
 	attrs = make(map[string]interface{})
-	modelType := GetParsedModel(model.Type())
+	modelType, err := ParseModel(model.Type())
+	if err != nil {
+		return nil, err
+	}
 	var hasOnlyColumns, hasExceptColumns bool
 	if m.OnlyColumns != nil && len(m.OnlyColumns) > 0 {
 		hasOnlyColumns = true
@@ -384,11 +587,9 @@ func (m *EloquentModel) GetAttributesForUpdate() (attrs map[string]interface{})
 		}
 
 		if m.IsDirty(field.Name) {
-			v := model.Field(keyIndex).Interface()
-			if value, ok := v.(driver.Valuer); ok {
-				v, _ = value.Value()
+			if attrs[columnName], err = fieldValue(field, model.Field(keyIndex)); err != nil {
+				return nil, err
 			}
-			attrs[columnName] = v
 		}
 
 	}
@@ -415,10 +616,17 @@ func (m *EloquentModel) GetAttributesForUpdate() (attrs map[string]interface{})
 	}
 	return
 }
-func (m *EloquentModel) GetAttributesForCreate() (attrs map[string]interface{}) {
+
+/*
+GetAttributesForCreate Get the attributes that should be inserted.
+*/
+func (m *EloquentModel) GetAttributesForCreate() (attrs map[string]interface{}, err error) {
 	model := reflect.Indirect(m.ModelPointer)
 	attrs = make(map[string]interface{})
-	modelType := GetParsedModel(model.Type())
+	modelType, err := ParseModel(model.Type())
+	if err != nil {
+		return nil, err
+	}
 	var hasOnlyColumns, hasExceptColumns bool
 	if m.OnlyColumns != nil && len(m.OnlyColumns) > 0 {
 		hasOnlyColumns = true
@@ -440,14 +648,13 @@ func (m *EloquentModel) GetAttributesForCreate() (attrs map[string]interface{})
 				continue
 			}
 		}
-		v := model.Field(keyIndex).Interface()
-
 		//for create we will save all whether it's zero or not , can use .Only() to exclude zero columns
-		if value, ok := v.(driver.Valuer); ok {
-			v, _ = value.Value()
+		if attrs[columnName], err = fieldValue(field, model.Field(keyIndex)); err != nil {
+			return nil, err
 		}
-
-		attrs[columnName] = v
+	}
+	if err = m.fillTenant(modelType, attrs); err != nil {
+		return nil, err
 	}
 	if modelType.CreatedAt != "" {
 		//if user set it manually,we won't change it
@@ -482,7 +689,7 @@ func (m *EloquentModel) GetAttributes() (attrs map[string]interface{}) {
 
 	model := reflect.Indirect(m.ModelPointer)
 	attrs = make(map[string]interface{})
-	modelType := GetParsedModel(model.Type())
+	modelType := MustParseModel(model.Type())
 	for columnName, field := range modelType.FieldsByDbName {
 		keyIndex := field.Index
 		attrs[columnName] = model.Field(keyIndex).Interface()
@@ -492,7 +699,34 @@ func (m *EloquentModel) GetAttributes() (attrs map[string]interface{}) {
 func (m *EloquentModel) GetModel() interface{} {
 	return m.ModelPointer.Interface()
 }
+
+/*
+FireModelEvent call the model's own hook,then the observers registered by DB.Observe and the listeners registered by DB.Listen,
+muted events are not fired,an error from a "-ing" event aborts the operation,
+"-ed" events of models implementing AfterCommitEvents are deferred until the transaction they are in commits
+*/
 func (m *EloquentModel) FireModelEvent(eventName string, b *EloquentBuilder) error {
+	if strings.Contains(m.Muted, eventName) {
+		return nil
+	}
+	if tx := m.deferredEventTransaction(eventName); tx != nil {
+		tx.AfterCommit(func() {
+			_ = m.dispatchModelEvent(eventName)
+		})
+		return nil
+	}
+	return m.dispatchModelEvent(eventName)
+}
+func (m *EloquentModel) dispatchModelEvent(eventName string) error {
+	if err := m.fireModelHook(eventName); err != nil {
+		return err
+	}
+	if DB == nil {
+		return nil
+	}
+	return DB.fireModelEvent(eventName, m.ModelPointer.Interface())
+}
+func (m *EloquentModel) fireModelHook(eventName string) error {
 	reverted := m.ModelPointer.Interface()
 	switch eventName {
 	case EventSaving:
@@ -527,6 +761,22 @@ func (m *EloquentModel) FireModelEvent(eventName string, b *EloquentBuilder) err
 		if model, ok := reverted.(IDeleted); ok && !strings.Contains(m.Muted, EventDeleted) {
 			return model.EloquentDeleted()
 		}
+	case EventRestoring:
+		if model, ok := reverted.(IRestoring); ok && !strings.Contains(m.Muted, EventRestoring) {
+			return model.EloquentRestoring()
+		}
+	case EventRestored:
+		if model, ok := reverted.(IRestored); ok && !strings.Contains(m.Muted, EventRestored) {
+			return model.EloquentRestored()
+		}
+	case EventForceDeleting:
+		if model, ok := reverted.(IForceDeleting); ok && !strings.Contains(m.Muted, EventForceDeleting) {
+			return model.EloquentForceDeleting()
+		}
+	case EventForceDeleted:
+		if model, ok := reverted.(IForceDeleted); ok && !strings.Contains(m.Muted, EventForceDeleted) {
+			return model.EloquentForceDeleted()
+		}
 	case EventRetrieved:
 		if model, ok := reverted.(IRetrieved); ok && !strings.Contains(m.Muted, EventRetrieved) {
 			return model.EloquentRetrieved()
@@ -535,6 +785,18 @@ func (m *EloquentModel) FireModelEvent(eventName string, b *EloquentBuilder) err
 		if model, ok := reverted.(IRetrieving); ok && !strings.Contains(m.Muted, EventRetrieving) {
 			return model.EloquentRetrieving()
 		}
+	case EventBooting:
+		if model, ok := reverted.(IBooting); ok {
+			return model.EloquentBooting()
+		}
+	case EventBooted:
+		if model, ok := reverted.(IBooted); ok {
+			return model.EloquentBooted()
+		}
+	case EventInitialized:
+		if model, ok := reverted.(IInitialized); ok {
+			return model.EloquentInitialized()
+		}
 	}
 	return nil
 
@@ -548,11 +810,13 @@ Fill fill the model with a map of attributes.
     fill user with map[string]interface{},force fill ignore guard or fillable
  3. user.Fill(map[string]interface{},false,&user)
     fill user with map[string]interface{},honor fillable or guard and init with user
+
+values that can't be passed to a Set{Field}Attribute mutator are skipped,the error is returned by the next Save
 */
 func (m *EloquentModel) Fill(attrs map[string]interface{}, ps ...interface{}) *EloquentModel {
 	force := false
 	if len(ps) > 1 {
-		parsed := GetParsedModel(reflect.Indirect(reflect.ValueOf(ps[1])).Type())
+		parsed := MustParseModel(reflect.Indirect(reflect.ValueOf(ps[1])).Type())
 		e := NewEloquentModel(ps[1])
 		reflect.ValueOf(ps[1]).Elem().Field(parsed.EloquentModelFieldIndex).Set(reflect.ValueOf(e))
 		return e.Fill(attrs, ps[0])
@@ -564,7 +828,7 @@ func (m *EloquentModel) Fill(attrs map[string]interface{}, ps ...interface{}) *E
 		panic("model not inited yet,call Init first")
 	}
 	model := reflect.Indirect(m.ModelPointer)
-	config := GetParsedModel(model.Type())
+	config := MustParseModel(model.Type())
 	for k, v := range attrs {
 		if _, ok := config.Fillables[k]; !ok && len(config.Fillables) > 0 && !force {
 			continue
@@ -572,19 +836,38 @@ func (m *EloquentModel) Fill(attrs map[string]interface{}, ps ...interface{}) *E
 		if _, ok := config.Guards[k]; ok && len(config.Guards) > 0 && !force {
 			continue
 		}
-		if f, ok := config.FieldsByDbName[k]; ok {
-			model.Field(f.Index).Set(reflect.ValueOf(v))
-		} else if f, ok := config.FieldsByStructName[k]; ok {
+		f, ok := config.FieldsByDbName[k]
+		if !ok {
+			f, ok = config.FieldsByStructName[k]
+		}
+		if !ok {
+			continue
+		}
+		mutated, err := m.callMutator(config, f, v)
+		if err != nil {
+			m.addError(err)
+			continue
+		}
+		if !mutated {
 			model.Field(f.Index).Set(reflect.ValueOf(v))
 		}
 	}
 	return m
 }
+
+/*
+addError record the first error met while filling the model,it's returned by the next Save
+*/
+func (m *EloquentModel) addError(err error) {
+	if m.Err == nil && err != nil {
+		m.Err = err
+	}
+}
 func (m *EloquentModel) QualifyColumn(column string) string {
 	if strings.Contains(column, ".") {
 		return column
 	}
-	return GetParsedModel(m.ModelPointer.Type()).Table + "." + column
+	return MustParseModel(m.ModelPointer.Type()).Table + "." + column
 }
 
 /*
@@ -611,13 +894,19 @@ func (m *EloquentModel) Except(columns ...string) *EloquentModel {
 	}
 	return m
 }
-func (m *EloquentModel) Load(relations ...interface{}) {
+
+/*
+Load eager load relations for a model that is already retrieved,the first error met while loading them is returned
+
+ 1. user.Load("Posts", "Address")
+*/
+func (m *EloquentModel) Load(relations ...interface{}) error {
 
 	var b *EloquentBuilder
-	b = NewEloquentBuilder(m.ModelPointer)
+	b = NewEloquentBuilder(m.ModelPointer).WithContext(m.Context)
 
 	b.With(relations...)
 	b.Dest = m.ModelPointer.Interface()
 
-	b.EagerLoadRelations(b.Dest)
+	return b.EagerLoadRelations(b.Dest)
 }
@@ -11,7 +11,7 @@ import (
 type MysqlConnector struct {
 }
 
-func (c MysqlConnector) connect(config *DBConfig) *Connection {
+func (c MysqlConnector) connect(config *DBConfig) (*Connection, error) {
 	/**
 	[username[:password]@][protocol[(address)]]/dbname[?param1=value1&...¶mN=valueN]
 	// user@unix(/path/to/socket)/dbname
@@ -40,7 +40,10 @@ func (c MysqlConnector) connect(config *DBConfig) *Connection {
 		config.ConnMaxIdleTime = 7200
 	}
 
-	db := c.CreateConnection(c.GetDsn(config))
+	db, err := c.CreateConnection(c.GetDsn(config))
+	if err != nil {
+		return nil, err
+	}
 
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	db.SetMaxIdleConns(config.MaxIdleConns)
@@ -49,19 +52,24 @@ func (c MysqlConnector) connect(config *DBConfig) *Connection {
 	return &Connection{
 		DB:     db,
 		Config: config,
-	}
+	}, nil
 
 }
-func (c MysqlConnector) CreateConnection(dsn string) *sql.DB {
+
+/*
+CreateConnection open the database and ping it,the error is returned if the database can't be reached
+*/
+func (c MysqlConnector) CreateConnection(dsn string) (*sql.DB, error) {
 	db, err := sql.Open(string(DriverMysql), dsn)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	err = db.Ping()
 	if err != nil {
-		panic(err.Error())
+		db.Close()
+		return nil, err
 	}
-	return db
+	return db, nil
 }
 func (c MysqlConnector) ConfigureIsolationLevel(db *sql.DB, config DBConfig) {
 	if len(config.IsolationLevel) > 0 {
@@ -28,6 +28,21 @@ func (m *MysqlGrammar) GetBuilder() *Builder {
 	return m.Builder
 }
 
+func (m *MysqlGrammar) GetDriver() Driver {
+	return DriverMysql
+}
+
+/*
+grammar get the grammar the builder actually uses,other dialects embed MysqlGrammar and
+override part of the methods,calling through it makes those overrides take effect
+*/
+func (m *MysqlGrammar) grammar() Grammar {
+	if m.Builder != nil && m.Builder.Grammar != nil {
+		return m.Builder.Grammar
+	}
+	return m
+}
+
 func (m *MysqlGrammar) CompileInsert(values []map[string]interface{}) string {
 	b := m.GetBuilder()
 	b.PreSql.WriteString("insert into ")
@@ -71,6 +86,14 @@ func (m *MysqlGrammar) CompileInsertOrIgnore(values []map[string]interface{}) st
 	m.GetBuilder().PreparedSql = strings.Replace(m.CompileInsert(values), "insert", "insert ignore", 1)
 	return m.GetBuilder().PreparedSql
 }
+
+/*
+CompileInsertGetId Compile an insert and get ID statement into SQL.
+mysql reads the id from LastInsertId,so the sequence is ignored
+*/
+func (m *MysqlGrammar) CompileInsertGetId(values []map[string]interface{}, sequence string) string {
+	return m.CompileInsert(values)
+}
 func (m *MysqlGrammar) CompileDelete() string {
 	b := m.GetBuilder()
 	b.PreSql.WriteString("delete from ")
@@ -97,7 +120,7 @@ func (m *MysqlGrammar) CompileUpdate(value map[string]interface{}) string {
 	for k, v := range value {
 		count++
 		if (b.OnlyColumns == nil && b.ExceptColumns == nil) || b.FileterColumn(k) {
-			b.PreSql.WriteString(m.Wrap(k))
+			b.PreSql.WriteString(m.grammar().Wrap(k))
 			b.PreSql.WriteString(" = ")
 			b.AddBinding([]interface{}{v}, TYPE_UPDATE)
 			if e, ok := v.(Expression); ok {
@@ -118,7 +141,7 @@ func (m *MysqlGrammar) CompileUpdate(value map[string]interface{}) string {
 
 func (m *MysqlGrammar) CompileSelect() string {
 	b := m.GetBuilder()
-	if len(b.Aggregates) > 0 && len(b.Havings) > 0 {
+	if len(b.Aggregates) > 0 && (len(b.Havings) > 0 || len(b.Unions) > 0) {
 		return m.CompileUnionAggregate()
 	}
 	b.PreparedSql = ""
@@ -134,18 +157,21 @@ func (m *MysqlGrammar) CompileSelect() string {
 	}
 	b.PreparedSql = b.PreSql.String()
 	b.PreSql.Reset()
+	if len(b.Unions) > 0 {
+		b.PreparedSql = m.grammar().WrapUnion(b.PreparedSql) + m.CompileComponentUnions()
+	}
 	return b.PreparedSql
 }
 func (m *MysqlGrammar) CompileUnionAggregate() string {
 	b := m.GetBuilder()
 	sql := m.CompileComponentAggregate(b.Aggregates...)
 	b.Aggregates = []Aggregate{}
-	b.PreparedSql = fmt.Sprintf("%s from (%s) as %s", sql, m.CompileSelect(), m.Wrap("temp_table"))
+	b.PreparedSql = fmt.Sprintf("%s from (%s) as %s", sql, m.CompileSelect(), m.grammar().Wrap("temp_table"))
 	return b.PreparedSql
 }
 func (m *MysqlGrammar) CompileExists() string {
 	sql := m.CompileSelect()
-	m.GetBuilder().PreparedSql = fmt.Sprintf("select exists(%s) as %s", sql, m.Wrap("exists"))
+	m.GetBuilder().PreparedSql = fmt.Sprintf("select exists(%s) as %s", sql, m.grammar().Wrap("exists"))
 	return m.GetBuilder().PreparedSql
 }
 
@@ -171,9 +197,10 @@ func (m *MysqlGrammar) compileComponent(componentName string) string {
 		return m.CompileComponentLimitNum()
 	case TYPE_OFFSET:
 		return m.CompileComponentOffsetNum()
-	case "unions":
+	case TYPE_UNION:
+		//unions wrap the whole select,they are compiled at the end of CompileSelect
 	case TYPE_LOCK:
-		return m.CompileLock()
+		return m.grammar().CompileLock()
 	}
 	return ""
 }
@@ -194,9 +221,9 @@ func (m *MysqlGrammar) CompileComponentAggregate(aggregates ...Aggregate) string
 	builder.WriteString("(")
 	if m.GetBuilder().IsDistinct && aggregate.AggregateColumn != "*" {
 		builder.WriteString("distinct ")
-		builder.WriteString(m.Wrap(aggregate.AggregateColumn))
+		builder.WriteString(m.grammar().Wrap(aggregate.AggregateColumn))
 	} else {
-		builder.WriteString(m.Wrap(aggregate.AggregateColumn))
+		builder.WriteString(m.grammar().Wrap(aggregate.AggregateColumn))
 	}
 	builder.WriteString(") as aggregate")
 	return builder.String()
@@ -223,11 +250,11 @@ func (m *MysqlGrammar) CompileComponentColumns() string {
 func (m *MysqlGrammar) CompileComponentFromTable() string {
 	builder := strings.Builder{}
 	builder.WriteString(" from ")
-	builder.WriteString(m.WrapTable(m.GetBuilder().FromTable))
+	builder.WriteString(m.grammar().WrapTable(m.GetBuilder().FromTable))
 	return builder.String()
 }
 func (m *MysqlGrammar) CompileComponentTable() string {
-	return m.WrapTable(m.GetBuilder().FromTable)
+	return m.grammar().WrapTable(m.GetBuilder().FromTable)
 }
 func (m *MysqlGrammar) CompileComponentJoins() string {
 	builder := strings.Builder{}
@@ -235,9 +262,9 @@ func (m *MysqlGrammar) CompileComponentJoins() string {
 		var tableAndNestedJoins string
 		if len(join.Joins) > 0 {
 			//nested join
-			tableAndNestedJoins = fmt.Sprintf("(%s%s)", m.WrapTable(join.Table), join.Grammar.CompileComponentJoins())
+			tableAndNestedJoins = fmt.Sprintf("(%s%s)", m.grammar().WrapTable(join.Table), join.Grammar.CompileComponentJoins())
 		} else {
-			tableAndNestedJoins = m.WrapTable(join.Table)
+			tableAndNestedJoins = m.grammar().WrapTable(join.Table)
 		}
 		onStr := join.Grammar.CompileComponentWheres()
 		s := ""
@@ -275,10 +302,10 @@ func (m *MysqlGrammar) CompileComponentWheres() string {
 			builder.WriteString(")")
 		case CONDITION_TYPE_SUB:
 			builder.WriteString(w.Boolean + " ")
-			builder.WriteString(m.Wrap(w.Column))
+			builder.WriteString(m.grammar().Wrap(w.Column))
 			builder.WriteString(" " + w.Operator + " ")
 			builder.WriteString("(")
-			cb := NewQueryBuilder()
+			cb := m.GetBuilder().ForSubQuery()
 
 			if temp, ok := w.Value.(*Builder); ok {
 				sql := temp.Grammar.CompileSelect()
@@ -295,7 +322,7 @@ func (m *MysqlGrammar) CompileComponentWheres() string {
 			}
 			builder.WriteString(")")
 		default:
-			builder.WriteString(m.CompileWhere(w))
+			builder.WriteString(m.grammar().CompileWhere(w))
 		}
 
 		builder.WriteString(" ")
@@ -323,11 +350,11 @@ func (m *MysqlGrammar) CompileWhere(w Where) (sql string) {
 	sqlBuilder.WriteString(w.Boolean + " ")
 	switch w.Type {
 	case CONDITION_TYPE_BASIC:
-		sqlBuilder.WriteString(m.Wrap(w.Column))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 		sqlBuilder.WriteString(" " + w.Operator + " ")
 		sqlBuilder.WriteString(m.parameter(w.Value))
 	case CONDITION_TYPE_BETWEEN:
-		sqlBuilder.WriteString(m.Wrap(w.Column))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 		if w.Not {
 			sqlBuilder.WriteString(" not between ")
 		} else {
@@ -337,15 +364,15 @@ func (m *MysqlGrammar) CompileWhere(w Where) (sql string) {
 		sqlBuilder.WriteString(" and ")
 		sqlBuilder.WriteString(m.parameter(w.Values[1]))
 	case CONDITION_TYPE_BETWEEN_COLUMN:
-		sqlBuilder.WriteString(m.Wrap(w.Column))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 		if w.Not {
 			sqlBuilder.WriteString(" not between ")
 		} else {
 			sqlBuilder.WriteString(" between ")
 		}
-		sqlBuilder.WriteString(m.Wrap(w.Values[0]))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Values[0]))
 		sqlBuilder.WriteString(" and ")
-		sqlBuilder.WriteString(m.Wrap(w.Values[1]))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Values[1]))
 	case CONDITION_TYPE_IN:
 		if len(w.Values) == 0 {
 			if w.Not {
@@ -354,7 +381,7 @@ func (m *MysqlGrammar) CompileWhere(w Where) (sql string) {
 				sqlBuilder.WriteString("0 = 1")
 			}
 		} else {
-			sqlBuilder.WriteString(m.Wrap(w.Column))
+			sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 			if w.Not {
 				sqlBuilder.WriteString(" not in (")
 			} else {
@@ -367,24 +394,24 @@ func (m *MysqlGrammar) CompileWhere(w Where) (sql string) {
 	case CONDITION_TYPE_DATE, CONDITION_TYPE_TIME, CONDITION_TYPE_DAY, CONDITION_TYPE_MONTH, CONDITION_TYPE_YEAR:
 		sqlBuilder.WriteString(w.Type)
 		sqlBuilder.WriteString("(")
-		sqlBuilder.WriteString(m.Wrap(w.Column))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 		sqlBuilder.WriteString(") ")
 		sqlBuilder.WriteString(w.Operator)
 		sqlBuilder.WriteString(" ")
 		sqlBuilder.WriteString(m.parameter(w.Value))
 	case CONDITION_TYPE_NULL:
-		sqlBuilder.WriteString(m.Wrap(w.Column))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.Column))
 		sqlBuilder.WriteString(" is ")
 		if w.Not {
 			sqlBuilder.WriteString("not ")
 		}
 		sqlBuilder.WriteString("null")
 	case CONDITION_TYPE_COLUMN:
-		sqlBuilder.WriteString(m.Wrap(w.FirstColumn))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.FirstColumn))
 		sqlBuilder.WriteString(" ")
 		sqlBuilder.WriteString(w.Operator)
 		sqlBuilder.WriteString(" ")
-		sqlBuilder.WriteString(m.Wrap(w.SecondColumn))
+		sqlBuilder.WriteString(m.grammar().Wrap(w.SecondColumn))
 	case CONDITION_TYPE_RAW:
 		sqlBuilder.WriteString(string(w.RawSql.(Expression)))
 	case CONDITION_TYPE_NESTED:
@@ -404,7 +431,10 @@ func (m *MysqlGrammar) CompileWhere(w Where) (sql string) {
 		}
 		sqlBuilder.WriteString(fmt.Sprintf("(%s) %s (%s)", m.columnize(columns), w.Operator, m.parameter(w.Values...)))
 	case CONDITION_TYPE_JSON_CONTAINS:
-
+		if w.Not {
+			sqlBuilder.WriteString("not ")
+		}
+		sqlBuilder.WriteString(fmt.Sprintf("json_contains(%s, %s)", m.grammar().Wrap(w.Column), m.parameter(w.Value)))
 	default:
 		panic("where type not Found")
 	}
@@ -442,7 +472,7 @@ func (m *MysqlGrammar) CompileComponentHavings(query ...Builder) string {
 		}
 		switch having.HavingType {
 		case CONDITION_TYPE_BASIC:
-			builder.WriteString(m.Wrap(having.HavingColumn))
+			builder.WriteString(m.grammar().Wrap(having.HavingColumn))
 			builder.WriteString(" ")
 			builder.WriteString(having.HavingOperator)
 			builder.WriteString(" ")
@@ -451,7 +481,7 @@ func (m *MysqlGrammar) CompileComponentHavings(query ...Builder) string {
 			builder.WriteString(string(having.RawSql.(Expression)))
 		case CONDITION_TYPE_BETWEEN:
 			vs := having.HavingValue.([]interface{})
-			builder.WriteString(m.Wrap(having.HavingColumn))
+			builder.WriteString(m.grammar().Wrap(having.HavingColumn))
 			builder.WriteString(" ")
 			builder.WriteString(CONDITION_TYPE_BETWEEN)
 			builder.WriteString(" ")
@@ -464,7 +494,7 @@ func (m *MysqlGrammar) CompileComponentHavings(query ...Builder) string {
 			builder.WriteString(compiled)
 			builder.WriteString(")")
 		case CONDITION_TYPE_NULL:
-			builder.WriteString(m.Wrap(having.HavingColumn))
+			builder.WriteString(m.grammar().Wrap(having.HavingColumn))
 			builder.WriteString(" is ")
 			if having.Not {
 				builder.WriteString("not ")
@@ -477,9 +507,12 @@ func (m *MysqlGrammar) CompileComponentHavings(query ...Builder) string {
 }
 
 func (m *MysqlGrammar) CompileComponentOrders() string {
+	return m.compileOrders(m.GetBuilder().Orders)
+}
+func (m *MysqlGrammar) compileOrders(orders []Order) string {
 	builder := strings.Builder{}
 	builder.WriteString(" order by ")
-	for i, order := range m.GetBuilder().Orders {
+	for i, order := range orders {
 		if i != 0 {
 			builder.WriteString(", ")
 		}
@@ -487,7 +520,7 @@ func (m *MysqlGrammar) CompileComponentOrders() string {
 			builder.WriteString(string(order.RawSql.(Expression)))
 			continue
 		}
-		builder.WriteString(m.Wrap(order.Column))
+		builder.WriteString(m.grammar().Wrap(order.Column))
 		builder.WriteString(" ")
 		builder.WriteString(order.Direction)
 	}
@@ -514,6 +547,41 @@ func (m *MysqlGrammar) CompileComponentOffsetNum() string {
 	}
 	return ""
 }
+
+/*
+CompileComponentUnions Compile the union queries and the union level order by/limit/offset.
+
+union (select `name` from `admins`) order by `name` asc limit 10
+*/
+func (m *MysqlGrammar) CompileComponentUnions() string {
+	b := m.GetBuilder()
+	builder := strings.Builder{}
+	for _, union := range b.Unions {
+		if union.All {
+			builder.WriteString(" union all ")
+		} else {
+			builder.WriteString(" union ")
+		}
+		builder.WriteString(m.grammar().WrapUnion(union.Query.Grammar.CompileSelect()))
+	}
+	if len(b.UnionOrders) > 0 {
+		builder.WriteString(m.compileOrders(b.UnionOrders))
+	}
+	if b.UnionLimit > 0 {
+		builder.WriteString(fmt.Sprintf(" limit %v", b.UnionLimit))
+	}
+	if b.UnionOffset > 0 {
+		builder.WriteString(fmt.Sprintf(" offset %v", b.UnionOffset))
+	}
+	return builder.String()
+}
+
+/*
+WrapUnion Wrap a union subquery in parentheses.
+*/
+func (m *MysqlGrammar) WrapUnion(sql string) string {
+	return "(" + sql + ")"
+}
 func (m *MysqlGrammar) CompileLock() string {
 	switch m.GetBuilder().LockMode.(type) {
 	case string:
@@ -535,7 +603,7 @@ func (m *MysqlGrammar) columnize(columns []interface{}) string {
 	var t []string
 	for _, value := range columns {
 		if s, ok := value.(string); ok {
-			t = append(t, m.Wrap(s))
+			t = append(t, m.grammar().Wrap(s))
 		} else if e, ok := value.(Expression); ok {
 			t = append(t, string(e))
 		}
@@ -573,9 +641,9 @@ func (m *MysqlGrammar) WrapAliasedValue(value string, prefixAlias ...bool) strin
 	if len(prefixAlias) > 0 && prefixAlias[0] {
 		segments[1] = m.GetTablePrefix() + segments[1]
 	}
-	result.WriteString(m.Wrap(segments[0]))
+	result.WriteString(m.grammar().Wrap(segments[0]))
 	result.WriteString(" as ")
-	result.WriteString(m.WrapValue(segments[1]))
+	result.WriteString(m.grammar().WrapValue(segments[1]))
 	return result.String()
 }
 
@@ -589,9 +657,9 @@ func (m *MysqlGrammar) WrapSegments(values []string) string {
 	paramLength := len(values)
 	for i, value := range values {
 		if paramLength > 1 && i == 0 {
-			segments = append(segments, m.WrapTable(value))
+			segments = append(segments, m.grammar().WrapTable(value))
 		} else {
-			segments = append(segments, m.WrapValue(value))
+			segments = append(segments, m.grammar().WrapValue(value))
 		}
 	}
 	return strings.Join(segments, ".")
@@ -602,7 +670,7 @@ WrapTable wrap a table in keyword identifiers.
 */
 func (m *MysqlGrammar) WrapTable(tableName interface{}) string {
 	if str, ok := tableName.(string); ok {
-		return m.Wrap(m.GetTablePrefix()+str, true)
+		return m.grammar().Wrap(m.GetTablePrefix()+str, true)
 	} else if expr, ok := tableName.(Expression); ok {
 		return string(expr)
 	} else {
@@ -629,6 +697,12 @@ func (m *MysqlGrammar) CompileRandom(seed ...int) string {
 	}
 	return "RAND()"
 }
+
+/*
+CompileUpsert Compile an "upsert" statement into SQL.
+
+insert into `users` (`email`, `name`) values (?, ?) on duplicate key update `name` = values(`name`)
+*/
 func (m *MysqlGrammar) CompileUpsert(values []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) string {
 	sql := m.CompileInsert(values)
 	sql = sql + " on duplicate key update "
@@ -636,20 +710,22 @@ func (m *MysqlGrammar) CompileUpsert(values []map[string]interface{}, uniqueColu
 	var columns []string
 	switch t := updateColumns.(type) {
 	case nil:
-		for s, _ := range values[0] {
-			columns = append(columns, fmt.Sprintf("%s = values(%s)", m.Wrap(s), m.Wrap(s)))
+		for _, s := range upsertUpdateColumns(values, uniqueColumns) {
+			columns = append(columns, fmt.Sprintf("%s = values(%s)", m.grammar().Wrap(s), m.grammar().Wrap(s)))
 		}
 	case []string:
 		for _, column := range updateColumns.([]string) {
-			columns = append(columns, fmt.Sprintf("%s = values(%s)", m.Wrap(column), m.Wrap(column)))
+			columns = append(columns, fmt.Sprintf("%s = values(%s)", m.grammar().Wrap(column), m.grammar().Wrap(column)))
 		}
 	case map[string]interface{}:
-		for c, column := range updateColumns.(map[string]interface{}) {
-			columns = append(columns, fmt.Sprintf("%s = %s, ", m.Wrap(c), m.parameter(column)))
+		for _, c := range sortedColumns(t) {
+			columns = append(columns, fmt.Sprintf("%s = %s", m.grammar().Wrap(c), m.parameter(t[c])))
+			m.GetBuilder().AddBinding([]interface{}{t[c]}, TYPE_INSERT)
 		}
 	default:
-		panic(fmt.Sprintf("wrong type:%v", t))
+		m.GetBuilder().AddError(fmt.Errorf("upsert update columns must be nil,[]string or map[string]interface{},got %T", t))
 	}
 
-	return sql + strings.Join(columns, ", ")
+	m.GetBuilder().PreparedSql = sql + strings.Join(columns, ", ")
+	return m.GetBuilder().PreparedSql
 }
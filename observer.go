@@ -0,0 +1,53 @@
+package goeloquent
+
+import (
+	"reflect"
+)
+
+/*
+ModelEventListener listener for model events across all models,register with DB.Listen(EventSaving, listener),
+return an error from a "-ing" event to abort the operation
+*/
+type ModelEventListener func(model interface{}) error
+
+/*
+Observe register an observer for a model,the observer can implement any of the lifecycle methods,
+they are named after the event without the Eloquent prefix and receive the model pointer,
+return an error from a "-ing" method to abort the operation,call Unsubscribe on the returned subscription to remove it
+
+ 1. DB.Observe(&User{}, &UserObserver{}) UserObserver.Saving(model interface{}) error is called before a user is saved
+ 2. UserObserver.Retrieved(model interface{}) error is called after every user scanned by Get/First/Find
+*/
+func (dm *DatabaseManager) Observe(model interface{}, observer interface{}) *Subscription {
+	return dm.Dispatcher().Observe(model, observer)
+}
+
+/*
+fireModelEvent call the observers of the model then the listeners of the event,stop at the first error
+*/
+func (dm *DatabaseManager) fireModelEvent(eventName string, model interface{}) error {
+	return dm.Dispatcher().FireModelEvent(eventName, model)
+}
+
+/*
+fireRetrieved fire EventRetrieved for every eloquent model scanned into dest
+*/
+func (b *EloquentBuilder) fireRetrieved(dest interface{}) (err error) {
+	eachModel(dest, func(model reflect.Value) {
+		if err != nil || model.Kind() != reflect.Struct {
+			return
+		}
+		parsed, parseErr := ParseModel(model.Type())
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		if !parsed.IsEloquent {
+			return
+		}
+		if em, ok := model.Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel); ok && em != nil {
+			err = em.FireModelEvent(EventRetrieved, b)
+		}
+	})
+	return
+}
@@ -0,0 +1,100 @@
+package goeloquent
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+type PostgresConnector struct {
+}
+
+func (c PostgresConnector) connect(config *DBConfig) (*Connection, error) {
+	if config.MaxOpenConns == 0 {
+		config.MaxOpenConns = 10
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 5
+	}
+	if config.ConnMaxLifetime == 0 {
+		config.ConnMaxLifetime = 86400
+	}
+	if config.ConnMaxIdleTime == 0 {
+		config.ConnMaxIdleTime = 7200
+	}
+
+	db, err := c.CreateConnection(c.GetDsn(config))
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(config.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(config.ConnMaxIdleTime) * time.Second)
+	return &Connection{
+		DB:     db,
+		Config: config,
+	}, nil
+}
+
+/*
+CreateConnection open the database and ping it,the error is returned if the database can't be reached
+*/
+func (c PostgresConnector) CreateConnection(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(string(DriverPostgres), dsn)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+/*
+GetDsn build a key/value connection string
+
+host=127.0.0.1 port=5432 user=root password=secret dbname=goeloquent sslmode=disable
+*/
+func (c PostgresConnector) GetDsn(config *DBConfig) string {
+	if len(config.Dsn) > 0 {
+		return config.Dsn
+	}
+	var params []string
+	if len(config.Host) > 0 {
+		params = append(params, "host="+config.Host)
+	} else if len(config.UnixSocket) > 0 {
+		params = append(params, "host="+config.UnixSocket)
+	}
+	if len(config.Port) > 0 {
+		params = append(params, "port="+config.Port)
+	}
+	if len(config.Username) > 0 {
+		params = append(params, "user="+config.Username)
+	}
+	if len(config.Password) > 0 {
+		params = append(params, fmt.Sprintf("password='%s'", strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(config.Password)))
+	}
+	if len(config.Database) > 0 {
+		params = append(params, "dbname="+config.Database)
+	}
+	if len(config.Sslmode) > 0 {
+		params = append(params, "sslmode="+config.Sslmode)
+	}
+	if len(config.Charset) > 0 {
+		params = append(params, "client_encoding="+config.Charset)
+	}
+	if len(config.Schema) > 0 {
+		params = append(params, "search_path="+config.Schema)
+	}
+	dsn := strings.Join(params, " ")
+	if len(config.DsnExtraString) > 0 {
+		dsn = dsn + " " + config.DsnExtraString
+	}
+	return dsn
+}
@@ -0,0 +1,174 @@
+package goeloquent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+PostgresGrammar compile queries for postgresql.
+
+It reuses MysqlGrammar and overrides identifier wrapping("users"."name"),
+json selectors(meta->'address'->>'city'),date wheres and the insert variants,
+placeholders are numbered($1,$2...) once the statement is compiled.
+*/
+type PostgresGrammar struct {
+	MysqlGrammar
+}
+
+func (g *PostgresGrammar) GetDriver() Driver {
+	return DriverPostgres
+}
+
+func (g *PostgresGrammar) CompileSelect() string {
+	return g.rebind(g.MysqlGrammar.CompileSelect())
+}
+
+func (g *PostgresGrammar) CompileExists() string {
+	return g.rebind(g.MysqlGrammar.CompileExists())
+}
+
+func (g *PostgresGrammar) CompileInsert(values []map[string]interface{}) string {
+	return g.rebind(g.MysqlGrammar.CompileInsert(values))
+}
+
+/*
+CompileInsertOrIgnore Compile an insert ignore statement into SQL.
+
+insert into "users" ("name") values ($1) on conflict do nothing
+*/
+func (g *PostgresGrammar) CompileInsertOrIgnore(values []map[string]interface{}) string {
+	return g.rebind(g.MysqlGrammar.CompileInsert(values) + " on conflict do nothing")
+}
+
+/*
+CompileInsertGetId Compile an insert and get ID statement into SQL.
+
+insert into "users" ("name") values ($1) returning "id"
+*/
+func (g *PostgresGrammar) CompileInsertGetId(values []map[string]interface{}, sequence string) string {
+	if len(sequence) == 0 {
+		sequence = "id"
+	}
+	return g.rebind(fmt.Sprintf("%s returning %s", g.MysqlGrammar.CompileInsert(values), g.Wrap(sequence)))
+}
+
+func (g *PostgresGrammar) CompileUpdate(value map[string]interface{}) string {
+	return g.rebind(g.MysqlGrammar.CompileUpdate(value))
+}
+
+func (g *PostgresGrammar) CompileDelete() string {
+	return g.rebind(g.MysqlGrammar.CompileDelete())
+}
+
+/*
+CompileUpsert Compile an "upsert" statement into SQL.
+
+insert into "users" ("email", "name") values ($1, $2) on conflict ("email") do update set "name" = "excluded"."name"
+*/
+func (g *PostgresGrammar) CompileUpsert(values []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) string {
+	return g.rebind(compileOnConflictUpsert(&g.MysqlGrammar, values, uniqueColumns, updateColumns))
+}
+
+func (g *PostgresGrammar) CompileWhere(w Where) string {
+	switch w.Type {
+	case CONDITION_TYPE_DATE, CONDITION_TYPE_TIME:
+		return fmt.Sprintf("%s %s::%s %s %s", w.Boolean, g.Wrap(w.Column), w.Type, w.Operator, g.parameter(w.Value))
+	case CONDITION_TYPE_DAY, CONDITION_TYPE_MONTH, CONDITION_TYPE_YEAR:
+		return fmt.Sprintf("%s extract(%s from %s) %s %s", w.Boolean, w.Type, g.Wrap(w.Column), w.Operator, g.parameter(w.Value))
+	case CONDITION_TYPE_JSON_CONTAINS:
+		not := ""
+		if w.Not {
+			not = "not "
+		}
+		return fmt.Sprintf("%s %s(%s)::jsonb @> %s", w.Boolean, not, g.Wrap(w.Column), g.parameter(w.Value))
+	}
+	return g.MysqlGrammar.CompileWhere(w)
+}
+
+func (g *PostgresGrammar) CompileLock() string {
+	if lock, ok := g.GetBuilder().LockMode.(bool); ok && !lock {
+		return " for share"
+	}
+	return g.MysqlGrammar.CompileLock()
+}
+
+func (g *PostgresGrammar) CompileRandom(seed ...int) string {
+	return "RANDOM()"
+}
+
+/*
+Wrap a value in keyword identifiers,json selectors are converted to postgres json operators.
+
+meta->address->city => "meta"->'address'->>'city'
+*/
+func (g *PostgresGrammar) Wrap(value interface{}, prefixAlias ...bool) string {
+	if str, ok := value.(string); ok && strings.Contains(str, "->") && !strings.Contains(str, " as ") && !strings.Contains(str, " AS ") {
+		column, path := splitJsonPath(str)
+		var builder strings.Builder
+		builder.WriteString(g.MysqlGrammar.Wrap(column))
+		for i, segment := range path {
+			if i == len(path)-1 {
+				builder.WriteString("->>")
+			} else {
+				builder.WriteString("->")
+			}
+			if _, err := strconv.Atoi(segment); err == nil {
+				builder.WriteString(segment)
+			} else {
+				builder.WriteString("'" + strings.ReplaceAll(segment, "'", "''") + "'")
+			}
+		}
+		return builder.String()
+	}
+	return g.MysqlGrammar.Wrap(value, prefixAlias...)
+}
+
+/*
+WrapValue Wrap a value in keyword identifiers.
+*/
+func (g *PostgresGrammar) WrapValue(value string) string {
+	if value != "*" {
+		return fmt.Sprintf(`"%s"`, strings.ReplaceAll(value, `"`, `""`))
+	}
+	return value
+}
+
+/*
+rebind number the placeholders of the compiled sql from left to right,
+both "?" and already numbered "$n" are renumbered so sub queries compiled on their own stay in order.
+*/
+func (g *PostgresGrammar) rebind(sql string) string {
+	var builder strings.Builder
+	var quote byte
+	n := 0
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			builder.WriteByte(c)
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		case c == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			for i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9' {
+				i++
+			}
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteByte(c)
+	}
+	g.GetBuilder().PreparedSql = builder.String()
+	return g.GetBuilder().PreparedSql
+}
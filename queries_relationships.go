@@ -0,0 +1,377 @@
+package goeloquent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+Has Add a relationship count / exists condition to the query.
+params are the operator(default ">=") and the count(default 1),relation can be nested with dots
+
+ 1. Has("Posts")
+
+    select * from `users` where exists (select * from `posts` where `users`.`id` = `posts`.`user_id`)
+
+ 2. Has("Posts", ">=", 3)
+
+    select * from `users` where (select count(*) from `posts` where `users`.`id` = `posts`.`user_id`) >= 3
+
+ 3. Has("Posts.Comments")
+
+    select * from `users` where exists (select * from `posts` where `users`.`id` = `posts`.`user_id` and exists (select * from `comments` where `posts`.`id` = `comments`.`post_id`))
+*/
+func (b *EloquentBuilder) Has(relation string, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.has(relation, operator, count, BOOLEAN_AND, nil)
+}
+
+/*
+OrHas Add a relationship count / exists condition to the query with an "or".
+*/
+func (b *EloquentBuilder) OrHas(relation string, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.has(relation, operator, count, BOOLEAN_OR, nil)
+}
+
+/*
+DoesntHave Add a relationship count / exists condition to the query.
+
+ 1. DoesntHave("Posts")
+
+    select * from `users` where not exists (select * from `posts` where `users`.`id` = `posts`.`user_id`)
+*/
+func (b *EloquentBuilder) DoesntHave(relation string, callback ...EloquentBuilderChainFunc) *EloquentBuilder {
+	return b.has(relation, "<", 1, BOOLEAN_AND, firstConstraint(callback))
+}
+
+/*
+OrDoesntHave Add a relationship count / exists condition to the query with an "or".
+*/
+func (b *EloquentBuilder) OrDoesntHave(relation string, callback ...EloquentBuilderChainFunc) *EloquentBuilder {
+	return b.has(relation, "<", 1, BOOLEAN_OR, firstConstraint(callback))
+}
+
+/*
+WhereHas Add a relationship count / exists condition to the query with where clauses.
+params are the operator(default ">=") and the count(default 1)
+
+ 1. WhereHas("Posts", func(builder *EloquentBuilder) *EloquentBuilder {
+    return builder.Where("status", 1)
+    })
+
+    select * from `users` where exists (select * from `posts` where `users`.`id` = `posts`.`user_id` and `status` = ?)
+
+ 2. WhereHas("Posts", func(builder *EloquentBuilder) *EloquentBuilder {
+    return builder.Where("status", 1)
+    }, ">", 10)
+
+    select * from `users` where (select count(*) from `posts` where `users`.`id` = `posts`.`user_id` and `status` = ?) > 10
+*/
+func (b *EloquentBuilder) WhereHas(relation string, callback EloquentBuilderChainFunc, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.has(relation, operator, count, BOOLEAN_AND, callback)
+}
+
+/*
+OrWhereHas Add a relationship count / exists condition to the query with where clauses and an "or".
+*/
+func (b *EloquentBuilder) OrWhereHas(relation string, callback EloquentBuilderChainFunc, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.has(relation, operator, count, BOOLEAN_OR, callback)
+}
+
+/*
+WhereDoesntHave Add a relationship count / exists condition to the query with where clauses.
+*/
+func (b *EloquentBuilder) WhereDoesntHave(relation string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	return b.has(relation, "<", 1, BOOLEAN_AND, callback)
+}
+
+/*
+OrWhereDoesntHave Add a relationship count / exists condition to the query with where clauses and an "or".
+*/
+func (b *EloquentBuilder) OrWhereDoesntHave(relation string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	return b.has(relation, "<", 1, BOOLEAN_OR, callback)
+}
+
+/*
+WhereRelation Add a basic where clause to a relationship query,params are the same as Where
+
+ 1. WhereRelation("Posts", "status", 1)
+
+    select * from `users` where exists (select * from `posts` where `users`.`id` = `posts`.`user_id` and `status` = ?)
+*/
+func (b *EloquentBuilder) WhereRelation(relation string, params ...interface{}) *EloquentBuilder {
+	return b.WhereHas(relation, func(builder *EloquentBuilder) *EloquentBuilder {
+		return builder.Where(params...)
+	})
+}
+
+/*
+OrWhereRelation Add an "or where" clause to a relationship query.
+*/
+func (b *EloquentBuilder) OrWhereRelation(relation string, params ...interface{}) *EloquentBuilder {
+	return b.OrWhereHas(relation, func(builder *EloquentBuilder) *EloquentBuilder {
+		return builder.Where(params...)
+	})
+}
+
+/*
+WhereHasMorph Add a polymorphic relationship count / exists condition to the query with where clauses.
+types are the registered morph names(see RegistMorphMap),params are the operator(default ">=") and the count(default 1)
+
+ 1. WhereHasMorph("Commentable", []string{"post", "video"}, func(builder *EloquentBuilder) *EloquentBuilder {
+    return builder.Where("title", "like", "foo%")
+    })
+
+    select * from `comments` where ((`comments`.`commentable_type` = ? and exists (select * from `posts` where `posts`.`id` = `comments`.`commentable_id` and `title` like ?))
+    or (`comments`.`commentable_type` = ? and exists (select * from `videos` where `videos`.`id` = `comments`.`commentable_id` and `title` like ?)))
+*/
+func (b *EloquentBuilder) WhereHasMorph(relation string, types []string, callback EloquentBuilderChainFunc, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.hasMorph(relation, types, operator, count, BOOLEAN_AND, callback)
+}
+
+/*
+OrWhereHasMorph Add a polymorphic relationship count / exists condition to the query with where clauses and an "or".
+*/
+func (b *EloquentBuilder) OrWhereHasMorph(relation string, types []string, callback EloquentBuilderChainFunc, params ...interface{}) *EloquentBuilder {
+	operator, count, err := parseHasParams(params)
+	if err != nil {
+		b.AddError(err)
+		return b
+	}
+	return b.hasMorph(relation, types, operator, count, BOOLEAN_OR, callback)
+}
+
+/*
+WhereDoesntHaveMorph Add a polymorphic relationship count / exists condition to the query with where clauses.
+*/
+func (b *EloquentBuilder) WhereDoesntHaveMorph(relation string, types []string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	return b.hasMorph(relation, types, "<", 1, BOOLEAN_AND, callback)
+}
+
+/*
+WhereMorphRelation Add a basic where clause to a polymorphic relationship query,params are the same as Where
+
+ 1. WhereMorphRelation("Commentable", []string{"post"}, "status", 1)
+*/
+func (b *EloquentBuilder) WhereMorphRelation(relation string, types []string, params ...interface{}) *EloquentBuilder {
+	return b.WhereHasMorph(relation, types, func(builder *EloquentBuilder) *EloquentBuilder {
+		return builder.Where(params...)
+	})
+}
+
+/*
+OrWhereMorphRelation Add a basic "or where" clause to a polymorphic relationship query.
+*/
+func (b *EloquentBuilder) OrWhereMorphRelation(relation string, types []string, params ...interface{}) *EloquentBuilder {
+	return b.OrWhereHasMorph(relation, types, func(builder *EloquentBuilder) *EloquentBuilder {
+		return builder.Where(params...)
+	})
+}
+
+func (b *EloquentBuilder) has(relationName string, operator string, count int, boolean string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	if strings.Contains(relationName, ".") {
+		return b.hasNested(relationName, operator, count, boolean, callback)
+	}
+	relation := b.getRelationWithoutConstraints(relationName)
+	if relation == nil {
+		return b
+	}
+	if _, ok := relation.(*MorphToRelation); ok {
+		b.AddError(fmt.Errorf("relation [%s] is a MorphTo relation,use WhereHasMorph instead", relationName))
+		return b
+	}
+	alias := fmt.Sprintf("%s%d", OrmAggregateAlias, len(b.Wheres)+1)
+	hasQuery := relation.GetRelationExistenceQuery(relation.GetEloquentBuilder(), b, alias, existenceColumns(operator, count))
+
+	return b.addHasWhere(hasQuery, operator, count, boolean, callback)
+}
+
+/*
+hasNested Add nested relationship count / exists conditions to the query.
+
+Has("Posts.Comments") is converted to WhereHas("Posts", func(builder){ return builder.Has("Comments") })
+*/
+func (b *EloquentBuilder) hasNested(relationName string, operator string, count int, boolean string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	relations := strings.Split(relationName, ".")
+	//the "doesn't have" check applies to the outer relation,inner relations must exist
+	doesntHave := operator == "<" && count == 1
+	if doesntHave {
+		operator = ">="
+	}
+	var closure EloquentBuilderChainFunc
+	closure = func(builder *EloquentBuilder) *EloquentBuilder {
+		if len(relations) > 1 {
+			name := relations[0]
+			relations = relations[1:]
+			return builder.WhereHas(name, closure)
+		}
+		return builder.has(relations[0], operator, count, BOOLEAN_AND, callback)
+	}
+	name := relations[0]
+	relations = relations[1:]
+	if doesntHave {
+		return b.has(name, "<", 1, boolean, closure)
+	}
+	return b.has(name, ">=", 1, boolean, closure)
+}
+
+func (b *EloquentBuilder) hasMorph(relationName string, types []string, operator string, count int, boolean string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	r := b.getRelationWithoutConstraints(relationName)
+	if r == nil {
+		return b
+	}
+	relation, ok := r.(*MorphToRelation)
+	if !ok {
+		b.AddError(fmt.Errorf("relation [%s] is not a MorphTo relation", relationName))
+		return b
+	}
+	models := make([]reflect.Value, len(types))
+	for i, morphType := range types {
+		v, err := GetMorphDBMap(morphType)
+		if err != nil {
+			b.AddError(err)
+			return b
+		}
+		models[i] = v
+	}
+	selfTable := b.BaseModel.Table
+	b.Builder.Where(func(builder *Builder) {
+		for i, morphType := range types {
+			relatedQuery := NewEloquentBuilder(reflect.New(models[i].Type()).Interface())
+			relatedQuery.Select(Raw(existenceColumns(operator, count))).
+				WhereColumn(relatedQuery.BaseModel.Table+"."+relation.RelatedModelIdColumn, "=", selfTable+"."+relation.SelfRelatedIdColumn)
+			hasQuery := ToEloquentBuilder(builder.newNestedQuery())
+			hasQuery.Where(selfTable+"."+relation.SelfRelatedTypeColumn, morphType)
+			hasQuery.addHasWhere(relatedQuery, operator, count, BOOLEAN_AND, callback)
+			builder.AddNestedWhereQuery(hasQuery.Builder, BOOLEAN_OR)
+		}
+	}, boolean)
+	return b
+}
+
+/*
+addHasWhere Add the "has" condition where clause to the query,
+an exists clause is used unless the count has to be compared
+*/
+func (b *EloquentBuilder) addHasWhere(hasQuery *EloquentBuilder, operator string, count int, boolean string, callback EloquentBuilderChainFunc) *EloquentBuilder {
+	//the scopes of the related model,like the tenant scope,read the parent query's context
+	hasQuery.WithContext(b.Context)
+	if callback != nil {
+		callback(hasQuery)
+	}
+	hasQuery.ApplyGlobalScopes()
+	b.AddError(hasQuery.Err)
+	if canUseExistsForExistenceCheck(operator, count) {
+		b.Builder.AddWhereExistsQuery(hasQuery.Builder, boolean, operator == "<")
+		return b
+	}
+	b.Builder.WhereRaw(fmt.Sprintf("(%s) %s %d", hasQuery.ToSql(), operator, count), hasQuery.GetBindings(), boolean)
+	return b
+}
+
+/*
+getRelationWithoutConstraints Get the relation of the model with its query rebuilt without the parent key constraints added by AddConstraints,
+nil is returned and the error is recorded on the builder if the relation doesn't exist
+*/
+func (b *EloquentBuilder) getRelationWithoutConstraints(name string) RelationI {
+	if b.BaseModel == nil {
+		b.AddError(fmt.Errorf("relation %s need a model", name))
+		return nil
+	}
+	method, ok := b.BaseModel.Relations[name]
+	if !ok {
+		b.AddError(fmt.Errorf("Relation method [%s] not found in model:[%s]", name, b.BaseModel.Name))
+		return nil
+	}
+	relation := method.Call([]reflect.Value{})[0].Interface().(RelationI)
+	if r, ok := relation.(interface{ removeConstraints() }); ok {
+		r.removeConstraints()
+	}
+	rq := relation.GetEloquentBuilder()
+	rq.Columns = nil
+	delete(rq.Bindings, TYPE_SELECT)
+	return relation
+}
+
+func canUseExistsForExistenceCheck(operator string, count int) bool {
+	return (operator == ">=" || operator == "<") && count == 1
+}
+
+func existenceColumns(operator string, count int) string {
+	if canUseExistsForExistenceCheck(operator, count) {
+		return "*"
+	}
+	return "count(*)"
+}
+
+/*
+parseHasParams get the operator(default ">=") and the count(default 1) of a relation existence query,
+the operator must be one of Operators since it's written into the sql,the count can be any integer type
+*/
+func parseHasParams(params []interface{}) (operator string, count int, err error) {
+	operator, count = ">=", 1
+	if len(params) > 0 {
+		o, ok := params[0].(string)
+		if !ok {
+			return "", 0, fmt.Errorf("the operator of a relation existence query must be a string,got %T", params[0])
+		}
+		if !isOperator(o) {
+			return "", 0, fmt.Errorf("invalid operator %q for a relation existence query", o)
+		}
+		operator = o
+	}
+	if len(params) > 1 {
+		v := reflect.ValueOf(params[1])
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			count = int(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			count = int(v.Uint())
+		default:
+			return "", 0, fmt.Errorf("the count of a relation existence query must be an integer,got %T", params[1])
+		}
+	}
+	return
+}
+
+func isOperator(operator string) bool {
+	operator = strings.ToLower(operator)
+	for _, o := range Operators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func firstConstraint(callbacks []EloquentBuilderChainFunc) EloquentBuilderChainFunc {
+	if len(callbacks) > 0 {
+		return callbacks[0]
+	}
+	return nil
+}
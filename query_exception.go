@@ -1,48 +1,137 @@
 package goeloquent
 
 import (
+	"errors"
 	"fmt"
-	"strings"
 )
 
+/*
+ErrRecordNotFound returned by FirstOrFail/FindOrFail when no rows match,wrapped in a QueryException
+
+ 1. if errors.Is(err, ErrRecordNotFound) { respond 404 }
+*/
+var ErrRecordNotFound = errors.New("record not found")
+
+/*
+QueryException errors of statements carry the sql and bindings,Err is one of the typed errors below if the driver error is classified,
+otherwise the driver error itself,use errors.Is/errors.As to inspect it
+
+ 1. var unique *UniqueConstraintViolation; if errors.As(err, &unique) { respond 409 }
+ 2. var mysqlErr *mysql.MySQLError; errors.As(err, &mysqlErr) the driver error is still reachable
+*/
 type QueryException struct {
 	Err      error
 	Sql      string
 	Bindings []interface{}
 }
 
+func (e *QueryException) Unwrap() error {
+	return e.Err
+}
+
+/*
+UniqueConstraintViolation a unique or primary key constraint is violated
+*/
+type UniqueConstraintViolation struct {
+	Err error
+}
+
+func (e *UniqueConstraintViolation) Error() string {
+	return e.Err.Error()
+}
+func (e *UniqueConstraintViolation) Unwrap() error {
+	return e.Err
+}
+
+/*
+ForeignKeyViolation a foreign key constraint is violated
+*/
+type ForeignKeyViolation struct {
+	Err error
+}
+
+func (e *ForeignKeyViolation) Error() string {
+	return e.Err.Error()
+}
+func (e *ForeignKeyViolation) Unwrap() error {
+	return e.Err
+}
+
+/*
+DeadlockError a deadlock,serialization failure or lock wait timeout,the transaction can be retried with TransactionWithRetry
+*/
+type DeadlockError struct {
+	Err error
+}
+
+func (e *DeadlockError) Error() string {
+	return e.Err.Error()
+}
+func (e *DeadlockError) Unwrap() error {
+	return e.Err
+}
+
+/*
+ConnectionLost the connection to the database is broken
+*/
+type ConnectionLost struct {
+	Err error
+}
+
+func (e *ConnectionLost) Error() string {
+	return e.Err.Error()
+}
+func (e *ConnectionLost) Unwrap() error {
+	return e.Err
+}
+
+/*
+classifyError wrap the driver error in a typed error with the connection's ErrorInspector
+*/
+func (c *Connection) classifyError(err error) error {
+	if c.Config == nil {
+		return err
+	}
+	inspector := GetErrorInspector(c.Config.Driver)
+	if inspector == nil {
+		return err
+	}
+	switch {
+	case inspector.IsUniqueViolation(err):
+		return &UniqueConstraintViolation{Err: err}
+	case inspector.IsForeignKeyViolation(err):
+		return &ForeignKeyViolation{Err: err}
+	case inspector.IsDeadlock(err) || inspector.IsLockWaitTimeout(err):
+		return &DeadlockError{Err: err}
+	case inspector.IsConnectionLost(err):
+		return &ConnectionLost{Err: err}
+	}
+	return err
+}
+
+/*
+queryError wrap the error of a statement in a QueryException and fire EventErrorArised
+*/
+func (c *Connection) queryError(query string, bindings []interface{}, err error) error {
+	var exception *QueryException
+	if !errors.As(err, &exception) {
+		err = &QueryException{Err: c.classifyError(err), Sql: query, Bindings: bindings}
+	}
+	DB.FireEvent(EventErrorArised, QueryErrorEvent{ConnectionName: c.ConnectionName, Sql: query, Bindings: bindings, Error: err})
+	return err
+}
+
+/*
+Error the driver message and the sql on a single line,bindings are left out so values like passwords don't end up in logs,
+they are kept in Bindings for callers who want them
+*/
 func (e *QueryException) Error() string {
-	var errString strings.Builder
-	errString.Grow(1024)
-	errString.WriteString("\x1b[91mOops🔥\x1b[39m \n\t")
-	errString.WriteString(e.Err.Error())
-	errString.WriteString(";\n\tError SQL: ")
-	errString.WriteString(e.Sql)
-	errString.WriteString("\n\tBindings:[")
-	for k, v := range e.Bindings {
-		switch s := v.(type) {
-		case string:
-			if k == 0 {
-				errString.WriteString(s)
-			} else {
-				errString.WriteString(", ")
-				errString.WriteString(s)
-			}
-		case int:
-			if k == 0 {
-				errString.WriteString(fmt.Sprintf("%v", s))
-			} else {
-				errString.WriteString(", ")
-				errString.WriteString(fmt.Sprintf("%v", s))
-			}
-		default:
-			if k == 0 {
-				errString.WriteString("???")
-			} else {
-				errString.WriteString(", ???")
-			}
-		}
+	return fmt.Sprintf("%s (SQL: %s)", e.Err.Error(), e.Sql)
+}
+
+func recordNotFound(result Result, err error) error {
+	if err == nil && result.Count == 0 {
+		return &QueryException{Err: ErrRecordNotFound, Sql: result.Sql, Bindings: result.Bindings}
 	}
-	errString.WriteString("]")
-	return errString.String()
+	return err
 }
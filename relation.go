@@ -1,18 +1,34 @@
 package goeloquent
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 )
 
 type Relations string
 
-func GetMorphMap(name string) string {
+/*
+GetMorphMap get the morph type registered by RegistMorphMap for a model name,an error is returned if the model isn't registered
+*/
+func GetMorphMap(name string) (string, error) {
 	v, ok := RegisteredMorphModelsMap.Load(name)
 	if !ok {
-		panic(fmt.Sprintf("no registered morph model found for %s,check your code or register it", name))
+		return "", fmt.Errorf("no registered morph model found for %s,check your code or register it", name)
+	}
+	return v.(string), nil
+}
+
+/*
+MustGetMorphMap same as GetMorphMap but panic if the model isn't registered
+*/
+func MustGetMorphMap(name string) string {
+	v, err := GetMorphMap(name)
+	if err != nil {
+		panic(err.Error())
 	}
-	return v.(string)
+	return v
 }
 
 const (
@@ -38,18 +54,46 @@ const (
 )
 
 type Relation struct {
-	SelfModel        interface{} // parent/self model pointer,usually a pointer to a struct , &User{}
-	RelatedModel     interface{} // related model,usually a pointer to a struct , &User{}
-	RelationTypeName Relations   // relation type name
-	FieldName        string      // field name in self model corresponding to this relation
-	*EloquentBuilder             // RelationBuilder
-
+	SelfModel          interface{} // parent/self model pointer,usually a pointer to a struct , &User{}
+	RelatedModel       interface{} // related model,usually a pointer to a struct , &User{}
+	RelationTypeName   Relations   // relation type name
+	FieldName          string      // field name in self model corresponding to this relation
+	*EloquentBuilder               // RelationBuilder
+	constraintWheres   [2]int      // where clauses added by AddConstraints,[from,to)
+	constraintBindings [2]int      // where bindings added by AddConstraints,[from,to)
 }
 
 func (r *Relation) GetEloquentBuilder() *EloquentBuilder {
 	return r.EloquentBuilder
 }
 
+/*
+addConstraints call AddConstraints of the relation and record the where clauses and bindings it adds,
+nothing is added if the relation builder has an error,like a related model that can't be parsed,the query methods return it
+*/
+func (r *Relation) addConstraints(relation RelationI) {
+	if r.Err != nil {
+		return
+	}
+	r.constraintWheres[0], r.constraintBindings[0] = len(r.Wheres), len(r.Bindings[TYPE_WHERE])
+	relation.AddConstraints()
+	r.constraintWheres[1], r.constraintBindings[1] = len(r.Wheres), len(r.Bindings[TYPE_WHERE])
+}
+
+/*
+removeConstraints rebuild the where clauses and bindings of the relation query without the ones added by AddConstraints,
+clauses added by the relation method after the constraints are kept
+*/
+func (r *Relation) removeConstraints() {
+	wheres := make([]Where, 0, len(r.Wheres))
+	wheres = append(wheres, r.Wheres[:r.constraintWheres[0]]...)
+	r.Wheres = append(wheres, r.Wheres[r.constraintWheres[1]:]...)
+	bindings := make([]interface{}, 0, len(r.Bindings[TYPE_WHERE]))
+	bindings = append(bindings, r.Bindings[TYPE_WHERE][:r.constraintBindings[0]]...)
+	r.Bindings[TYPE_WHERE] = append(bindings, r.Bindings[TYPE_WHERE][r.constraintBindings[1]:]...)
+	r.constraintWheres, r.constraintBindings = [2]int{}, [2]int{}
+}
+
 type RelationI interface {
 	AddEagerConstraints(models interface{})
 	AddConstraints()
@@ -78,10 +122,162 @@ func NewRelationBaseBuilder(related interface{}) *EloquentBuilder {
 	return NewEloquentBuilder(related)
 }
 func (r *Relation) GetSelfKey(key string) interface{} {
-	feild := GetParsedModel(r.SelfModel).FieldsByDbName[key]
+	feild := MustParseModel(r.SelfModel).FieldsByDbName[key]
 	return reflect.ValueOf(r.SelfModel).Elem().FieldByName(feild.Name).Interface()
 }
 
+/*
+selfEloquentModel get the *EloquentModel embedded in the self model,nil if it's not initialized
+*/
+func (r *Relation) selfEloquentModel() *EloquentModel {
+	parsed := MustParseModel(r.SelfModel)
+	if !parsed.IsEloquent {
+		return nil
+	}
+	e, _ := reflect.Indirect(reflect.ValueOf(r.SelfModel)).Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel)
+	return e
+}
+
+/*
+selfTx get the transaction the self model or the relation builder is using
+*/
+func (r *Relation) selfTx() *Transaction {
+	if e := r.selfEloquentModel(); e != nil && e.Tx != nil {
+		return e.Tx
+	}
+	if r.EloquentBuilder != nil {
+		return r.Tx
+	}
+	return nil
+}
+
+/*
+initSelfModel init the self model if needed and return its *EloquentModel
+*/
+func (r *Relation) initSelfModel() *EloquentModel {
+	Init(r.SelfModel)
+	return r.selfEloquentModel()
+}
+
+/*
+saveRelated save the related model with the self model's transaction,key is the self key the related model belongs to
+*/
+func (r *Relation) saveRelated(modelPointer interface{}, key interface{}) (Result, error) {
+	parsed := MustParseModel(modelPointer)
+	if !parsed.IsEloquent {
+		err := fmt.Errorf("model: %s is not an eloquent model", parsed.Name)
+		return Result{Error: err}, err
+	}
+	Init(modelPointer)
+	e := reflect.Indirect(reflect.ValueOf(modelPointer)).Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel)
+	e.Related = reflect.ValueOf(key)
+	if e.Tx == nil {
+		e.Tx = r.selfTx()
+		defer func() {
+			e.Tx = nil
+		}()
+	}
+	return e.Save()
+}
+
+/*
+saveMany save every model in models with save,models can be a slice of models/model pointers or a pointer to it
+*/
+func (r *Relation) saveMany(models interface{}, save func(modelPointer interface{}) (Result, error)) error {
+	slice := reflect.Indirect(reflect.ValueOf(models))
+	for i := 0; i < slice.Len(); i++ {
+		model := slice.Index(i)
+		if model.Kind() != reflect.Ptr {
+			model = model.Addr()
+		}
+		if _, err := save(model.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+newRelatedInstance create a new related model pointer filled with attributes
+*/
+func (r *Relation) newRelatedInstance(attributes map[string]interface{}) interface{} {
+	modelPointer := reflect.New(reflect.Indirect(reflect.ValueOf(r.RelatedModel)).Type()).Interface()
+	InitModel(modelPointer).Fill(attributes)
+	return modelPointer
+}
+
+/*
+createMany create a related model for each attributes with save
+*/
+func (r *Relation) createMany(records []map[string]interface{}, save func(modelPointer interface{}) (Result, error)) ([]interface{}, error) {
+	models := make([]interface{}, 0, len(records))
+	for _, attributes := range records {
+		model := r.newRelatedInstance(attributes)
+		if _, err := save(model); err != nil {
+			return models, err
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+/*
+setModelAttribute set the field mapped to column,value is converted to the field type or scanned if the field is a sql.Scanner,
+an error is returned if the column doesn't exist or the value can't be set
+*/
+func setModelAttribute(modelPointer interface{}, column string, value interface{}) error {
+	parsed := MustParseModel(modelPointer)
+	f, ok := parsed.FieldsByDbName[column]
+	if !ok {
+		return fmt.Errorf("column: %s not found in model: %s", column, parsed.Name)
+	}
+	field := reflect.Indirect(reflect.ValueOf(modelPointer)).Field(f.Index)
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(fmt.Sprint(value))
+		return nil
+	}
+	if v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("can not set %v to column: %s of model: %s", value, column, parsed.Name)
+}
+
+/*
+setSelfAttributes set columns of the self model with setModelAttribute and init it,
+the first error is recorded on its EloquentModel and returned by Save
+*/
+func (r *Relation) setSelfAttributes(columns []string, values ...interface{}) *EloquentModel {
+	var err error
+	for i, column := range columns {
+		if err = setModelAttribute(r.SelfModel, column, values[i]); err != nil {
+			break
+		}
+	}
+	e := r.initSelfModel()
+	e.addError(err)
+	return e
+}
+
 /*
 HasMany Define a one-to-many relationship.
 
@@ -106,7 +302,7 @@ func (m *EloquentModel) HasMany(selfModelPointer, relatedModelPointer interface{
 		RelatedColumn: relatedColumn,
 	}
 
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 	return &relation
 }
 
@@ -135,7 +331,7 @@ func (m *EloquentModel) BelongsTo(selfModelPointer, relatedModelPointer interfac
 		SelfColumn:    selfColumn,
 		RelatedColumn: relatedColumn,
 	}
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 
 	return &relation
 
@@ -171,12 +367,15 @@ func (m *EloquentModel) BelongsToMany(selfModelPointer, relatedModelPointer inte
 		SelfColumn:         selfModelColumn,
 		RelatedColumn:      relatedModelColumn,
 	}
-	relatedModel := GetParsedModel(relatedModelPointer)
+	if b.Err != nil {
+		return &relation
+	}
+	relatedModel := b.BaseModel
 	b.Join(relation.PivotTable, relation.PivotTable+"."+relation.PivotRelatedColumn, "=", relatedModel.Table+"."+relation.RelatedColumn)
 	b.Select(relatedModel.Table + "." + "*")
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotSelfColumn, OrmPivotAlias, relation.PivotSelfColumn))
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotRelatedColumn, OrmPivotAlias, relation.PivotRelatedColumn))
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 	return &relation
 
 }
@@ -206,7 +405,80 @@ func (m *EloquentModel) HasOne(selfModelPointer, relatedModelPointer interface{}
 		RelatedColumn: relatedColumn,
 		SelfColumn:    selfColumn,
 	}
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
+	return &relation
+}
+
+/*
+HasManyThrough Define a has-many-through relationship.
+
+let's say we have a Country model,a User model and a Post model,each country has many users and each user has many posts,
+country has a hasManyThrough relation with post through user,
+
+the country model(self) has an id column(localKey),the user model(through) has a country_id column(firstKey) and an id column(secondLocalKey),
+the post model(related) has a user_id column(secondKey),
+
+user's country_id = country's id , post's user_id = user's id , so the relation is defined as follows:
+
+	func (c *Country) PostsRelation() *goeloquent.HasManyThrough {
+		return c.HasManyThrough(c, &Post{}, &User{}, "country_id", "user_id", "id", "id")
+	}
+*/
+func (m *EloquentModel) HasManyThrough(selfModelPointer, relatedModelPointer, throughModelPointer interface{}, firstKey, secondKey, localKey, secondLocalKey string) *HasManyThrough {
+	b := NewRelationBaseBuilder(relatedModelPointer)
+	relation := HasManyThrough{
+		Relation: &Relation{
+			SelfModel:        selfModelPointer,
+			RelatedModel:     relatedModelPointer,
+			RelationTypeName: RelationHasManyThrough,
+			EloquentBuilder:  b,
+		},
+		ThroughParent:  throughModelPointer,
+		FarParent:      selfModelPointer,
+		FirstKey:       firstKey,
+		SecondKey:      secondKey,
+		LocalKey:       localKey,
+		SecondLocalKey: secondLocalKey,
+	}
+	if b.Err == nil {
+		relation.performJoin()
+	}
+	relation.addConstraints(&relation)
+	return &relation
+}
+
+/*
+HasOneThrough Define a has-one-through relationship.
+
+let's say we have a Mechanic model,a Car model and an Owner model,each mechanic has a car and each car has an owner,
+mechanic has a hasOneThrough relation with owner through car,
+
+cars.mechanic_id = mechanics.id , owners.car_id = cars.id , so the relation is defined as follows:
+
+	func (m *Mechanic) CarOwnerRelation() *goeloquent.HasOneThrough {
+		return m.HasOneThrough(m, &Owner{}, &Car{}, "mechanic_id", "car_id", "id", "id")
+	}
+*/
+func (m *EloquentModel) HasOneThrough(selfModelPointer, relatedModelPointer, throughModelPointer interface{}, firstKey, secondKey, localKey, secondLocalKey string) *HasOneThrough {
+	b := NewRelationBaseBuilder(relatedModelPointer)
+	relation := HasOneThrough{
+		Relation: &Relation{
+			SelfModel:        selfModelPointer,
+			RelatedModel:     relatedModelPointer,
+			RelationTypeName: RelationHasOneThrough,
+			EloquentBuilder:  b,
+		},
+		ThroughParent:  throughModelPointer,
+		FarParent:      selfModelPointer,
+		FirstKey:       firstKey,
+		SecondKey:      secondKey,
+		LocalKey:       localKey,
+		SecondLocalKey: secondLocalKey,
+	}
+	if b.Err == nil {
+		relation.hasManyThrough().performJoin()
+	}
+	relation.addConstraints(&relation)
 	return &relation
 }
 
@@ -239,7 +511,7 @@ func (m *EloquentModel) MorphTo(selfModelPointer interface{}, selfRelatedIdColum
 		SelfRelatedTypeColumn: selfRelatedTypeColumn,
 	}
 
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 
 	return &relation
 
@@ -271,13 +543,15 @@ func (m *EloquentModel) MorphOne(selfModelPointer, relatedModelPointer interface
 		SelfColumn:             selfColumn,
 		RelatedModelTypeColumn: relatedModelTypeColumn,
 	}
-	selfModel := GetParsedModel(selfModelPointer)
+	selfModel := MustParseModel(selfModelPointer)
 	if len(morphType) > 0 {
 		relation.RelatedModelTypeColumnValue = morphType[0]
 	} else {
-		relation.RelatedModelTypeColumnValue = GetMorphMap(selfModel.Name)
+		var err error
+		relation.RelatedModelTypeColumnValue, err = GetMorphMap(selfModel.Name)
+		b.AddError(err)
 	}
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 
 	return &relation
 
@@ -313,15 +587,17 @@ func (m *EloquentModel) MorphMany(selfModelPointer, relatedModelPointer interfac
 		SelfColumn:             selfColumn,
 		RelatedModelTypeColumn: relatedModelTypeColumn,
 	}
-	selfModel := GetParsedModel(selfModelPointer)
+	selfModel := MustParseModel(selfModelPointer)
 
 	if len(relatedModelTypeColumnValue) > 0 {
 		relation.RelatedModelTypeColumnValue = relatedModelTypeColumnValue[0]
 	} else {
-		relation.RelatedModelTypeColumnValue = GetMorphMap(selfModel.Name)
+		var err error
+		relation.RelatedModelTypeColumnValue, err = GetMorphMap(selfModel.Name)
+		b.AddError(err)
 	}
 
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 
 	return &relation
 
@@ -367,21 +643,23 @@ func (m *EloquentModel) MorphToMany(selfModelPointer, relatedModelPointer interf
 		RelatedIdColumn:      relatedIdColumn,
 		PivotRelatedIdColumn: pivotRelatedIdColumn,
 	}
-	relatedModel := GetParsedModel(relatedModelPointer)
-	selfModel := GetParsedModel(selfModelPointer)
+	if b.Err != nil {
+		return &relation
+	}
+	relatedModel := b.BaseModel
+	selfModel := MustParseModel(selfModelPointer)
 	b.Join(relation.PivotTable, relation.PivotTable+"."+relation.PivotRelatedIdColumn, "=", relatedModel.Table+"."+relation.RelatedIdColumn)
 	b.Select(relatedModel.Table + "." + "*")
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotRelatedIdColumn, OrmPivotAlias, relation.PivotRelatedIdColumn))
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotSelfIdColumn, OrmPivotAlias, relation.PivotSelfIdColumn))
-	var selfModelTypeColumnValue string
-
 	if len(morphType) > 0 {
-		selfModelTypeColumnValue = morphType[0]
+		relation.SelfModelTypeColumnValue = morphType[0]
 	} else {
-		selfModelTypeColumnValue = GetMorphMap(selfModel.Name)
+		var err error
+		relation.SelfModelTypeColumnValue, err = GetMorphMap(selfModel.Name)
+		b.AddError(err)
 	}
-	relation.SelfModelTypeColumnValue = selfModelTypeColumnValue
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 	return &relation
 
 }
@@ -421,12 +699,16 @@ func (m *EloquentModel) MorphByMany(selfModelPointer, relatedModelPointer interf
 		RelatedIdColumn:        relatedIdColumn,
 		PivotRelatedTypeColumn: pivotRelatedTypeColumn,
 	}
-
-	relatedModel := GetParsedModel(relatedModelPointer)
+	if b.Err != nil {
+		return &relation
+	}
+	relatedModel := b.BaseModel
 	if len(relatedModelTypeColumnValue) > 0 {
 		relation.RelatedModelTypeColumnValue = relatedModelTypeColumnValue[0]
 	} else {
-		relation.RelatedModelTypeColumnValue = GetMorphMap(relatedModel.Name)
+		var err error
+		relation.RelatedModelTypeColumnValue, err = GetMorphMap(relatedModel.Name)
+		b.AddError(err)
 	}
 	b.Join(relation.PivotTable, relation.PivotTable+"."+relation.PivotRelatedIdColumn, "=", relatedModel.Table+"."+relation.RelatedIdColumn)
 	b.Select(relatedModel.Table + "." + "*")
@@ -434,7 +716,7 @@ func (m *EloquentModel) MorphByMany(selfModelPointer, relatedModelPointer interf
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotSelfColumn, OrmPivotAlias, relation.PivotSelfColumn))
 	b.Select(fmt.Sprintf("%s.%s as %s%s", relation.PivotTable, relation.PivotRelatedTypeColumn, OrmPivotAlias, relation.PivotRelatedTypeColumn))
 
-	relation.AddConstraints()
+	relation.addConstraints(&relation)
 	return &relation
 
 }
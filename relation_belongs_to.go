@@ -12,7 +12,7 @@ type BelongsToRelation struct {
 }
 
 func (r *BelongsToRelation) AddEagerConstraints(parentModels interface{}) {
-	selfParsedModel := GetParsedModel(r.Relation.SelfModel)
+	selfParsedModel := MustParseModel(r.Relation.SelfModel)
 	selfRelatedKeyIndex := selfParsedModel.FieldsByDbName[r.SelfColumn].Index
 	parentModelSlice := reflect.Indirect(reflect.ValueOf(parentModels))
 	var parentModelRelatedKeys []interface{}
@@ -32,21 +32,21 @@ func (r *BelongsToRelation) AddEagerConstraints(parentModels interface{}) {
 		modelKey := model.Field(selfRelatedKeyIndex).Interface()
 		parentModelRelatedKeys = append(parentModelRelatedKeys, modelKey)
 	}
-	relatedParsedModel := GetParsedModel(r.RelatedModel)
+	relatedParsedModel := MustParseModel(r.RelatedModel)
 	//remove first where clause to simulate the Relation::noConstraints function in laravel
 	r.Wheres = r.Wheres[1:]
 	r.Bindings[TYPE_WHERE] = r.Bindings[TYPE_WHERE][1:]
 	r.Builder.WhereIn(relatedParsedModel.Table+"."+r.RelatedColumn, parentModelRelatedKeys)
 }
 func (r *BelongsToRelation) AddConstraints() {
-	relatedParsedModel := GetParsedModel(r.RelatedModel)
+	relatedParsedModel := MustParseModel(r.RelatedModel)
 	r.Builder.Where(relatedParsedModel.Table+"."+r.RelatedColumn, "=", r.GetSelfKey(r.SelfColumn))
 	r.Builder.WhereNotNull(relatedParsedModel.Table + "." + r.RelatedColumn)
 }
 
 func MatchBelongsTo(selfModels interface{}, relatedModelsValue reflect.Value, relation *BelongsToRelation) {
-	relatedModel := GetParsedModel(relation.RelatedModel)
-	selfModel := GetParsedModel(relation.SelfModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
+	selfModel := MustParseModel(relation.SelfModel)
 
 	groupedResultsMapType := reflect.MapOf(reflect.TypeOf(""), reflect.TypeOf(relation.Relation.RelatedModel))
 	groupedResults := reflect.MakeMap(groupedResultsMapType)
@@ -129,7 +129,7 @@ func (r *BelongsToRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuil
 	if selfQuery.FromTable == relatedQuery.FromTable {
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
-	return relatedQuery.Select(Raw(columns)).WhereColumn(fmt.Sprintf("%s.%s", GetParsedModel(r.RelatedModel).Table, r.RelatedColumn), "=", fmt.Sprintf("%s.%s", GetParsedModel(r.SelfModel).Table, r.SelfColumn))
+	return relatedQuery.Select(Raw(columns)).WhereColumn(fmt.Sprintf("%s.%s", MustParseModel(r.RelatedModel).Table, r.RelatedColumn), "=", fmt.Sprintf("%s.%s", MustParseModel(r.SelfModel).Table, r.SelfColumn))
 
 }
 
@@ -137,12 +137,32 @@ func (r *BelongsToRelation) GetRelationExistenceQueryForSelfRelation(relatedQuer
 	tableAlias := relatedQuery.FromTable.(string) + " as " + alias
 	relatedQuery.Select(Raw(columns)).From(tableAlias)
 
-	return relatedQuery.WhereColumn(fmt.Sprintf("%s.%s", GetParsedModel(r.RelatedModel).Table, r.RelatedColumn), "=", fmt.Sprintf("%s.%s", GetParsedModel(r.SelfModel).Table, r.SelfColumn))
+	return relatedQuery.WhereColumn(fmt.Sprintf("%s.%s", MustParseModel(r.RelatedModel).Table, r.RelatedColumn), "=", fmt.Sprintf("%s.%s", MustParseModel(r.SelfModel).Table, r.SelfColumn))
 }
 
 func (r *BelongsToRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *BelongsToRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Associate Associate the related model with the self model by setting the foreign key,call Save on the returned model to persist it.
+
+ 1. post.UserRelation().Associate(&user).Save()
+*/
+func (r *BelongsToRelation) Associate(modelPointer interface{}) *EloquentModel {
+	parsed := MustParseModel(modelPointer)
+	key := reflect.Indirect(reflect.ValueOf(modelPointer)).Field(parsed.FieldsByDbName[r.RelatedColumn].Index).Interface()
+	return r.setSelfAttributes([]string{r.SelfColumn}, key)
+}
+
+/*
+Dissociate Dissociate the related model from the self model by setting the foreign key to its zero value.
+
+ 1. post.UserRelation().Dissociate().Save()
+*/
+func (r *BelongsToRelation) Dissociate() *EloquentModel {
+	return r.setSelfAttributes([]string{r.SelfColumn}, nil)
 }
@@ -15,6 +15,8 @@ type BelongsToManyRelation struct {
 	PivotColumns       []string //extra columns to select in pivot table
 	PivotWheres        []Where  //extra where conditions in pivot table
 	WithTimestamps     bool
+	PivotCreatedAt     string //pivot created_at column filled with WithTimestamps,created_at if empty
+	PivotUpdatedAt     string //pivot updated_at column filled with WithTimestamps,updated_at if empty
 }
 
 func (r *BelongsToManyRelation) AddConstraints() {
@@ -22,7 +24,7 @@ func (r *BelongsToManyRelation) AddConstraints() {
 }
 
 func (r *BelongsToManyRelation) AddEagerConstraints(selfModels interface{}) {
-	selfParsedModel := GetParsedModel(r.SelfModel)
+	selfParsedModel := MustParseModel(r.SelfModel)
 	index := selfParsedModel.FieldsByDbName[r.SelfColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(selfModels))
 	var selfKeys []interface{}
@@ -48,8 +50,8 @@ func (r *BelongsToManyRelation) AddEagerConstraints(selfModels interface{}) {
 	r.Builder.WhereIn(r.PivotTable+"."+r.PivotSelfColumn, selfKeys)
 }
 func MatchBelongsToMany(selfModels interface{}, relatedModels reflect.Value, relation *BelongsToManyRelation) {
-	parsedRelatedModel := GetParsedModel(relation.RelatedModel)
-	selfParsedModel := GetParsedModel(relation.SelfModel)
+	parsedRelatedModel := MustParseModel(relation.RelatedModel)
+	selfParsedModel := MustParseModel(relation.SelfModel)
 
 	isPtr := selfParsedModel.FieldsByStructName[relation.Relation.FieldName].FieldType.Elem().Kind() == reflect.Ptr
 	var relatedType reflect.Type
@@ -140,7 +142,7 @@ func (r *BelongsToManyRelation) GetRelationExistenceQuery(relatedQuery *Eloquent
 		return r.GetRelationExistenceQueryForSelfJoin(relatedQuery, selfQuery, alias, columns)
 	}
 
-	return relatedQuery.Select(Raw(columns)).WhereColumn(GetParsedModel(r.SelfModel).Table+"."+r.SelfColumn, "=", r.PivotTable+"."+r.PivotSelfColumn)
+	return relatedQuery.Select(Raw(columns)).WhereColumn(MustParseModel(r.SelfModel).Table+"."+r.SelfColumn, "=", r.PivotTable+"."+r.PivotSelfColumn)
 
 }
 
@@ -148,11 +150,87 @@ func (r *BelongsToManyRelation) GetRelationExistenceQueryForSelfJoin(relatedQuer
 	relatedQuery.Select(Raw(columns))
 	tableAlias := relatedQuery.FromTable.(string) + " as " + OrmAggregateAlias
 	relatedQuery.From(tableAlias)
-	return relatedQuery.WhereColumn(GetParsedModel(r.SelfModel).Table+"."+r.SelfColumn, "=", r.PivotTable+"."+r.PivotSelfColumn)
+	return relatedQuery.WhereColumn(MustParseModel(r.SelfModel).Table+"."+r.SelfColumn, "=", r.PivotTable+"."+r.PivotSelfColumn)
 }
 func (r *BelongsToManyRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *BelongsToManyRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+func (r *BelongsToManyRelation) pivotTable() *pivotTable {
+	return &pivotTable{
+		relation:        r.Relation,
+		table:           r.PivotTable,
+		foreignPivotKey: r.PivotSelfColumn,
+		relatedPivotKey: r.PivotRelatedColumn,
+		parentKey:       r.SelfColumn,
+		withTimestamps:  r.WithTimestamps,
+		createdAt:       r.PivotCreatedAt,
+		updatedAt:       r.PivotUpdatedAt,
+	}
+}
+
+/*
+Attach Attach models to the self model by inserting pivot records,set WithTimestamps to true to fill pivot timestamps,
+the columns are created_at/updated_at unless PivotCreatedAt/PivotUpdatedAt are set.
+
+ 1. role.UsersRelation().Attach(1)
+    insert into `role_users` (`role_id`, `user_id`) values (?, ?)
+ 2. role.UsersRelation().Attach([]int{1, 2}, map[string]interface{}{"status": 1})
+    insert into `role_users` (`role_id`, `status`, `user_id`) values (?, ?, ?), (?, ?, ?)
+ 3. role.UsersRelation().Attach(map[int]map[string]interface{}{1: {"status": 1}, 2: {"status": 0}})
+*/
+func (r *BelongsToManyRelation) Attach(ids interface{}, attributes ...map[string]interface{}) (Result, error) {
+	return r.pivotTable().Attach(ids, attributes...)
+}
+
+/*
+Detach Detach models from the self model,detach all related models if no id is given.
+
+ 1. role.UsersRelation().Detach(1)
+    delete from `role_users` where `role_id` = ? and `user_id` in (?)
+ 2. role.UsersRelation().Detach([]int{1, 2})
+ 3. role.UsersRelation().Detach()
+    delete from `role_users` where `role_id` = ?
+*/
+func (r *BelongsToManyRelation) Detach(ids ...interface{}) (Result, error) {
+	return r.pivotTable().Detach(ids...)
+}
+
+/*
+Sync Sync the pivot table with the given ids,ids not in the given list are detached,
+the statements run in a new transaction if the self model doesn't have one.
+
+ 1. role.UsersRelation().Sync([]int{1, 2, 3})
+ 2. role.UsersRelation().Sync(map[int]map[string]interface{}{1: {"status": 1}, 2: {}})
+    existing pivot records are updated with the given attributes
+*/
+func (r *BelongsToManyRelation) Sync(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, true)
+}
+
+/*
+SyncWithoutDetaching Sync the pivot table with the given ids without detaching existing ones.
+*/
+func (r *BelongsToManyRelation) SyncWithoutDetaching(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, false)
+}
+
+/*
+Toggle Attach the given ids that are not attached and detach the given ids that are attached.
+*/
+func (r *BelongsToManyRelation) Toggle(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Toggle(ids)
+}
+
+/*
+UpdateExistingPivot Update an existing pivot record.
+
+ 1. role.UsersRelation().UpdateExistingPivot(1, map[string]interface{}{"status": 0})
+    update `role_users` set `status` = ? where `role_id` = ? and `user_id` = ?
+*/
+func (r *BelongsToManyRelation) UpdateExistingPivot(id interface{}, attributes map[string]interface{}) (Result, error) {
+	return r.pivotTable().UpdateExistingPivot(id, attributes)
 }
@@ -12,8 +12,8 @@ type HasManyRelation struct {
 }
 
 func (r *HasManyRelation) AddEagerConstraints(selfModels interface{}) {
-	relatedParsedModel := GetParsedModel(r.Relation.RelatedModel)
-	relatedSelfModel := GetParsedModel(r.Relation.SelfModel)
+	relatedParsedModel := MustParseModel(r.Relation.RelatedModel)
+	relatedSelfModel := MustParseModel(r.Relation.SelfModel)
 	selfColumnField := relatedSelfModel.FieldsByDbName[r.SelfColumn]
 	selfModelSlice := reflect.Indirect(reflect.ValueOf(selfModels))
 	var keys []interface{}
@@ -41,15 +41,15 @@ func (r *HasManyRelation) AddEagerConstraints(selfModels interface{}) {
 }
 
 func (r *HasManyRelation) AddConstraints() {
-	relatedParsedModel := GetParsedModel(r.Relation.RelatedModel)
+	relatedParsedModel := MustParseModel(r.Relation.RelatedModel)
 	r.Builder.Where(relatedParsedModel.Table+"."+r.RelatedColumn, "=", r.GetSelfKey(r.SelfColumn))
 	r.Builder.WhereNotNull(relatedParsedModel.Table + "." + r.RelatedColumn)
 }
 func MatchHasMany(models interface{}, related interface{}, relation *HasManyRelation) {
 	relatedModelsValue := related.(reflect.Value)
 	relatedModels := relatedModelsValue
-	relatedModel := GetParsedModel(relation.RelatedModel)
-	self := GetParsedModel(relation.SelfModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
+	self := MustParseModel(relation.SelfModel)
 	isPtr := self.FieldsByStructName[relation.Relation.FieldName].FieldType.Elem().Kind() == reflect.Ptr
 
 	var relatedType reflect.Type
@@ -134,22 +134,60 @@ func (r *HasManyRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuilde
 	if relatedQuery.FromTable.(string) == selfQuery.FromTable.(string) {
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedColumn)
 
 }
 
 func (r *HasManyRelation) GetRelationExistenceQueryForSelfRelation(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	relatedQuery.From(relatedQuery.FromTable.(string) + " as " + alias)
 	relatedQuery.Select(Raw(columns)).WhereColumn(r.SelfColumn, "=", r.RelatedColumn)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedColumn)
 }
 func (r *HasManyRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *HasManyRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Save Save the related model,the foreign key is set automatically.
+
+ 1. user.PostRelation().Save(&post)
+*/
+func (r *HasManyRelation) Save(modelPointer interface{}) (Result, error) {
+	key := r.GetSelfKey(r.SelfColumn)
+	if err := setModelAttribute(modelPointer, r.RelatedColumn, key); err != nil {
+		return Result{Error: err}, err
+	}
+	return r.saveRelated(modelPointer, key)
+}
+
+/*
+SaveMany Save a slice of related models.
+*/
+func (r *HasManyRelation) SaveMany(models interface{}) error {
+	return r.saveMany(models, r.Save)
+}
+
+/*
+Create Create a related model with attributes and save it,returns a pointer to the new model.
+
+ 1. user.PostRelation().Create(map[string]interface{}{"title": "foo"})
+*/
+func (r *HasManyRelation) Create(attributes map[string]interface{}) (interface{}, error) {
+	modelPointer := r.newRelatedInstance(attributes)
+	_, err := r.Save(modelPointer)
+	return modelPointer, err
+}
+
+/*
+CreateMany Create related models with a slice of attributes.
+*/
+func (r *HasManyRelation) CreateMany(records []map[string]interface{}) ([]interface{}, error) {
+	return r.createMany(records, r.Save)
 }
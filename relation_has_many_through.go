@@ -1,11 +1,124 @@
 package goeloquent
 
+import (
+	"fmt"
+	"reflect"
+)
+
 type HasManyThrough struct {
-	Relation
-	ThroughParent  interface{}
-	FarParent      interface{}
-	FirstKey       string
-	SecondKey      string
-	LocalKey       string
-	SecondLocalKey string
+	*Relation
+	ThroughParent  interface{} // intermediate model pointer,&User{}
+	FarParent      interface{} // self model pointer,&Country{}
+	FirstKey       string      // foreign key on the through model,users.country_id
+	SecondKey      string      // foreign key on the related model,posts.user_id
+	LocalKey       string      // local key on the self model,countries.id
+	SecondLocalKey string      // local key on the through model,users.id
+}
+
+/*
+performJoin join the through table,the first key is selected as an orm pivot column so results can be matched to self models,
+soft deleted through models are left out here so the filter is kept when the constraints are removed
+*/
+func (r *HasManyThrough) performJoin() {
+	throughParsed := MustParseModel(r.ThroughParent)
+	relatedParsed := MustParseModel(r.RelatedModel)
+	r.EloquentBuilder.Join(throughParsed.Table, throughParsed.Table+"."+r.SecondLocalKey, "=", relatedParsed.Table+"."+r.SecondKey)
+	r.EloquentBuilder.Select(relatedParsed.Table + "." + "*")
+	r.EloquentBuilder.Select(fmt.Sprintf("%s.%s as %s%s", throughParsed.Table, r.FirstKey, OrmPivotAlias, r.FirstKey))
+	if throughParsed.SoftDelete {
+		r.Builder.WhereNull(throughParsed.Table + "." + throughParsed.DeletedAt)
+	}
+}
+
+func (r *HasManyThrough) AddConstraints() {
+	throughParsed := MustParseModel(r.ThroughParent)
+	r.Builder.Where(throughParsed.Table+"."+r.FirstKey, "=", r.GetSelfKey(r.LocalKey))
+}
+
+func (r *HasManyThrough) AddEagerConstraints(models interface{}) {
+	index := MustParseModel(r.SelfModel).FieldsByDbName[r.LocalKey].Index
+	var keys []interface{}
+	eachModel(models, func(model reflect.Value) {
+		keys = append(keys, model.Field(index).Interface())
+	})
+	r.removeConstraints()
+	r.Builder.WhereIn(MustParseModel(r.ThroughParent).Table+"."+r.FirstKey, keys)
+}
+
+/*
+MatchHasManyThrough match the eagerly loaded results to their self models by the selected first key
+*/
+func MatchHasManyThrough(selfModels interface{}, relatedModels reflect.Value, relation *HasManyThrough) {
+	if !relatedModels.IsValid() || relatedModels.IsNil() {
+		return
+	}
+	selfParsed := MustParseModel(relation.SelfModel)
+	relationField := selfParsed.FieldsByStructName[relation.FieldName]
+	isPtr := relationField.FieldType.Elem().Kind() == reflect.Ptr
+	throughKey := OrmPivotAlias + relation.FirstKey
+
+	groupedResults := make(map[string]reflect.Value)
+	for i := 0; i < relatedModels.Len(); i++ {
+		related := relatedModels.Index(i)
+		groupKey := throughKeyOf(related, throughKey)
+		existed, ok := groupedResults[groupKey]
+		if !ok {
+			existed = reflect.MakeSlice(relationField.FieldType, 0, 1)
+		}
+		if isPtr {
+			existed = reflect.Append(existed, related.Addr())
+		} else {
+			existed = reflect.Append(existed, related)
+		}
+		groupedResults[groupKey] = existed
+	}
+
+	keyIndex := selfParsed.FieldsByDbName[relation.LocalKey].Index
+	eachModel(selfModels, func(model reflect.Value) {
+		if value, ok := groupedResults[fmt.Sprint(model.Field(keyIndex).Interface())]; ok {
+			model.Field(relationField.Index).Set(value)
+		}
+	})
+}
+
+func (r *HasManyThrough) GetRelationExistenceQuery(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
+	throughParsed := MustParseModel(r.ThroughParent)
+	selfParsed := MustParseModel(r.SelfModel)
+	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.LocalKey, "=", throughParsed.Table+"."+r.FirstKey)
+}
+func (r *HasManyThrough) GetSelf() *Model {
+	return MustParseModel(r.SelfModel)
+}
+func (r *HasManyThrough) GetRelated() *Model {
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+throughKeyOf get the through key selected as an orm pivot column from a related model
+*/
+func throughKeyOf(related reflect.Value, throughKey string) string {
+	parsed := MustParseModel(related.Type())
+	eloquentModelPtr := related.Field(parsed.EloquentModelFieldIndex)
+	pivotMap := eloquentModelPtr.Elem().Field(EloquentModelPivotFieldIndex).Interface().(map[string]interface{})
+	return fmt.Sprint(pivotMap[throughKey])
+}
+
+/*
+eachModel call fn with every model struct,models can be a pointer to a model,a pointer to a slice of models or *reflect.Value for nested relations
+*/
+func eachModel(models interface{}, fn func(model reflect.Value)) {
+	if rv, ok := models.(*reflect.Value); ok {
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+		return
+	}
+	value := reflect.Indirect(reflect.ValueOf(models))
+	if value.Kind() != reflect.Slice {
+		fn(value)
+		return
+	}
+	for i := 0; i < value.Len(); i++ {
+		fn(reflect.Indirect(value.Index(i)))
+	}
 }
@@ -12,7 +12,7 @@ type HasOneRelation struct {
 }
 
 func (r *HasOneRelation) AddEagerConstraints(models interface{}) {
-	relatedParsedModel := GetParsedModel(r.RelatedModel)
+	relatedParsedModel := MustParseModel(r.RelatedModel)
 	index := relatedParsedModel.FieldsByDbName[r.SelfColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	var keys []interface{}
@@ -38,7 +38,7 @@ func (r *HasOneRelation) AddEagerConstraints(models interface{}) {
 	r.Builder.WhereIn(relatedParsedModel.Table+"."+r.RelatedColumn, keys)
 }
 func (r *HasOneRelation) AddConstraints() {
-	relatedParsedModel := GetParsedModel(r.RelatedModel)
+	relatedParsedModel := MustParseModel(r.RelatedModel)
 	r.Builder.Where(relatedParsedModel.Table+"."+r.RelatedColumn, "=", r.GetSelfKey(r.SelfColumn))
 	r.Builder.WhereNotNull(relatedParsedModel.Table + "." + r.RelatedColumn)
 }
@@ -49,8 +49,8 @@ selfModels: the models that has the relation,usually a pointer of  slice of mode
 related: the related models ,reflect.Value of slice
 */
 func MatchHasOne(selfModels interface{}, relatedModelsValue reflect.Value, relation *HasOneRelation) {
-	relatedModel := GetParsedModel(relation.RelatedModel)
-	selfModel := GetParsedModel(relation.SelfModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
+	selfModel := MustParseModel(relation.SelfModel)
 
 	//make map[string]relatedModel , key is RelatedColumn,value is relatedModel pointer
 	groupedResultsMapType := reflect.MapOf(reflect.TypeOf(""), reflect.TypeOf(relation.RelatedModel))
@@ -130,23 +130,61 @@ func (r *HasOneRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuilder
 	if relatedQuery.FromTable.(string) == selfQuery.FromTable.(string) {
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
-	selfParsed := GetParsedModel(r.SelfModel)
-	relatedParsed := GetParsedModel(r.RelatedModel)
+	selfParsed := MustParseModel(r.SelfModel)
+	relatedParsed := MustParseModel(r.RelatedModel)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedColumn)
 
 }
 
 func (r *HasOneRelation) GetRelationExistenceQueryForSelfRelation(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
-	selfParsed := GetParsedModel(r.SelfModel)
-	relatedParsed := GetParsedModel(r.RelatedModel)
+	selfParsed := MustParseModel(r.SelfModel)
+	relatedParsed := MustParseModel(r.RelatedModel)
 	relatedQuery.From(relatedQuery.FromTable.(string) + " as " + alias)
 
 	relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedColumn)
 	return relatedQuery
 }
 func (r *HasOneRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *HasOneRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Save Save the related model,the foreign key is set automatically.
+
+ 1. user.PhoneRelation().Save(&phone)
+*/
+func (r *HasOneRelation) Save(modelPointer interface{}) (Result, error) {
+	key := r.GetSelfKey(r.SelfColumn)
+	if err := setModelAttribute(modelPointer, r.RelatedColumn, key); err != nil {
+		return Result{Error: err}, err
+	}
+	return r.saveRelated(modelPointer, key)
+}
+
+/*
+SaveMany Save a slice of related models.
+*/
+func (r *HasOneRelation) SaveMany(models interface{}) error {
+	return r.saveMany(models, r.Save)
+}
+
+/*
+Create Create a related model with attributes and save it,returns a pointer to the new model.
+
+ 1. user.PhoneRelation().Create(map[string]interface{}{"tel": "123456"})
+*/
+func (r *HasOneRelation) Create(attributes map[string]interface{}) (interface{}, error) {
+	modelPointer := r.newRelatedInstance(attributes)
+	_, err := r.Save(modelPointer)
+	return modelPointer, err
+}
+
+/*
+CreateMany Create related models with a slice of attributes.
+*/
+func (r *HasOneRelation) CreateMany(records []map[string]interface{}) ([]interface{}, error) {
+	return r.createMany(records, r.Save)
 }
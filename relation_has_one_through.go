@@ -1,11 +1,73 @@
 package goeloquent
 
+import (
+	"fmt"
+	"reflect"
+)
+
 type HasOneThrough struct {
-	Relation
-	ThroughParent  interface{}
-	FarParent      interface{}
-	FirstKey       string
-	SecondKey      string
-	LocalKey       string
-	SecondLocalKey string
+	*Relation
+	ThroughParent  interface{} // intermediate model pointer,&User{}
+	FarParent      interface{} // self model pointer,&Country{}
+	FirstKey       string      // foreign key on the through model,users.country_id
+	SecondKey      string      // foreign key on the related model,posts.user_id
+	LocalKey       string      // local key on the self model,countries.id
+	SecondLocalKey string      // local key on the through model,users.id
+}
+
+/*
+hasManyThrough HasOneThrough shares the same fields with HasManyThrough,only the matching differs
+*/
+func (r *HasOneThrough) hasManyThrough() *HasManyThrough {
+	return (*HasManyThrough)(r)
+}
+func (r *HasOneThrough) AddConstraints() {
+	r.hasManyThrough().AddConstraints()
+}
+func (r *HasOneThrough) AddEagerConstraints(models interface{}) {
+	r.hasManyThrough().AddEagerConstraints(models)
+}
+
+/*
+MatchHasOneThrough match the eagerly loaded results to their self models by the selected first key,only the first result is used
+*/
+func MatchHasOneThrough(selfModels interface{}, relatedModels reflect.Value, relation *HasOneThrough) {
+	if !relatedModels.IsValid() || relatedModels.IsNil() {
+		return
+	}
+	selfParsed := MustParseModel(relation.SelfModel)
+	relationField := selfParsed.FieldsByStructName[relation.FieldName]
+	isPtr := relationField.FieldType.Kind() == reflect.Ptr
+	throughKey := OrmPivotAlias + relation.FirstKey
+
+	groupedResults := make(map[string]reflect.Value)
+	for i := 0; i < relatedModels.Len(); i++ {
+		related := relatedModels.Index(i)
+		groupKey := throughKeyOf(related, throughKey)
+		if _, ok := groupedResults[groupKey]; ok {
+			continue
+		}
+		if isPtr {
+			groupedResults[groupKey] = related.Addr()
+		} else {
+			groupedResults[groupKey] = related
+		}
+	}
+
+	keyIndex := selfParsed.FieldsByDbName[relation.LocalKey].Index
+	eachModel(selfModels, func(model reflect.Value) {
+		if value, ok := groupedResults[fmt.Sprint(model.Field(keyIndex).Interface())]; ok {
+			model.Field(relationField.Index).Set(value)
+		}
+	})
+}
+
+func (r *HasOneThrough) GetRelationExistenceQuery(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
+	return r.hasManyThrough().GetRelationExistenceQuery(relatedQuery, selfQuery, alias, columns)
+}
+func (r *HasOneThrough) GetSelf() *Model {
+	return MustParseModel(r.SelfModel)
+}
+func (r *HasOneThrough) GetRelated() *Model {
+	return MustParseModel(r.RelatedModel)
 }
@@ -14,10 +14,13 @@ type MorphByManyRelation struct {
 	SelfIdColumn                string
 	RelatedIdColumn             string
 	RelatedModelTypeColumnValue string
+	WithTimestamps              bool
+	PivotCreatedAt              string //pivot created_at column filled with WithTimestamps,created_at if empty
+	PivotUpdatedAt              string //pivot updated_at column filled with WithTimestamps,updated_at if empty
 }
 
 func (r *MorphByManyRelation) AddEagerConstraints(models interface{}) {
-	parentParsedModel := GetParsedModel(r.SelfModel)
+	parentParsedModel := MustParseModel(r.SelfModel)
 	index := parentParsedModel.FieldsByDbName[r.SelfIdColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	var keys []interface{}
@@ -50,10 +53,10 @@ func (r *MorphByManyRelation) AddConstraints() {
 func MatchMorphByMany(models interface{}, related interface{}, relation *MorphByManyRelation) {
 	relatedModelsValue := related.(reflect.Value)
 	relatedModels := relatedModelsValue
-	relatedModel := GetParsedModel(relation.RelatedModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
 	relatedType := reflect.ValueOf(relation.Relation.RelatedModel).Elem().Type()
 
-	parent := GetParsedModel(relation.SelfModel)
+	parent := MustParseModel(relation.SelfModel)
 	relationFieldIsPtr := parent.FieldsByStructName[relation.Relation.FieldName].FieldType.Kind() == reflect.Ptr
 	var sliceEleIsptr bool
 	if relationFieldIsPtr {
@@ -159,9 +162,9 @@ func (r *MorphByManyRelation) GetRelationExistenceQuery(relatedQuery *EloquentBu
 	if selfQuery.FromTable == relatedQuery.FromTable {
 		return r.GetRelationExistenceQueryForSelfJoin(relatedQuery, selfQuery, alias, columns)
 	}
-	relatedQuery.Join(r.PivotTable, r.PivotTable+"."+r.PivotRelatedIdColumn, "=", GetParsedModel(r.RelatedModel).Table+"."+r.RelatedIdColumn)
+	relatedQuery.Join(r.PivotTable, r.PivotTable+"."+r.PivotRelatedIdColumn, "=", MustParseModel(r.RelatedModel).Table+"."+r.RelatedIdColumn)
 
-	return relatedQuery.Select(Raw(columns)).WhereColumn(GetParsedModel(r.RelatedModel).Table, "=", r.SelfIdColumn).Where(r.PivotTable+"."+r.PivotRelatedTypeColumn, "=", r.RelatedModelTypeColumnValue)
+	return relatedQuery.Select(Raw(columns)).WhereColumn(MustParseModel(r.RelatedModel).Table, "=", r.SelfIdColumn).Where(r.PivotTable+"."+r.PivotRelatedTypeColumn, "=", r.RelatedModelTypeColumnValue)
 
 }
 
@@ -169,13 +172,72 @@ func (r *MorphByManyRelation) GetRelationExistenceQueryForSelfJoin(relatedQuery
 	relatedQuery.Select(Raw(columns))
 	tableAlias := relatedQuery.FromTable.(string) + " as " + OrmAggregateAlias
 	relatedQuery.From(tableAlias)
-	relatedQuery.Join(tableAlias, tableAlias+"."+r.PivotRelatedIdColumn, "=", GetParsedModel(r.RelatedModel).Table+"."+r.RelatedIdColumn).Where(
+	relatedQuery.Join(tableAlias, tableAlias+"."+r.PivotRelatedIdColumn, "=", MustParseModel(r.RelatedModel).Table+"."+r.RelatedIdColumn).Where(
 		tableAlias+"."+r.PivotRelatedTypeColumn, "=", r.RelatedModelTypeColumnValue)
 	return relatedQuery.WhereColumn(tableAlias+"."+r.RelatedIdColumn, "=", r.SelfIdColumn)
 }
 func (r *MorphByManyRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *MorphByManyRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+func (r *MorphByManyRelation) pivotTable() *pivotTable {
+	return &pivotTable{
+		relation:        r.Relation,
+		table:           r.PivotTable,
+		foreignPivotKey: r.PivotSelfColumn,
+		relatedPivotKey: r.PivotRelatedIdColumn,
+		parentKey:       r.SelfIdColumn,
+		morphType:       r.PivotRelatedTypeColumn,
+		morphClass:      r.RelatedModelTypeColumnValue,
+		withTimestamps:  r.WithTimestamps,
+		createdAt:       r.PivotCreatedAt,
+		updatedAt:       r.PivotUpdatedAt,
+	}
+}
+
+/*
+Attach Attach models to the self model by inserting pivot records,see BelongsToManyRelation.Attach
+
+ 1. tag.PostsRelation().Attach([]int{1, 2})
+*/
+func (r *MorphByManyRelation) Attach(ids interface{}, attributes ...map[string]interface{}) (Result, error) {
+	return r.pivotTable().Attach(ids, attributes...)
+}
+
+/*
+Detach Detach models from the self model,detach all related models if no id is given.
+*/
+func (r *MorphByManyRelation) Detach(ids ...interface{}) (Result, error) {
+	return r.pivotTable().Detach(ids...)
+}
+
+/*
+Sync Sync the pivot table with the given ids,ids not in the given list are detached.
+*/
+func (r *MorphByManyRelation) Sync(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, true)
+}
+
+/*
+SyncWithoutDetaching Sync the pivot table with the given ids without detaching existing ones.
+*/
+func (r *MorphByManyRelation) SyncWithoutDetaching(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, false)
+}
+
+/*
+Toggle Attach the given ids that are not attached and detach the given ids that are attached.
+*/
+func (r *MorphByManyRelation) Toggle(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Toggle(ids)
+}
+
+/*
+UpdateExistingPivot Update an existing pivot record.
+*/
+func (r *MorphByManyRelation) UpdateExistingPivot(id interface{}, attributes map[string]interface{}) (Result, error) {
+	return r.pivotTable().UpdateExistingPivot(id, attributes)
 }
@@ -14,7 +14,7 @@ type MorphManyRelation struct {
 }
 
 func (r *MorphManyRelation) AddEagerConstraints(models interface{}) {
-	selfParsedModel := GetParsedModel(r.SelfModel)
+	selfParsedModel := MustParseModel(r.SelfModel)
 	index := selfParsedModel.FieldsByDbName[r.SelfColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	var keys []interface{}
@@ -47,10 +47,10 @@ func (r *MorphManyRelation) AddConstraints() {
 func MatchMorphMany(models interface{}, related interface{}, relation *MorphManyRelation) {
 	relatedModelsValue := related.(reflect.Value)
 	relatedModels := relatedModelsValue
-	relatedModel := GetParsedModel(relation.RelatedModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
 	relatedType := reflect.ValueOf(relation.Relation.RelatedModel).Elem().Type()
 
-	parent := GetParsedModel(relation.SelfModel)
+	parent := MustParseModel(relation.SelfModel)
 	relationFieldIsPtr := parent.FieldsByStructName[relation.Relation.FieldName].FieldType.Kind() == reflect.Ptr
 	var sliceEleIsptr bool
 	if relationFieldIsPtr {
@@ -156,22 +156,63 @@ func (r *MorphManyRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuil
 	if relatedQuery.FromTable.(string) == selfQuery.FromTable.(string) {
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedModelIdColumn).Where(relatedParsed.Table+"."+r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 
 }
 
 func (r *MorphManyRelation) GetRelationExistenceQueryForSelfRelation(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	relatedQuery.From(relatedQuery.FromTable.(string) + " as " + alias)
 	relatedQuery.Select(Raw(columns)).WhereColumn(r.SelfColumn, "=", r.RelatedModelIdColumn).Where(r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedModelIdColumn).Where(relatedParsed.Table+"."+r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 }
 func (r *MorphManyRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *MorphManyRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Save Save the related model,the foreign key and morph type are set automatically.
+
+ 1. post.ImageRelation().Save(&image)
+*/
+func (r *MorphManyRelation) Save(modelPointer interface{}) (Result, error) {
+	key := r.GetSelfKey(r.SelfColumn)
+	if err := setModelAttribute(modelPointer, r.RelatedModelIdColumn, key); err != nil {
+		return Result{Error: err}, err
+	}
+	if err := setModelAttribute(modelPointer, r.RelatedModelTypeColumn, r.RelatedModelTypeColumnValue); err != nil {
+		return Result{Error: err}, err
+	}
+	return r.saveRelated(modelPointer, key)
+}
+
+/*
+SaveMany Save a slice of related models.
+*/
+func (r *MorphManyRelation) SaveMany(models interface{}) error {
+	return r.saveMany(models, r.Save)
+}
+
+/*
+Create Create a related model with attributes and save it,returns a pointer to the new model.
+
+ 1. post.ImageRelation().Create(map[string]interface{}{"url": "foo.png"})
+*/
+func (r *MorphManyRelation) Create(attributes map[string]interface{}) (interface{}, error) {
+	modelPointer := r.newRelatedInstance(attributes)
+	_, err := r.Save(modelPointer)
+	return modelPointer, err
+}
+
+/*
+CreateMany Create related models with a slice of attributes.
+*/
+func (r *MorphManyRelation) CreateMany(records []map[string]interface{}) ([]interface{}, error) {
+	return r.createMany(records, r.Save)
 }
@@ -14,7 +14,7 @@ type MorphOneRelation struct {
 }
 
 func (r *MorphOneRelation) AddEagerConstraints(models interface{}) {
-	selfParsedModel := GetParsedModel(r.SelfModel)
+	selfParsedModel := MustParseModel(r.SelfModel)
 	index := selfParsedModel.FieldsByDbName[r.SelfColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	var keys []interface{}
@@ -40,7 +40,7 @@ func (r *MorphOneRelation) AddEagerConstraints(models interface{}) {
 	r.Builder.WhereIn(r.RelatedModelIdColumn, keys)
 }
 func (r *MorphOneRelation) AddConstraints() {
-	selfParsedModel := GetParsedModel(r.SelfModel)
+	selfParsedModel := MustParseModel(r.SelfModel)
 	selfDirect := reflect.Indirect(reflect.ValueOf(r.SelfModel))
 	r.Builder.Where(r.RelatedModelIdColumn, "=", selfDirect.Field(selfParsedModel.FieldsByDbName[r.SelfColumn].Index).Interface())
 	r.Builder.Where(r.RelatedModelTypeColumn, r.RelatedModelTypeColumnValue)
@@ -50,12 +50,12 @@ func (r *MorphOneRelation) AddConstraints() {
 func MatchMorphOne(models interface{}, related interface{}, relation *MorphOneRelation) {
 	relatedModelsValue := related.(reflect.Value)
 	relatedResults := relatedModelsValue
-	relatedModel := GetParsedModel(relation.RelatedModel)
+	relatedModel := MustParseModel(relation.RelatedModel)
 	relatedType := reflect.ValueOf(relation.Relation.RelatedModel).Elem().Type()
 	slice := reflect.MakeSlice(reflect.SliceOf(relatedType), 0, 1)
 	groupedResultsMapType := reflect.MapOf(reflect.TypeOf(""), reflect.TypeOf(slice))
 	groupedResults := reflect.MakeMap(groupedResultsMapType)
-	selfParsedModel := GetParsedModel(relation.SelfModel)
+	selfParsedModel := MustParseModel(relation.SelfModel)
 	isPtr := selfParsedModel.FieldsByStructName[relation.Relation.FieldName].FieldType.Kind() == reflect.Ptr
 	if !relatedResults.IsValid() || relatedResults.IsNil() {
 		return
@@ -157,21 +157,62 @@ func (r *MorphOneRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuild
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
 
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedModelIdColumn).Where(relatedParsed.Table+"."+r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 }
 
 func (r *MorphOneRelation) GetRelationExistenceQueryForSelfRelation(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	relatedQuery.From(relatedQuery.FromTable.(string) + " as " + alias)
 	relatedQuery.Select(Raw(columns)).WhereColumn(r.SelfColumn, "=", r.RelatedModelIdColumn).Where(r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 	return relatedQuery.Select(Raw(columns)).WhereColumn(selfParsed.Table+"."+r.SelfColumn, "=", relatedParsed.Table+"."+r.RelatedModelIdColumn).Where(relatedParsed.Table+"."+r.RelatedModelTypeColumn, "=", r.RelatedModelTypeColumnValue)
 }
 func (r *MorphOneRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *MorphOneRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Save Save the related model,the foreign key and morph type are set automatically.
+
+ 1. user.AvatarRelation().Save(&image)
+*/
+func (r *MorphOneRelation) Save(modelPointer interface{}) (Result, error) {
+	key := r.GetSelfKey(r.SelfColumn)
+	if err := setModelAttribute(modelPointer, r.RelatedModelIdColumn, key); err != nil {
+		return Result{Error: err}, err
+	}
+	if err := setModelAttribute(modelPointer, r.RelatedModelTypeColumn, r.RelatedModelTypeColumnValue); err != nil {
+		return Result{Error: err}, err
+	}
+	return r.saveRelated(modelPointer, key)
+}
+
+/*
+SaveMany Save a slice of related models.
+*/
+func (r *MorphOneRelation) SaveMany(models interface{}) error {
+	return r.saveMany(models, r.Save)
+}
+
+/*
+Create Create a related model with attributes and save it,returns a pointer to the new model.
+
+ 1. user.AvatarRelation().Create(map[string]interface{}{"url": "foo.png"})
+*/
+func (r *MorphOneRelation) Create(attributes map[string]interface{}) (interface{}, error) {
+	modelPointer := r.newRelatedInstance(attributes)
+	_, err := r.Save(modelPointer)
+	return modelPointer, err
+}
+
+/*
+CreateMany Create related models with a slice of attributes.
+*/
+func (r *MorphOneRelation) CreateMany(records []map[string]interface{}) ([]interface{}, error) {
+	return r.createMany(records, r.Save)
 }
@@ -21,7 +21,7 @@ func (r *MorphToRelation) AddEagerConstraints(models interface{}) {
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	groups := make(map[string][]interface{})
 	if modelSlice.Type().Kind() == reflect.Slice {
-		parsedSelfModel := GetParsedModel(modelSlice.Type().Elem())
+		parsedSelfModel := MustParseModel(modelSlice.Type().Elem())
 		typeColumnIndex := parsedSelfModel.FieldsByDbName[r.SelfRelatedTypeColumn].Index
 		idColumnIndex := parsedSelfModel.FieldsByDbName[r.SelfRelatedIdColumn].Index
 		for i := 0; i < modelSlice.Len(); i++ {
@@ -36,7 +36,7 @@ func (r *MorphToRelation) AddEagerConstraints(models interface{}) {
 			}
 		}
 	} else if ms, ok := models.(*reflect.Value); ok {
-		parsed := GetParsedModel(ms.Type().Elem())
+		parsed := MustParseModel(ms.Type().Elem())
 		typeColumnIndex := parsed.FieldsByDbName[r.SelfRelatedTypeColumn].Index
 		idColumnIndex := parsed.FieldsByDbName[r.SelfRelatedIdColumn].Index
 		for i := 0; i < ms.Len(); i++ {
@@ -52,7 +52,7 @@ func (r *MorphToRelation) AddEagerConstraints(models interface{}) {
 		}
 	} else {
 		model := modelSlice
-		parsed := GetParsedModel(modelSlice.Type())
+		parsed := MustParseModel(modelSlice.Type())
 		typeColumnIndex := parsed.FieldsByDbName[r.SelfRelatedTypeColumn].Index
 		idColumnIndex := parsed.FieldsByDbName[r.SelfRelatedIdColumn].Index
 		t := model.Field(typeColumnIndex).Interface().(string)
@@ -65,7 +65,12 @@ func (r *MorphToRelation) AddEagerConstraints(models interface{}) {
 func (r *MorphToRelation) AddConstraints() {
 	r.Builder.Where(r.RelatedModelIdColumn, "=", r.GetSelfKey(r.SelfRelatedIdColumn))
 	if key, ok := r.GetSelfKey(r.SelfRelatedTypeColumn).(string); ok && len(key) > 0 {
-		modelPointer := GetMorphDBMap(r.GetSelfKey(r.SelfRelatedTypeColumn).(string))
+		model, err := GetMorphDBMap(key)
+		if err != nil {
+			r.AddError(err)
+			return
+		}
+		modelPointer := reflect.New(model.Type()).Interface()
 		r.Relation.RelatedModel = modelPointer
 		r.EloquentBuilder.SetModel(modelPointer)
 	}
@@ -76,12 +81,12 @@ func MatchMorphTo(selfModels interface{}, releated reflect.Value, relation *Morp
 	//releatedResults := releated
 	//map[string][]reflect.Value{}
 	morphMapResults := make(map[string]map[string]reflect.Value) //map[relatedTypeString]map[relatedIdString]reflect.Value
-	parsedSelfModel := GetParsedModel(relation.SelfModel)
+	parsedSelfModel := MustParseModel(relation.SelfModel)
 	isPtr := parsedSelfModel.FieldsByStructName[relation.Relation.FieldName].FieldType.Kind() == reflect.Ptr
 
 	for morphType, relatedSliceValue := range releatedResults {
 		groupedResults := make(map[string]reflect.Value)
-		parsedMorphModel := GetParsedModel(GetMorphDBMap(morphType).Type())
+		parsedMorphModel := MustParseModel(MustGetMorphDBMap(morphType).Type())
 
 		for i := 0; i < relatedSliceValue.Len(); i++ {
 			morphModelValue := relatedSliceValue.Index(i)
@@ -150,7 +155,7 @@ func (r *MorphToRelation) GetRelationExistenceQuery(relatedQuery *EloquentBuilde
 	if selfQuery.FromTable == relatedQuery.FromTable {
 		return r.GetRelationExistenceQueryForSelfRelation(relatedQuery, selfQuery, alias, columns)
 	}
-	return relatedQuery.Select(Raw(columns)).WhereColumn(GetParsedModel(r.RelatedModel).Table, "=", r.SelfRelatedIdColumn)
+	return relatedQuery.Select(Raw(columns)).WhereColumn(MustParseModel(r.RelatedModel).Table, "=", r.SelfRelatedIdColumn)
 
 }
 
@@ -161,8 +166,32 @@ func (r *MorphToRelation) GetRelationExistenceQueryForSelfRelation(relatedQuery
 	return relatedQuery.WhereColumn(tableAlias+"."+r.RelatedModelIdColumn, "=", r.SelfRelatedIdColumn)
 }
 func (r *MorphToRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *MorphToRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+/*
+Associate Associate the related model with the self model by setting the morph id and morph type,call Save on the returned model to persist it.
+
+ 1. image.ImageableRelation().Associate(&post).Save()
+*/
+func (r *MorphToRelation) Associate(modelPointer interface{}) *EloquentModel {
+	parsed := MustParseModel(modelPointer)
+	key := reflect.Indirect(reflect.ValueOf(modelPointer)).Field(parsed.FieldsByDbName[r.RelatedModelIdColumn].Index).Interface()
+	morphType, err := GetMorphMap(parsed.Name)
+	if err != nil {
+		e := r.initSelfModel()
+		e.addError(err)
+		return e
+	}
+	return r.setSelfAttributes([]string{r.SelfRelatedIdColumn, r.SelfRelatedTypeColumn}, key, morphType)
+}
+
+/*
+Dissociate Dissociate the related model from the self model by setting the morph id and morph type to their zero values.
+*/
+func (r *MorphToRelation) Dissociate() *EloquentModel {
+	return r.setSelfAttributes([]string{r.SelfRelatedIdColumn, r.SelfRelatedTypeColumn}, nil, nil)
 }
@@ -14,10 +14,13 @@ type MorphToManyRelation struct {
 	SelfIdColumn             string
 	RelatedIdColumn          string
 	SelfModelTypeColumnValue string
+	WithTimestamps           bool
+	PivotCreatedAt           string //pivot created_at column filled with WithTimestamps,created_at if empty
+	PivotUpdatedAt           string //pivot updated_at column filled with WithTimestamps,updated_at if empty
 }
 
 func (r *MorphToManyRelation) AddEagerConstraints(models interface{}) {
-	parentParsedModel := GetParsedModel(r.SelfModel)
+	parentParsedModel := MustParseModel(r.SelfModel)
 	index := parentParsedModel.FieldsByDbName[r.SelfIdColumn].Index
 	modelSlice := reflect.Indirect(reflect.ValueOf(models))
 	var keys []interface{}
@@ -44,15 +47,15 @@ func (r *MorphToManyRelation) AddEagerConstraints(models interface{}) {
 
 }
 func (r *MorphToManyRelation) AddConstraints() {
-	selfModel := GetParsedModel(r.SelfModel)
+	selfModel := MustParseModel(r.SelfModel)
 	selfDirect := reflect.Indirect(reflect.ValueOf(r.SelfModel))
 	r.Builder.Where(r.PivotSelfIdColumn, selfDirect.Field(selfModel.FieldsByDbName[r.SelfIdColumn].Index).Interface())
 	r.Builder.Where(r.PivotSelfTypeColumn, r.SelfModelTypeColumnValue)
 }
 func MatchMorphToMany(selfModels interface{}, related reflect.Value, relation *MorphToManyRelation) {
 	relatedResults := related
-	relatedParsedModel := GetParsedModel(relation.RelatedModel)
-	selfParsedModel := GetParsedModel(relation.SelfModel)
+	relatedParsedModel := MustParseModel(relation.RelatedModel)
+	selfParsedModel := MustParseModel(relation.SelfModel)
 
 	isPtr := selfParsedModel.FieldsByStructName[relation.Relation.FieldName].FieldType.Elem().Kind() == reflect.Ptr
 	var relatedType reflect.Type
@@ -145,8 +148,8 @@ func (r *MorphToManyRelation) GetRelationExistenceQuery(relatedQuery *EloquentBu
 	if selfQuery.FromTable == relatedQuery.FromTable {
 		return r.GetRelationExistenceQueryForSelfJoin(relatedQuery, selfQuery, alias, columns)
 	}
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	return relatedQuery.Select(Raw(columns)).
 		WhereColumn(relatedParsed.Table+"."+r.RelatedIdColumn, "=", selfParsed.Table+"."+r.SelfIdColumn).
 		Where(r.PivotTable+"."+r.PivotSelfTypeColumn, "=", r.SelfModelTypeColumnValue)
@@ -154,16 +157,75 @@ func (r *MorphToManyRelation) GetRelationExistenceQuery(relatedQuery *EloquentBu
 }
 
 func (r *MorphToManyRelation) GetRelationExistenceQueryForSelfJoin(relatedQuery *EloquentBuilder, selfQuery *EloquentBuilder, alias string, columns string) *EloquentBuilder {
-	relatedParsed := GetParsedModel(r.Relation.RelatedModel)
-	selfParsed := GetParsedModel(r.Relation.SelfModel)
+	relatedParsed := MustParseModel(r.Relation.RelatedModel)
+	selfParsed := MustParseModel(r.Relation.SelfModel)
 	relatedQuery.Select(Raw(columns))
 	tableAlias := relatedQuery.FromTable.(string) + " as " + OrmAggregateAlias
 	relatedQuery.From(tableAlias)
 	return relatedQuery.WhereColumn(relatedParsed.Table+"."+r.RelatedIdColumn, "=", selfParsed.Table+"."+r.SelfIdColumn)
 }
 func (r *MorphToManyRelation) GetSelf() *Model {
-	return GetParsedModel(r.SelfModel)
+	return MustParseModel(r.SelfModel)
 }
 func (r *MorphToManyRelation) GetRelated() *Model {
-	return GetParsedModel(r.RelatedModel)
+	return MustParseModel(r.RelatedModel)
+}
+
+func (r *MorphToManyRelation) pivotTable() *pivotTable {
+	return &pivotTable{
+		relation:        r.Relation,
+		table:           r.PivotTable,
+		foreignPivotKey: r.PivotSelfIdColumn,
+		relatedPivotKey: r.PivotRelatedIdColumn,
+		parentKey:       r.SelfIdColumn,
+		morphType:       r.PivotSelfTypeColumn,
+		morphClass:      r.SelfModelTypeColumnValue,
+		withTimestamps:  r.WithTimestamps,
+		createdAt:       r.PivotCreatedAt,
+		updatedAt:       r.PivotUpdatedAt,
+	}
+}
+
+/*
+Attach Attach models to the self model by inserting pivot records,see BelongsToManyRelation.Attach
+
+ 1. post.TagsRelation().Attach([]int{1, 2})
+*/
+func (r *MorphToManyRelation) Attach(ids interface{}, attributes ...map[string]interface{}) (Result, error) {
+	return r.pivotTable().Attach(ids, attributes...)
+}
+
+/*
+Detach Detach models from the self model,detach all related models if no id is given.
+*/
+func (r *MorphToManyRelation) Detach(ids ...interface{}) (Result, error) {
+	return r.pivotTable().Detach(ids...)
+}
+
+/*
+Sync Sync the pivot table with the given ids,ids not in the given list are detached.
+*/
+func (r *MorphToManyRelation) Sync(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, true)
+}
+
+/*
+SyncWithoutDetaching Sync the pivot table with the given ids without detaching existing ones.
+*/
+func (r *MorphToManyRelation) SyncWithoutDetaching(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Sync(ids, false)
+}
+
+/*
+Toggle Attach the given ids that are not attached and detach the given ids that are attached.
+*/
+func (r *MorphToManyRelation) Toggle(ids interface{}) (SyncResult, error) {
+	return r.pivotTable().Toggle(ids)
+}
+
+/*
+UpdateExistingPivot Update an existing pivot record.
+*/
+func (r *MorphToManyRelation) UpdateExistingPivot(id interface{}, attributes map[string]interface{}) (Result, error) {
+	return r.pivotTable().UpdateExistingPivot(id, attributes)
 }
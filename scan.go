@@ -3,14 +3,21 @@ package goeloquent
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 )
 
+/*
+ScanAll scan rows into dest,errors are returned in Result.Error,a panic while scanning is recovered as an error too
+*/
 func ScanAll(rows *sql.Rows, dest interface{}, mapping map[string]interface{}) (result Result) {
 	defer func() {
 		if r := recover(); r != nil {
-			result.Error = errors.New("scan error:" + r.(string))
+			result.Error = fmt.Errorf("scan error:%v", r)
+		} else if result.Error == nil {
+			//errors met while iterating rows
+			result.Error = rows.Err()
 		}
 	}()
 	v := reflect.ValueOf(dest)
@@ -44,7 +51,8 @@ func ScanAll(rows *sql.Rows, dest interface{}, mapping map[string]interface{}) (
 			result.Count++
 			err := rows.Scan(dest)
 			if err != nil {
-				panic(err.Error())
+				result.Error = err
+				return
 			}
 		}
 	}
@@ -71,7 +79,8 @@ func scanMapSlice(rows *sql.Rows, dest interface{}, mapping map[string]interface
 		}
 		err := rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		for i, column := range columns {
 			element[column] = reflect.ValueOf(scanArgs[i]).Elem().Interface()
@@ -87,7 +96,11 @@ func scanStructSlice(rows *sql.Rows, dest interface{}, mapping map[string]interf
 	slice := realDest.Type()
 	sliceItem := slice.Elem()
 	itemIsPtr := sliceItem.Kind() == reflect.Ptr
-	model := GetParsedModel(dest)
+	model, err := ParseModel(dest)
+	if err != nil {
+		result.Error = err
+		return
+	}
 	scanArgs := make([]interface{}, len(columns))
 
 	var needProcessPivot bool
@@ -106,7 +119,7 @@ func scanStructSlice(rows *sql.Rows, dest interface{}, mapping map[string]interf
 		}
 		for i, column := range columns {
 			if f, ok := model.FieldsByDbName[column]; ok {
-				scanArgs[i] = v.Field(f.Index).Addr().Interface()
+				scanArgs[i] = scanArg(f, v.Field(f.Index))
 			} else if strings.Contains(column, PivotAlias) {
 				//process  withpivot column
 				needProcessPivot = true
@@ -149,9 +162,10 @@ func scanStructSlice(rows *sql.Rows, dest interface{}, mapping map[string]interf
 				scanArgs[i] = new(interface{})
 			}
 		}
-		err := rows.Scan(scanArgs...)
+		err = rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		if needProcessPivot || needProcessAggregate {
 			t := make(map[string]interface{}, 2)
@@ -190,7 +204,11 @@ func scanRelations(rows *sql.Rows, dest interface{}, mapping map[string]interfac
 	slice := destValue.Type()
 	sliceItem := slice.Elem()
 	//itemIsPtr := base.Kind() == reflect.Ptr
-	model := GetParsedModel(sliceItem)
+	model, err := ParseModel(sliceItem)
+	if err != nil {
+		result.Error = err
+		return
+	}
 	scanArgs := make([]interface{}, len(columns))
 	vp := reflect.New(sliceItem)
 	v := reflect.Indirect(vp)
@@ -202,7 +220,7 @@ func scanRelations(rows *sql.Rows, dest interface{}, mapping map[string]interfac
 		result.Count++
 		for i, column := range columns {
 			if f, ok := model.FieldsByDbName[column]; ok {
-				scanArgs[i] = v.Field(f.Index).Addr().Interface()
+				scanArgs[i] = scanArg(f, v.Field(f.Index))
 			} else if strings.Contains(column, PivotAlias) {
 				//process user's withpivot column
 				needProcessPivot = true
@@ -229,9 +247,10 @@ func scanRelations(rows *sql.Rows, dest interface{}, mapping map[string]interfac
 				scanArgs[i] = new(interface{})
 			}
 		}
-		err := rows.Scan(scanArgs...)
+		err = rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		if needProcessPivot || needProcessAggregate {
 			t := make(map[string]interface{}, 2)
@@ -262,7 +281,11 @@ func scanRelations(rows *sql.Rows, dest interface{}, mapping map[string]interfac
 
 func scanStruct(rows *sql.Rows, dest interface{}, mapping map[string]interface{}) (result Result) {
 	realDest := reflect.Indirect(reflect.ValueOf(dest))
-	model := GetParsedModel(dest)
+	model, err := ParseModel(dest)
+	if err != nil {
+		result.Error = err
+		return
+	}
 	columns, _ := rows.Columns()
 	scanArgs := make([]interface{}, len(columns))
 	vp := reflect.New(realDest.Type())
@@ -278,7 +301,7 @@ func scanStruct(rows *sql.Rows, dest interface{}, mapping map[string]interface{}
 				if t, ok := mapping[column]; ok {
 					scanArgs[i] = reflect.New(reflect.TypeOf(t)).Interface()
 				} else {
-					scanArgs[i] = v.Field(f.Index).Addr().Interface()
+					scanArgs[i] = scanArg(f, v.Field(f.Index))
 				}
 			} else if strings.Contains(column, PivotAlias) {
 				//process user's withpivot column
@@ -322,9 +345,10 @@ func scanStruct(rows *sql.Rows, dest interface{}, mapping map[string]interface{}
 				scanArgs[i] = new(interface{})
 			}
 		}
-		err := rows.Scan(scanArgs...)
+		err = rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		if needProcessPivot || needProcessAggregate {
 			t := make(map[string]interface{}, 2)
@@ -375,7 +399,8 @@ func scanMap(rows *sql.Rows, dest interface{}, mapping map[string]interface{}) (
 		}
 		err := rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		for i, column := range columns {
 			//If elem is the zero Value, SetMapIndex deletes the key from the map.
@@ -393,7 +418,8 @@ func scanValues(rows *sql.Rows, dest interface{}) (result Result) {
 		scanArgs[0] = reflect.New(realDest.Type().Elem()).Interface()
 		err := rows.Scan(scanArgs...)
 		if err != nil {
-			panic(err.Error())
+			result.Error = err
+			return
 		}
 		realDest.Set(reflect.Append(realDest, reflect.ValueOf(reflect.ValueOf(scanArgs[0]).Elem().Interface())))
 	}
@@ -420,7 +446,7 @@ func prepareScanArgs(columns []string, dest interface{}, mapping map[string]inte
 			if t, ok := mapping[column]; ok {
 				scanArgs[i] = reflect.New(reflect.TypeOf(t)).Interface()
 			} else {
-				scanArgs[i] = realDest.Field(f.Index).Addr().Interface()
+				scanArgs[i] = scanArg(f, realDest.Field(f.Index))
 			}
 		} else if strings.Contains(column, OrmPivotAlias) {
 			//process orm pivot keys as string
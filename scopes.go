@@ -1 +1,122 @@
 package goeloquent
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const LocalScopePrefix = "Scope"
+
+/*
+IGlobalScopes models implement this to register their global scopes when the model is parsed,scopes are merged with the builtin ones like the soft delete scope
+
+	func (u *User) EloquentAddGlobalScopes() map[string]goeloquent.ScopeFunc {
+		return map[string]goeloquent.ScopeFunc{
+			"Active": func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+				return builder.Where("status", 1)
+			},
+		}
+	}
+*/
+type IGlobalScopes interface {
+	EloquentAddGlobalScopes() map[string]ScopeFunc
+}
+
+/*
+AddGlobalScope register a global scope for a model at boot,so packages can share query fragments without touching the model
+
+ 1. AddGlobalScope(&User{}, "Tenant", func(builder *EloquentBuilder) *EloquentBuilder { return builder.Where("tenant_id", 1) })
+
+DB.Model(&User{}).Get(&users) select * from `users` where `tenant_id` = ?
+*/
+func AddGlobalScope(model interface{}, name string, scope ScopeFunc) {
+	parsed := MustParseModel(model)
+	parsed.GlobalScopes[name] = scope
+}
+
+/*
+RemoveGlobalScope unregister a global scope of a model,use WithOutGlobalScopes to remove it for a single query
+*/
+func RemoveGlobalScope(model interface{}, name string) {
+	delete(MustParseModel(model).GlobalScopes, name)
+}
+
+/*
+parseLocalScope record a model method like ScopeActive(builder *EloquentBuilder, args ...interface{}) as local scope Active
+*/
+func (m *Model) parseLocalScope(method reflect.Method, value reflect.Value) {
+	if !strings.HasPrefix(method.Name, LocalScopePrefix) || len(method.Name) == len(LocalScopePrefix) {
+		return
+	}
+	//method type includes the receiver
+	if method.Type.NumIn() < 2 || method.Type.In(1) != reflect.TypeOf(&EloquentBuilder{}) {
+		return
+	}
+	m.LocalScopes[strings.TrimPrefix(method.Name, LocalScopePrefix)] = value
+}
+
+/*
+Scope apply a local scope declared on the model,a method named Scope{Name} receives the builder and args,
+like func (u *User) ScopeOfType(builder *EloquentBuilder, t string) *EloquentBuilder,
+an unknown scope or wrong arguments make the query methods return an error
+
+ 1. DB.Model(&User{}).Scope("Active") select * from `users` where `status` = ?
+ 2. DB.Model(&User{}).Scope("Active").Scope("OfType", "admin") select * from `users` where `status` = ? and `type` = ?
+*/
+func (b *EloquentBuilder) Scope(name string, args ...interface{}) *EloquentBuilder {
+	if b.BaseModel == nil {
+		b.AddError(fmt.Errorf("scope %s need a model", name))
+		return b
+	}
+	method, ok := b.BaseModel.LocalScopes[name]
+	if !ok {
+		b.AddError(fmt.Errorf("scope %s on model %s not found", name, b.BaseModel.Name))
+		return b
+	}
+	methodType := method.Type()
+	in := []reflect.Value{reflect.ValueOf(b)}
+	for i, arg := range args {
+		var paramType reflect.Type
+		if methodType.IsVariadic() && i+1 >= methodType.NumIn()-1 {
+			paramType = methodType.In(methodType.NumIn() - 1).Elem()
+		} else if i+1 < methodType.NumIn() {
+			paramType = methodType.In(i + 1)
+		} else {
+			b.AddError(fmt.Errorf("too many arguments for scope %s on model %s", name, b.BaseModel.Name))
+			return b
+		}
+		if arg == nil {
+			in = append(in, reflect.Zero(paramType))
+			continue
+		}
+		v := reflect.ValueOf(arg)
+		if !v.Type().AssignableTo(paramType) {
+			if !v.Type().ConvertibleTo(paramType) {
+				b.AddError(fmt.Errorf("argument %d of scope %s on model %s should be %s,got %s", i, name, b.BaseModel.Name, paramType, v.Type()))
+				return b
+			}
+			v = v.Convert(paramType)
+		}
+		in = append(in, v)
+	}
+	if len(in) < methodType.NumIn() && !methodType.IsVariadic() {
+		b.AddError(fmt.Errorf("not enough arguments for scope %s on model %s", name, b.BaseModel.Name))
+		return b
+	}
+	method.Call(in)
+	return b
+}
+
+/*
+sortedGlobalScopes global scope names in a stable order so the generated sql is the same every time
+*/
+func (m *Model) sortedGlobalScopes() []string {
+	names := make([]string, 0, len(m.GlobalScopes))
+	for name := range m.GlobalScopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
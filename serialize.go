@@ -0,0 +1,189 @@
+package goeloquent
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	AccessorPrefix  = "Get"
+	MutatorPrefix   = "Set"
+	AttributeSuffix = "Attribute"
+)
+
+/*
+ToStudlyCase convert a snake case string to studly case,full_name => FullName
+*/
+func ToStudlyCase(str string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(str, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+/*
+parseAttributeMethod record model methods like GetFullNameAttribute() interface{} as accessors and SetNameAttribute(value interface{}) as mutators
+*/
+func (m *Model) parseAttributeMethod(method reflect.Method) {
+	if !strings.HasSuffix(method.Name, AttributeSuffix) {
+		return
+	}
+	name := strings.TrimSuffix(method.Name, AttributeSuffix)
+	//method type includes the receiver
+	if strings.HasPrefix(name, AccessorPrefix) && len(name) > len(AccessorPrefix) && method.Type.NumIn() == 1 && method.Type.NumOut() > 0 {
+		m.Accessors[strings.TrimPrefix(name, AccessorPrefix)] = method
+	}
+	if strings.HasPrefix(name, MutatorPrefix) && len(name) > len(MutatorPrefix) && method.Type.NumIn() == 2 {
+		m.Mutators[strings.TrimPrefix(name, MutatorPrefix)] = method
+	}
+}
+
+/*
+callMutator set a field by its Set{Field}Attribute mutator,returns false if the model doesn't have one,
+an error is returned if the value can't be passed to the mutator
+*/
+func (m *EloquentModel) callMutator(parsed *Model, field *Field, value interface{}) (bool, error) {
+	mutator, ok := parsed.Mutators[field.Name]
+	if !ok {
+		return false, nil
+	}
+	paramType := mutator.Type.In(1)
+	var param reflect.Value
+	if value == nil {
+		param = reflect.Zero(paramType)
+	} else {
+		param = reflect.ValueOf(value)
+		if !param.Type().AssignableTo(paramType) {
+			if !param.Type().ConvertibleTo(paramType) {
+				return true, fmt.Errorf("mutator %s%s%s on model %s needs %s,got %s", MutatorPrefix, field.Name, AttributeSuffix, parsed.Name, paramType, param.Type())
+			}
+			param = param.Convert(paramType)
+		}
+	}
+	mutator.Func.Call([]reflect.Value{m.ModelPointer, param})
+	return true, nil
+}
+
+/*
+ToMap convert the model to a map for serialization,keys are database column names,
+Get{Field}Attribute accessors,appended attributes,loaded relations,aggregates and pivot attributes are included,
+keys in EloquentGetHidden are removed and only keys in EloquentGetVisible are kept if it's not empty
+
+ 1. user.ToMap() map[string]interface{}{"id": 1, "name": "john", "full_name": "john doe", "posts": []map[string]interface{}{...}}
+*/
+func (m *EloquentModel) ToMap() map[string]interface{} {
+	return m.toMap(m.ModelPointer)
+}
+
+/*
+ToJSON convert the model to json with ToMap
+*/
+func (m *EloquentModel) ToJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *EloquentModel) toMap(pointer reflect.Value) map[string]interface{} {
+	model := reflect.Indirect(pointer)
+	parsed := MustParseModel(model.Type())
+	result := make(map[string]interface{}, len(parsed.FieldsByDbName))
+
+	for column, field := range parsed.FieldsByDbName {
+		if accessor, ok := parsed.Accessors[field.Name]; ok {
+			result[column] = accessor.Func.Call([]reflect.Value{pointer})[0].Interface()
+			continue
+		}
+		v := model.Field(field.Index).Interface()
+		//keep casted values like json structs as they are
+		if valuer, ok := v.(driver.Valuer); ok && field.Cast == nil {
+			v, _ = valuer.Value()
+		}
+		result[column] = v
+	}
+	for name := range parsed.Appends {
+		//accessors of appended attributes are checked when the model is parsed
+		result[name] = parsed.Accessors[ToStudlyCase(name)].Func.Call([]reflect.Value{pointer})[0].Interface()
+	}
+	for fieldName := range parsed.Relations {
+		relation := model.Field(parsed.FieldsByStructName[fieldName].Index)
+		if relation.IsZero() {
+			continue
+		}
+		result[ToSnakeCase(fieldName)] = serializeRelation(relation)
+	}
+	for name, value := range m.WithAggregates {
+		result[ToSnakeCase(name)] = value
+	}
+	if len(m.Pivot) > 0 {
+		pivot := make(map[string]interface{}, len(m.Pivot))
+		for column, value := range m.Pivot {
+			//orm pivot keys are used to match relations
+			if !strings.HasPrefix(column, OrmPivotAlias) {
+				pivot[column] = value
+			}
+		}
+		if len(pivot) > 0 {
+			result["pivot"] = pivot
+		}
+	}
+
+	if len(parsed.Visible) > 0 {
+		for key := range result {
+			if _, ok := parsed.Visible[key]; !ok {
+				delete(result, key)
+			}
+		}
+	}
+	for key := range parsed.Hidden {
+		delete(result, key)
+	}
+	return result
+}
+
+/*
+serializeRelation convert a loaded relation,a model,a pointer to a model or a slice of them
+*/
+func serializeRelation(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return serializeRelation(value.Elem())
+	case reflect.Slice:
+		items := make([]map[string]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item := reflect.Indirect(value.Index(i))
+			if item.IsValid() {
+				items = append(items, serializeModel(item))
+			}
+		}
+		return items
+	case reflect.Struct:
+		return serializeModel(value)
+	}
+	return value.Interface()
+}
+
+func serializeModel(value reflect.Value) map[string]interface{} {
+	if !value.CanAddr() {
+		cp := reflect.New(value.Type())
+		cp.Elem().Set(value)
+		value = cp.Elem()
+	}
+	parsed := MustParseModel(value.Type())
+	em := &EloquentModel{}
+	if parsed.IsEloquent {
+		if existed, ok := value.Field(parsed.EloquentModelFieldIndex).Interface().(*EloquentModel); ok && existed != nil {
+			em.Pivot = existed.Pivot
+			em.WithAggregates = existed.WithAggregates
+		}
+	}
+	return em.toMap(value.Addr())
+}
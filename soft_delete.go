@@ -1 +1,174 @@
 package goeloquent
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/*
+runSoftDelete mark the model as deleted,the deleted at field is set and synced so Trashed reports it
+*/
+func (m *EloquentModel) runSoftDelete(b *EloquentBuilder) (res Result, err error) {
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	now := time.Now()
+	values := map[string]interface{}{
+		parsed.DeletedAt: sql.NullTime{Time: now, Valid: true},
+	}
+	if parsed.UpdatedAt != "" {
+		values[parsed.UpdatedAt] = now
+	}
+	res, err = b.Builder.Update(values)
+	if err != nil {
+		return
+	}
+	for column := range values {
+		if err = setModelAttribute(m.ModelPointer.Interface(), column, now); err != nil {
+			return
+		}
+		field := parsed.FieldsByDbName[column]
+		m.Origin[field.Name] = reflect.Indirect(m.ModelPointer).Field(field.Index).Interface()
+	}
+	return
+}
+
+/*
+Trashed Determine if the model has been soft-deleted.
+*/
+func (m *EloquentModel) Trashed() bool {
+	parsed := MustParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if !parsed.SoftDelete {
+		return false
+	}
+	value := reflect.Indirect(m.ModelPointer).Field(parsed.FieldsByDbName[parsed.DeletedAt].Index).Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, _ := valuer.Value()
+		return v != nil
+	}
+	return !reflect.ValueOf(value).IsZero()
+}
+
+/*
+Restore Restore a soft-deleted model,EloquentRestoring/EloquentRestored are fired before/after it.
+
+	user.Restore()
+	update `users` set `deleted_at` = ?, `updated_at` = ? where `id` = ?
+*/
+func (m *EloquentModel) Restore() (res Result, err error) {
+	parsed, err := ParseModel(reflect.Indirect(m.ModelPointer).Type())
+	if err != nil {
+		return Result{Error: err}, err
+	}
+	if !parsed.SoftDelete {
+		err = fmt.Errorf("model: %s doesn't use soft delete", parsed.Name)
+		return Result{Error: err}, err
+	}
+	if eventErr := m.FireModelEvent(EventRestoring, nil); eventErr != nil {
+		return Result{Error: eventErr}, eventErr
+	}
+	field := reflect.Indirect(m.ModelPointer).Field(parsed.FieldsByDbName[parsed.DeletedAt].Index)
+	field.Set(reflect.Zero(field.Type()))
+	m.Exists = true
+	res, err = m.Save()
+	if err != nil {
+		return
+	}
+	if eventErr := m.FireModelEvent(EventRestored, nil); eventErr != nil {
+		return Result{Error: eventErr}, eventErr
+	}
+	return
+}
+
+/*
+ForceDelete Delete the model from the database even if it uses soft delete,EloquentForceDeleting/EloquentForceDeleted are fired before/after it.
+*/
+func (m *EloquentModel) ForceDelete() (res Result, err error) {
+	if eventErr := m.FireModelEvent(EventForceDeleting, nil); eventErr != nil {
+		return Result{Error: eventErr}, eventErr
+	}
+	res, err = m.delete(true)
+	if err != nil {
+		return
+	}
+	if eventErr := m.FireModelEvent(EventForceDeleted, nil); eventErr != nil {
+		return Result{Error: eventErr}, eventErr
+	}
+	return
+}
+
+/*
+Delete Delete records matching the query,records of soft deletable models are only marked as deleted like EloquentModel.Delete does,
+use ForceDelete to remove them from the database
+
+ 1. DB.Model(&User{}).Where("age", "<", 18).Delete()
+    update `users` set `deleted_at` = ?, `updated_at` = ? where `age` < ? and `users`.`deleted_at` is null
+ 2. DB.Model(&User{}).Where("age", "<", 18).ForceDelete()
+    delete from `users` where `age` < ? and `users`.`deleted_at` is null
+*/
+func (b *EloquentBuilder) Delete(id ...interface{}) (Result, error) {
+	if b.BaseModel == nil || !b.BaseModel.SoftDelete {
+		if err := b.applyTenantScope(); err != nil {
+			return Result{Error: err}, err
+		}
+		return b.Builder.Delete(id...)
+	}
+	if err := b.checkTenantScope(); err != nil {
+		return Result{Error: err}, err
+	}
+	if len(id) > 0 {
+		b.Where(b.BaseModel.PrimaryKey.ColumnName, id[0])
+	}
+	b.ApplyGlobalScopes()
+	now := time.Now()
+	values := map[string]interface{}{b.BaseModel.DeletedAt: sql.NullTime{Time: now, Valid: true}}
+	if b.BaseModel.UpdatedAt != "" {
+		values[b.BaseModel.UpdatedAt] = now
+	}
+	return b.Builder.Update(values)
+}
+
+/*
+Restore Restore soft-deleted records matching the query.
+
+ 1. DB.Model(&User{}).Where("age", "<", 18).Restore()
+    update `users` set `deleted_at` = ?, `updated_at` = ? where `age` < ?
+*/
+func (b *EloquentBuilder) Restore() (Result, error) {
+	if b.BaseModel == nil || !b.BaseModel.SoftDelete {
+		err := errors.New("the model doesn't use soft delete")
+		if b.BaseModel != nil {
+			err = fmt.Errorf("model: %s doesn't use soft delete", b.BaseModel.Name)
+		}
+		return Result{Error: err}, err
+	}
+	if err := b.checkTenantScope(); err != nil {
+		return Result{Error: err}, err
+	}
+	b.WithTrashed()
+	b.ApplyGlobalScopes()
+	values := map[string]interface{}{b.BaseModel.DeletedAt: nil}
+	if b.BaseModel.UpdatedAt != "" {
+		values[b.BaseModel.UpdatedAt] = time.Now()
+	}
+	return b.Builder.Update(values)
+}
+
+/*
+ForceDelete Delete records matching the query from the database,use WithTrashed to include soft-deleted records.
+
+ 1. DB.Model(&User{}).WithTrashed().Where("age", "<", 18).ForceDelete()
+    delete from `users` where `age` < ?
+*/
+func (b *EloquentBuilder) ForceDelete(id ...interface{}) (Result, error) {
+	if err := b.checkTenantScope(); err != nil {
+		return Result{Error: err}, err
+	}
+	b.ApplyGlobalScopes()
+	return b.Builder.Delete(id...)
+}
@@ -0,0 +1,85 @@
+package goeloquent
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+	"time"
+)
+
+type SqliteConnector struct {
+}
+
+func (c SqliteConnector) connect(config *DBConfig) (*Connection, error) {
+	if c.IsMemory(config) {
+		// every connection to :memory: opens a new empty database,keep a single one alive
+		config.MaxOpenConns = 1
+		config.MaxIdleConns = 1
+	}
+	if config.MaxOpenConns == 0 {
+		config.MaxOpenConns = 10
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 5
+	}
+
+	db, err := c.CreateConnection(c.GetDsn(config))
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	if !c.IsMemory(config) {
+		if config.ConnMaxLifetime == 0 {
+			config.ConnMaxLifetime = 86400
+		}
+		if config.ConnMaxIdleTime == 0 {
+			config.ConnMaxIdleTime = 7200
+		}
+		db.SetConnMaxLifetime(time.Duration(config.ConnMaxLifetime) * time.Second)
+		db.SetConnMaxIdleTime(time.Duration(config.ConnMaxIdleTime) * time.Second)
+	}
+	return &Connection{
+		DB:     db,
+		Config: config,
+	}, nil
+}
+
+/*
+CreateConnection open the database and ping it,the error is returned if the database can't be reached
+*/
+func (c SqliteConnector) CreateConnection(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(string(DriverSqlite), dsn)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+/*
+IsMemory determine if the config points to an in-memory database
+*/
+func (c SqliteConnector) IsMemory(config *DBConfig) bool {
+	return config.Database == ":memory:" || config.Dsn == ":memory:"
+}
+
+/*
+GetDsn Database is the path of the database file or :memory:
+
+/path/to/database.sqlite?_pragma=foreign_keys(1)
+*/
+func (c SqliteConnector) GetDsn(config *DBConfig) string {
+	if len(config.Dsn) > 0 {
+		return config.Dsn
+	}
+	dsn := config.Database
+	if len(config.DsnExtraString) > 0 {
+		dsn = dsn + "?" + config.DsnExtraString
+	}
+	return dsn
+}
@@ -0,0 +1,126 @@
+package goeloquent
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+SqliteGrammar compile queries for sqlite.
+
+It reuses MysqlGrammar and overrides identifier wrapping("users"."name"),
+json selectors(json_extract),strftime based date wheres,"insert or ignore" and "on conflict" upserts.
+*/
+type SqliteGrammar struct {
+	MysqlGrammar
+}
+
+func (g *SqliteGrammar) GetDriver() Driver {
+	return DriverSqlite
+}
+
+/*
+CompileInsertOrIgnore Compile an insert ignore statement into SQL.
+
+insert or ignore into "users" ("name") values (?)
+*/
+func (g *SqliteGrammar) CompileInsertOrIgnore(values []map[string]interface{}) string {
+	g.GetBuilder().PreparedSql = strings.Replace(g.CompileInsert(values), "insert", "insert or ignore", 1)
+	return g.GetBuilder().PreparedSql
+}
+
+/*
+CompileUpsert Compile an "upsert" statement into SQL.
+
+insert into "users" ("email", "name") values (?, ?) on conflict ("email") do update set "name" = "excluded"."name"
+*/
+func (g *SqliteGrammar) CompileUpsert(values []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) string {
+	g.GetBuilder().PreparedSql = compileOnConflictUpsert(&g.MysqlGrammar, values, uniqueColumns, updateColumns)
+	return g.GetBuilder().PreparedSql
+}
+
+func (g *SqliteGrammar) CompileWhere(w Where) string {
+	switch w.Type {
+	case CONDITION_TYPE_DATE:
+		return g.compileDateBasedWhere("%Y-%m-%d", w)
+	case CONDITION_TYPE_TIME:
+		return g.compileDateBasedWhere("%H:%M:%S", w)
+	case CONDITION_TYPE_DAY:
+		return g.compileDateBasedWhere("%d", w)
+	case CONDITION_TYPE_MONTH:
+		return g.compileDateBasedWhere("%m", w)
+	case CONDITION_TYPE_YEAR:
+		return g.compileDateBasedWhere("%Y", w)
+	case CONDITION_TYPE_JSON_CONTAINS:
+		//the query isn't run,the error is returned by the query methods
+		g.GetBuilder().AddError(errors.New("this database engine does not support json contains operations"))
+		return ""
+	}
+	return g.MysqlGrammar.CompileWhere(w)
+}
+
+/*
+compileDateBasedWhere compile a date based where clause with strftime,
+day month and year are compared as integers so 5 and "05" both match
+*/
+func (g *SqliteGrammar) compileDateBasedWhere(format string, w Where) string {
+	column := fmt.Sprintf("strftime('%s', %s)", format, g.Wrap(w.Column))
+	if w.Type == CONDITION_TYPE_DATE || w.Type == CONDITION_TYPE_TIME {
+		return fmt.Sprintf("%s %s %s cast(%s as text)", w.Boolean, column, w.Operator, g.parameter(w.Value))
+	}
+	return fmt.Sprintf("%s cast(%s as integer) %s cast(%s as integer)", w.Boolean, column, w.Operator, g.parameter(w.Value))
+}
+
+/*
+CompileLock sqlite doesn't support row locks,the lock clause is ignored
+*/
+func (g *SqliteGrammar) CompileLock() string {
+	return ""
+}
+
+func (g *SqliteGrammar) CompileRandom(seed ...int) string {
+	return "RANDOM()"
+}
+
+/*
+Wrap a value in keyword identifiers,json selectors are converted to json_extract.
+
+meta->address->city => json_extract("meta", '$."address"."city"')
+*/
+func (g *SqliteGrammar) Wrap(value interface{}, prefixAlias ...bool) string {
+	if str, ok := value.(string); ok && strings.Contains(str, "->") && !strings.Contains(str, " as ") && !strings.Contains(str, " AS ") {
+		column, path := splitJsonPath(str)
+		var builder strings.Builder
+		builder.WriteString("$")
+		for _, segment := range path {
+			if _, err := strconv.Atoi(segment); err == nil {
+				builder.WriteString("[" + segment + "]")
+			} else {
+				builder.WriteString(`."` + strings.ReplaceAll(segment, "'", "''") + `"`)
+			}
+		}
+		return fmt.Sprintf("json_extract(%s, '%s')", g.MysqlGrammar.Wrap(column), builder.String())
+	}
+	return g.MysqlGrammar.Wrap(value, prefixAlias...)
+}
+
+/*
+WrapValue Wrap a value in keyword identifiers.
+*/
+func (g *SqliteGrammar) WrapValue(value string) string {
+	if value != "*" {
+		return fmt.Sprintf(`"%s"`, strings.ReplaceAll(value, `"`, `""`))
+	}
+	return value
+}
+
+/*
+WrapUnion sqlite doesn't allow parentheses around the queries of a compound select,select from them instead.
+
+select * from (select "name" from "users") union select * from (select "name" from "admins")
+*/
+func (g *SqliteGrammar) WrapUnion(sql string) string {
+	return "select * from (" + sql + ")"
+}
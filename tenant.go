@@ -0,0 +1,122 @@
+package goeloquent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const (
+	GlobalScopeTenant = "Tenant"
+	ColumnTenant      = "TENANT"
+)
+
+var (
+	ErrTenantMissing      = errors.New("tenant id not found in context")
+	ErrTenantScopeRemoved = errors.New("tenant scope removed without WithoutTenant")
+)
+
+type tenantContextKey struct{}
+
+/*
+WithTenant return a context carrying the tenant id,queries of models with a TENANT column are scoped by it
+
+ 1. ctx := WithTenant(context.Background(), 3)
+    DB.Model(&User{}).WithContext(ctx).Get(&users) select * from `users` where `users`.`tenant_id` = ?
+*/
+func WithTenant(ctx context.Context, id interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tenantContextKey{}, id)
+}
+
+/*
+TenantFromContext get the tenant id set by WithTenant
+*/
+func TenantFromContext(ctx context.Context) (id interface{}, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	id = ctx.Value(tenantContextKey{})
+	return id, id != nil
+}
+
+/*
+tenantScope the global scope registered by the TENANT tag,a query without a tenant id in its context returns ErrTenantMissing rather than reading every tenant's rows
+*/
+func tenantScope(m *Model) ScopeFunc {
+	return func(builder *EloquentBuilder) *EloquentBuilder {
+		id, ok := TenantFromContext(builder.Context)
+		if !ok {
+			builder.AddError(fmt.Errorf("%w for model %s", ErrTenantMissing, m.Name))
+			return builder
+		}
+		return builder.Where(builder.QualifyColumn(m.Tenant), id)
+	}
+}
+
+/*
+WithoutTenant explicitly bypass the tenant scope,updates and deletes are only allowed to run across tenants after this
+
+ 1. DB.Model(&User{}).WithoutTenant().Where("status", 0).Delete()
+*/
+func (b *EloquentBuilder) WithoutTenant() *EloquentBuilder {
+	b.TenantBypassed = true
+	return b.WithOutGlobalScopes(GlobalScopeTenant)
+}
+
+/*
+checkTenantScope refuse writes whose tenant scope was removed by WithOutGlobalScopes without WithoutTenant,or whose context has no tenant id
+*/
+func (b *EloquentBuilder) checkTenantScope() error {
+	if b.BaseModel == nil || b.BaseModel.Tenant == "" || b.TenantBypassed {
+		return nil
+	}
+	if _, removed := b.RemovedScopes[GlobalScopeTenant]; removed {
+		return fmt.Errorf("%w for model %s", ErrTenantScopeRemoved, b.BaseModel.Name)
+	}
+	if _, ok := TenantFromContext(b.Context); !ok {
+		return fmt.Errorf("%w for model %s", ErrTenantMissing, b.BaseModel.Name)
+	}
+	return nil
+}
+
+/*
+applyTenantScope check and apply only the tenant scope,for writes which don't apply the other global scopes
+*/
+func (b *EloquentBuilder) applyTenantScope() error {
+	if err := b.checkTenantScope(); err != nil {
+		return err
+	}
+	if b.BaseModel == nil || b.BaseModel.Tenant == "" {
+		return nil
+	}
+	if _, removed := b.RemovedScopes[GlobalScopeTenant]; !removed {
+		b.callScope(b.BaseModel.GlobalScopes[GlobalScopeTenant])
+	}
+	return nil
+}
+
+/*
+fillTenant set the tenant column of a new model from its context if it's not set
+*/
+func (m *EloquentModel) fillTenant(parsed *Model, attrs map[string]interface{}) error {
+	if parsed.Tenant == "" {
+		return nil
+	}
+	id, ok := TenantFromContext(m.Context)
+	if !ok {
+		return nil
+	}
+	field := parsed.FieldsByDbName[parsed.Tenant]
+	if !reflect.Indirect(m.ModelPointer).Field(field.Index).IsZero() {
+		return nil
+	}
+	if err := setModelAttribute(m.ModelPointer.Interface(), parsed.Tenant, id); err != nil {
+		return err
+	}
+	attrs[parsed.Tenant] = reflect.Indirect(m.ModelPointer).Field(field.Index).Interface()
+	return nil
+}
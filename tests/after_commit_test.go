@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var afterCommitEvents []string
+
+type AfterCommitPost struct {
+	*goeloquent.EloquentModel
+	ID    int64  `goelo:"column:id;primaryKey"`
+	Title string `goelo:"column:title"`
+}
+
+func (p *AfterCommitPost) TableName() string {
+	return "tx_posts"
+}
+func (p *AfterCommitPost) ConnectionName() string {
+	return "sqlite"
+}
+func (p *AfterCommitPost) EloquentEventsAfterCommit() bool {
+	return true
+}
+func (p *AfterCommitPost) EloquentSaving() error {
+	afterCommitEvents = append(afterCommitEvents, "saving:"+p.Title)
+	return nil
+}
+func (p *AfterCommitPost) EloquentCreated() error {
+	afterCommitEvents = append(afterCommitEvents, "created:"+p.Title)
+	return nil
+}
+
+func TestAfterCommitCallbacks(t *testing.T) {
+	defer createTxPosts(t)()
+	var events []string
+	_, err := GetSqliteConnection().Transaction(func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		tx.AfterCommit(func() { events = append(events, "outer committed") })
+		tx.AfterRollback(func() { events = append(events, "outer rolled back") })
+		_, err := tx.Transaction(func(nested *goeloquent.Transaction) (goeloquent.Result, error) {
+			goeloquent.DB.AfterCommit(nested.GetContext(), func() { events = append(events, "released") })
+			return goeloquent.Result{}, nil
+		})
+		assert.Nil(t, err)
+		_, err = tx.Transaction(func(nested *goeloquent.Transaction) (goeloquent.Result, error) {
+			nested.AfterCommit(func() { events = append(events, "dropped") })
+			nested.AfterRollback(func() { events = append(events, "savepoint rolled back") })
+			return goeloquent.Result{}, errors.New("rollback savepoint")
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"savepoint rolled back"}, events)
+		return goeloquent.Result{}, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"savepoint rolled back", "outer committed", "released"}, events)
+
+	events = nil
+	tx, err := GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	tx.AfterCommit(func() { events = append(events, "committed") })
+	tx.AfterRollback(func() { events = append(events, "rolled back") })
+	assert.Nil(t, tx.Rollback())
+	assert.Equal(t, []string{"rolled back"}, events)
+
+	//without a transaction the callback runs immediately
+	events = nil
+	goeloquent.DB.AfterCommit(context.Background(), func() { events = append(events, "now") })
+	assert.Equal(t, []string{"now"}, events)
+}
+
+func TestModelEventsAfterCommit(t *testing.T) {
+	defer createTxPosts(t)()
+	afterCommitEvents = nil
+	tx, err := GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	post := AfterCommitPost{Title: "a"}
+	goeloquent.Init(&post)
+	_, err = post.WithContext(tx.GetContext()).Save()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"saving:a"}, afterCommitEvents)
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, []string{"saving:a", "created:a"}, afterCommitEvents)
+
+	afterCommitEvents = nil
+	tx, err = GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	rolledBack := AfterCommitPost{Title: "b"}
+	goeloquent.InitModelInTx(&rolledBack, tx)
+	_, err = rolledBack.Save()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	assert.Equal(t, []string{"saving:b"}, afterCommitEvents)
+
+	//outside a transaction events fire immediately
+	afterCommitEvents = nil
+	now := AfterCommitPost{Title: "c"}
+	goeloquent.Init(&now)
+	_, err = now.Save()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"saving:c", "created:c"}, afterCommitEvents)
+}
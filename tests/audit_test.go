@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type AuditPost struct {
+	*goeloquent.EloquentModel
+	ID        int64        `goelo:"column:id;primaryKey"`
+	Title     string       `goelo:"column:title"`
+	Secret    string       `goelo:"column:secret"`
+	DeletedAt sql.NullTime `goelo:"column:deleted_at;DELETED_AT"`
+}
+
+func (p *AuditPost) TableName() string {
+	return "audit_posts"
+}
+func (p *AuditPost) ConnectionName() string {
+	return "sqlite"
+}
+func (p *AuditPost) EloquentAuditExclude() []string {
+	return []string{"secret"}
+}
+
+type auditRow struct {
+	Type   string
+	Id     string
+	Event  string
+	Old    map[string]interface{}
+	New    map[string]interface{}
+	UserId interface{}
+}
+
+func createAuditTables(t *testing.T) func() {
+	return CreateSqliteTables(t,
+		`create table "audit_posts" ("id" integer primary key autoincrement, "title" text, "secret" text, "deleted_at" datetime)`,
+		`create table "audits" ("id" integer primary key autoincrement, "auditable_type" text, "auditable_id" text, "event" text, "old_values" text, "new_values" text, "user_id" text, "created_at" datetime)`,
+	)
+}
+
+func getAudits(t *testing.T) []auditRow {
+	var rows []map[string]interface{}
+	_, err := GetSqliteConnection().Table("audits").OrderBy("id").Get(&rows)
+	assert.Nil(t, err)
+	var audits []auditRow
+	for _, row := range rows {
+		a := auditRow{Type: row["auditable_type"].(string), Id: row["auditable_id"].(string), Event: row["event"].(string), UserId: row["user_id"]}
+		assert.Nil(t, json.Unmarshal([]byte(row["old_values"].(string)), &a.Old))
+		assert.Nil(t, json.Unmarshal([]byte(row["new_values"].(string)), &a.New))
+		audits = append(audits, a)
+	}
+	return audits
+}
+
+func TestAuditRowsAreWritten(t *testing.T) {
+	defer createAuditTables(t)()
+	post := AuditPost{Title: "a", Secret: "s"}
+	goeloquent.Init(&post)
+	post.WithContext(goeloquent.WithAuditUser(context.Background(), "7"))
+	_, err := post.Save()
+	assert.Nil(t, err)
+
+	post.Title = "b"
+	post.Secret = "t"
+	_, err = post.Save()
+	assert.Nil(t, err)
+	//nothing changed,nothing audited
+	_, err = post.Save()
+	assert.Nil(t, err)
+
+	_, err = post.Delete()
+	assert.Nil(t, err)
+	_, err = post.Restore()
+	assert.Nil(t, err)
+
+	audits := getAudits(t)
+	assert.Len(t, audits, 4)
+	assert.Equal(t, "AuditPost", audits[0].Type)
+	assert.Equal(t, "1", audits[0].Id)
+	assert.Equal(t, "created", audits[0].Event)
+	assert.Empty(t, audits[0].Old)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "title": "a", "deleted_at": nil}, audits[0].New)
+	assert.Equal(t, "7", audits[0].UserId)
+
+	assert.Equal(t, "updated", audits[1].Event)
+	assert.Equal(t, map[string]interface{}{"title": "a"}, audits[1].Old)
+	assert.Equal(t, map[string]interface{}{"title": "b"}, audits[1].New)
+
+	assert.Equal(t, "deleted", audits[2].Event)
+	assert.Equal(t, "b", audits[2].Old["title"])
+	assert.Empty(t, audits[2].New)
+
+	assert.Equal(t, "restored", audits[3].Event)
+	assert.NotNil(t, audits[3].Old["deleted_at"])
+	assert.Nil(t, audits[3].New["deleted_at"])
+}
+
+func TestAuditRowsAreWrittenInTheSameTransaction(t *testing.T) {
+	defer createAuditTables(t)()
+	tx, err := GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	post := AuditPost{Title: "a"}
+	goeloquent.InitModelInTx(&post, tx)
+	_, err = post.Save()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	assert.Empty(t, getAudits(t))
+}
+
+func TestAuditRowAndChangeAreRolledBackTogether(t *testing.T) {
+	defer createAuditTables(t)()
+	post := AuditPost{Title: "a"}
+	goeloquent.Init(&post)
+	_, err := post.Save()
+	assert.Nil(t, err)
+	assert.Nil(t, post.Tx)
+
+	c := GetSqliteConnection()
+	_, err = c.Statement(`drop table "audits"`, nil)
+	assert.Nil(t, err)
+	post.Title = "b"
+	_, err = post.Save()
+	assert.NotNil(t, err)
+	assert.Nil(t, post.Tx)
+	_, err = post.Delete()
+	assert.NotNil(t, err)
+
+	var rows []map[string]interface{}
+	_, err = c.Table("audit_posts").Get(&rows)
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "a", rows[0]["title"])
+	assert.Nil(t, rows[0]["deleted_at"])
+}
+
+func TestCleanSaveWithoutUpdatedAtRunsNoQuery(t *testing.T) {
+	defer createSaveTables(t)()
+	post := SavePost{Title: "a"}
+	goeloquent.Init(&post)
+	_, err := post.Save()
+	assert.Nil(t, err)
+	res, err := post.Save()
+	assert.Nil(t, err)
+	assert.Empty(t, res.Sql)
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type CastSettings struct {
+	Theme  string         `json:"theme"`
+	Limits map[string]int `json:"limits"`
+}
+
+type yesNoCaster struct{}
+
+func (yesNoCaster) Get(field *goeloquent.Field, dest reflect.Value, value interface{}) error {
+	dest.SetBool(fmt.Sprintf("%s", value) == "yes")
+	return nil
+}
+func (yesNoCaster) Set(field *goeloquent.Field, src reflect.Value) (interface{}, error) {
+	if src.Bool() {
+		return "yes", nil
+	}
+	return "no", nil
+}
+
+func init() {
+	goeloquent.RegisterCaster("yesno", yesNoCaster{})
+}
+
+type CastUser struct {
+	*goeloquent.EloquentModel
+	ID       int64         `goelo:"column:id;primaryKey"`
+	Settings CastSettings  `goelo:"column:settings;cast:json"`
+	Meta     []interface{} `goelo:"column:meta;cast:json"`
+	Tags     []string      `goelo:"column:tags;cast:set"`
+	LoginAt  time.Time     `goelo:"column:login_at;cast:unix"`
+	Balance  string        `goelo:"column:balance;cast:decimal:2"`
+	Secret   string        `goelo:"column:secret;cast:encrypted"`
+	Active   bool          `goelo:"column:active;cast:yesno"`
+}
+
+func (u *CastUser) TableName() string {
+	return "cast_users"
+}
+func (u *CastUser) ConnectionName() string {
+	return "sqlite"
+}
+
+func createCastUsers(t *testing.T) func() {
+	return CreateSqliteTables(t, `create table "cast_users" ("id" integer primary key autoincrement, "settings" text, "meta" text, "tags" text, "login_at" integer, "balance" numeric, "secret" text, "active" text)`)
+}
+
+func TestCastsAreAppliedOnSaveAndScan(t *testing.T) {
+	defer createCastUsers(t)()
+	assert.Nil(t, goeloquent.SetEncryptionKey([]byte("0123456789abcdef0123456789abcdef")))
+	loginAt := time.Unix(1700000000, 0)
+	user := CastUser{
+		Settings: CastSettings{Theme: "dark", Limits: map[string]int{"posts": 3}},
+		Tags:     []string{"go", "sql"},
+		LoginAt:  loginAt,
+		Balance:  "12.5",
+		Secret:   "s3cret",
+		Active:   true,
+	}
+	goeloquent.Init(&user)
+	_, err := user.Save()
+	assert.Nil(t, err)
+
+	var raw []map[string]interface{}
+	_, err = GetSqliteConnection().Table("cast_users").Get(&raw)
+	assert.Nil(t, err)
+	assert.Len(t, raw, 1)
+	assert.Equal(t, `{"theme":"dark","limits":{"posts":3}}`, raw[0]["settings"])
+	assert.Nil(t, raw[0]["meta"])
+	assert.Equal(t, "go,sql", raw[0]["tags"])
+	assert.Equal(t, int64(1700000000), raw[0]["login_at"])
+	assert.Equal(t, "yes", raw[0]["active"])
+	assert.NotEqual(t, "s3cret", raw[0]["secret"])
+
+	var found CastUser
+	_, err = DB.Model(&found).Find(&found, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, user.Settings, found.Settings)
+	assert.Nil(t, found.Meta)
+	assert.Equal(t, []string{"go", "sql"}, found.Tags)
+	assert.True(t, loginAt.Equal(found.LoginAt))
+	assert.Equal(t, "12.50", found.Balance)
+	assert.Equal(t, "s3cret", found.Secret)
+	assert.True(t, found.Active)
+
+	var users []CastUser
+	_, err = DB.Model(&CastUser{}).Get(&users)
+	assert.Nil(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "s3cret", users[0].Secret)
+}
+
+func TestCastsDirtyChecking(t *testing.T) {
+	defer createCastUsers(t)()
+	assert.Nil(t, goeloquent.SetEncryptionKey([]byte("0123456789abcdef")))
+	_, err := GetSqliteConnection().Table("cast_users").Insert(map[string]interface{}{
+		"settings": `{"theme":"light","limits":{"posts":1}}`,
+		"tags":     "a,b",
+		"balance":  3,
+		"active":   "no",
+	})
+	assert.Nil(t, err)
+
+	var user CastUser
+	_, err = DB.Model(&user).First(&user)
+	assert.Nil(t, err)
+	assert.Equal(t, "3.00", user.Balance)
+	assert.Empty(t, user.GetDirty())
+
+	//in place changes of maps and slices are detected
+	user.Settings.Limits["posts"] = 2
+	user.Tags[0] = "c"
+	//same database value is not dirty
+	user.Balance = "3"
+	dirty := user.GetDirty()
+	assert.Len(t, dirty, 2)
+	assert.Contains(t, dirty, "Settings")
+	assert.Contains(t, dirty, "Tags")
+	assert.False(t, user.IsDirty("Balance"))
+
+	_, err = user.Save()
+	assert.Nil(t, err)
+	assert.Empty(t, user.GetDirty())
+	var found CastUser
+	_, err = DB.Model(&found).First(&found)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, found.Settings.Limits["posts"])
+	assert.Equal(t, []string{"c", "b"}, found.Tags)
+
+	assert.Panics(t, func() {
+		type BadCast struct {
+			*goeloquent.EloquentModel
+			ID int64 `goelo:"column:id;primaryKey;cast:unknown"`
+		}
+		goeloquent.MustParseModel(&BadCast{})
+	})
+}
+
+func TestCastErrorsAreReturned(t *testing.T) {
+	defer createCastUsers(t)()
+	assert.Nil(t, goeloquent.SetEncryptionKey([]byte("0123456789abcdef")))
+	user := CastUser{Balance: "abc"}
+	goeloquent.Init(&user)
+	_, err := user.Save()
+	assert.Regexp(t, "^cast column balance", err)
+	assert.False(t, user.Exists)
+
+	user.Balance = "1"
+	_, err = user.Save()
+	assert.Nil(t, err)
+	user.Balance = "abc"
+	_, err = user.Save()
+	assert.Regexp(t, "^cast column balance", err)
+
+	_, err = GetSqliteConnection().Table("cast_users").Insert(&CastUser{Balance: "abc"})
+	assert.Regexp(t, "^cast column balance", err)
+	var count int
+	_, err = GetSqliteConnection().Table("cast_users").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -7,6 +7,7 @@ import (
 )
 
 func TestOpenFailed(t *testing.T) {
+	RequireMysql(t)
 
 	openCases := map[string]map[string]interface{}{
 		//testIfDriverIsntSetExceptionIsThrown
@@ -49,13 +50,14 @@ func TestOpenFailed(t *testing.T) {
 		errStr := m["msg"].(string)
 		config := m["config"].(map[string]goeloquent.DBConfig)
 		assert.PanicsWithValuef(t, errStr, func() {
-			_ = goeloquent.Open(config)
+			_ = goeloquent.MustOpen(config)
 		}, "TestOpen case %s failed", caseName)
 	}
 
 }
 
 func TestOpenSuccess(t *testing.T) {
+	RequireMysql(t)
 	Setup()
 	var res int
 	_, err := DB.Select("SELECT 1 + 1", nil, &res)
@@ -64,13 +66,15 @@ func TestOpenSuccess(t *testing.T) {
 }
 
 func TestConnectionCanBeCreated(t *testing.T) {
+	RequireMysql(t)
 	//testConnectionCanBeCreated
 	Setup()
 	conn := goeloquent.Connection{}
 	assert.IsType(t, DB.Conn("default"), conn)
-	assert.IsType(t, DB.Connection("chat"), &conn)
+	assert.IsType(t, DB.MustConnection("chat"), &conn)
 }
 func TestConnectionHasProperConfig(t *testing.T) {
+	RequireMysql(t)
 	//testConnectionFromUrlHasProperConfig
 	Setup()
 	configs := map[string]goeloquent.DBConfig{
@@ -87,18 +91,20 @@ func TestConnectionHasProperConfig(t *testing.T) {
 }
 
 func TestSingleConnectionNotCreatedUntilNeeded(t *testing.T) {
+	RequireMysql(t)
 	Setup()
 	assert.Equal(t, 1, len(DB.Connections))
 	_, ok := DB.Connections["chat"]
 	assert.False(t, ok)
 }
 func TestDSNConfig(t *testing.T) {
+	RequireMysql(t)
 	DB.AddConfig("test", &goeloquent.DBConfig{
 		Driver: "mysql",
 		Dsn:    "root:123@tcp(127.0.0.1:8889)/goeloquent?charset=utf8mb4&parseTime=true",
 	})
 	//fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s", config.Username, config.Password, config.Host, config.Port, config.Database, strings.Join(params, "&"))
-	conn := DB.Connection("test")
+	conn := DB.MustConnection("test")
 	err := conn.DB.Ping()
 	assert.Nil(t, err)
 }
@@ -152,3 +158,35 @@ func TestConnectionPool(t *testing.T) {
 //TODO: testSqlServerConnectCallsCreateConnectionWithProperArguments
 //TODO: testSqlServerConnectCallsCreateConnectionWithOptionalArguments
 //TODO: testSqlServerConnectCallsCreateConnectionWithPreferredODBC
+
+func TestOpenAndConnectionReturnErrors(t *testing.T) {
+	current := goeloquent.DB
+	db, err := goeloquent.Open(map[string]goeloquent.DBConfig{"default": {Driver: "mssql"}})
+	assert.Nil(t, db)
+	assert.EqualError(t, err, "unsupported driver:mssql")
+	//a failed Open keeps the current manager
+	assert.Same(t, current, goeloquent.DB)
+
+	_, err = DB.Connection("missing")
+	assert.EqualError(t, err, "Database connection missing not configured.")
+	assert.PanicsWithValue(t, "Database connection missing not configured.", func() {
+		DB.MustConnection("missing")
+	})
+
+	DB.AddConfig("unreachable", &goeloquent.DBConfig{
+		Driver:   goeloquent.DriverSqlite,
+		Database: "/nonexistent/goeloquent/test.sqlite",
+	})
+	_, err = DB.Connection("unreachable")
+	assert.NotNil(t, err)
+	_, ok := DB.Connections["unreachable"]
+	assert.False(t, ok)
+	//the next call tries again
+	DB.Configs["unreachable"].Database = ":memory:"
+	conn, err := DB.Connection("unreachable")
+	assert.Nil(t, err)
+	assert.Same(t, conn, DB.MustConnection("unreachable"))
+	conn.DB.Close()
+	delete(DB.Connections, "unreachable")
+	delete(DB.Configs, "unreachable")
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCancelledContextStopsQueries(t *testing.T) {
+	defer CreateSqliteTables(t, `create table "context_users" ("id" integer primary key autoincrement, "name" text)`)()
+	c := GetSqliteConnection()
+	_, err := c.Table("context_users").Insert([]map[string]interface{}{{"name": "a"}, {"name": "b"}, {"name": "c"}})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var names []string
+	_, err = c.Table("context_users").WithContext(ctx).Pluck(&names, "name")
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = c.Table("context_users").WithContext(ctx).Where("id", 1).Update(map[string]interface{}{"name": "d"})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	var chunks int
+	err = c.Table("context_users").WithContext(ctx).OrderBy("id").Chunk(&[]map[string]interface{}{}, 1, func(dest interface{}) error {
+		chunks++
+		cancel()
+		return nil
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, chunks)
+}
+
+type contextTestKey struct{}
+
+func TestTransactionUsesBeginContext(t *testing.T) {
+	c := GetSqliteConnection()
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextTestKey{}, "value"))
+	tx, err := c.BeginTransactionContext(ctx, nil)
+	assert.Nil(t, err)
+	//the transaction's context is derived from ctx and carries the transaction
+	assert.Equal(t, "value", tx.Query().Context.Value(contextTestKey{}))
+	assert.Same(t, tx, goeloquent.TransactionFromContext(tx.Query().Context, c.ConnectionName))
+	cancel()
+	assert.True(t, errors.Is(tx.Query().Context.Err(), context.Canceled))
+	var count int
+	_, err = tx.Query().From("sqlite_master").Count(&count)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"database/sql"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type DirtyPost struct {
+	*goeloquent.EloquentModel
+	ID          int64        `goelo:"column:id;primaryKey"`
+	Title       string       `goelo:"column:title"`
+	Body        []byte       `goelo:"column:body"`
+	PublishedAt sql.NullTime `goelo:"column:published_at"`
+	UpdatedAt   sql.NullTime `goelo:"column:updated_at;UPDATED_AT"`
+}
+
+func (p *DirtyPost) TableName() string {
+	return "dirty_posts"
+}
+func (p *DirtyPost) ConnectionName() string {
+	return "sqlite"
+}
+
+func createDirtyPosts(t *testing.T) func() {
+	drop := CreateSqliteTables(t, `create table "dirty_posts" ("id" integer primary key autoincrement, "title" text, "body" blob, "published_at" datetime, "updated_at" datetime)`)
+	_, err := GetSqliteConnection().Table("dirty_posts").Insert(map[string]interface{}{"title": "a", "body": []byte(`{"tags":["go"]}`), "published_at": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.Nil(t, err)
+	return drop
+}
+
+func TestDirtyTracking(t *testing.T) {
+	defer createDirtyPosts(t)()
+	var post DirtyPost
+	_, err := DB.Model(&post).Find(&post, 1)
+	assert.Nil(t, err)
+	assert.True(t, post.IsClean())
+	assert.Empty(t, post.Diff())
+
+	//equal values are not dirty
+	post.Body = []byte(`{"tags":["go"]}`)
+	post.PublishedAt = sql.NullTime{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	assert.True(t, post.IsClean())
+
+	//in place changes of non-comparable fields are detected
+	post.Body[2] = 'T'
+	assert.True(t, post.IsDirty("Body"))
+	assert.True(t, post.IsDirty("body"))
+	post.PublishedAt.Time = post.PublishedAt.Time.Add(time.Hour)
+	assert.True(t, post.IsDirty("PublishedAt"))
+	assert.True(t, post.IsClean("Title"))
+	assert.False(t, post.IsClean("Title", "Body"))
+
+	diff := post.Diff()
+	assert.Len(t, diff, 2)
+	assert.Equal(t, []byte(`{"tags":["go"]}`), diff["Body"].Old)
+	assert.Equal(t, []byte(`{"Tags":["go"]}`), diff["Body"].New)
+	assert.Equal(t, []byte(`{"tags":["go"]}`), post.GetOriginal("body"))
+}
+
+func TestChangesReflectTheLastSave(t *testing.T) {
+	defer createDirtyPosts(t)()
+	var post DirtyPost
+	_, err := DB.Model(&post).Find(&post, 1)
+	assert.Nil(t, err)
+	assert.False(t, post.WasChanged())
+
+	post.Body = []byte(`{"tags":["go","sql"]}`)
+	_, err = post.Save()
+	assert.Nil(t, err)
+	assert.True(t, post.IsClean())
+	assert.True(t, post.WasChanged())
+	assert.True(t, post.WasChanged("Body", "Title"))
+	assert.False(t, post.WasChanged("Title"))
+	assert.Contains(t, post.GetChanges(), "UpdatedAt")
+	assert.Equal(t, []byte(`{"tags":["go","sql"]}`), post.GetOriginal("Body"))
+
+	var found DirtyPost
+	_, err = DB.Model(&found).Find(&found, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(`{"tags":["go","sql"]}`), found.Body)
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"modernc.org/sqlite"
+	"testing"
+)
+
+func createErrorTables(t *testing.T) func() {
+	drop := CreateSqliteTables(t,
+		`create table "error_users" ("id" integer primary key autoincrement, "email" text unique)`,
+		`create table "error_posts" ("id" integer primary key autoincrement, "user_id" integer references "error_users" ("id"))`,
+		`PRAGMA foreign_keys = ON`,
+	)
+	return func() {
+		GetSqliteConnection().Statement(`PRAGMA foreign_keys = OFF`, nil)
+		drop()
+	}
+}
+
+func TestTypedQueryErrors(t *testing.T) {
+	defer createErrorTables(t)()
+	c := GetSqliteConnection()
+	_, err := c.Table("error_users").Insert(map[string]interface{}{"email": "a@example.com"})
+	assert.Nil(t, err)
+
+	_, err = c.Table("error_users").Insert(map[string]interface{}{"email": "a@example.com"})
+	var exception *goeloquent.QueryException
+	assert.True(t, errors.As(err, &exception))
+	assert.Equal(t, `insert into "error_users" ("email") values (?)`, exception.Sql)
+	assert.Equal(t, []interface{}{"a@example.com"}, exception.Bindings)
+	var unique *goeloquent.UniqueConstraintViolation
+	assert.True(t, errors.As(err, &unique))
+	//the driver error is still reachable
+	var driverErr *sqlite.Error
+	assert.True(t, errors.As(err, &driverErr))
+
+	_, err = c.Table("error_posts").Insert(map[string]interface{}{"user_id": 100})
+	var foreignKey *goeloquent.ForeignKeyViolation
+	assert.True(t, errors.As(err, &foreignKey))
+	assert.False(t, errors.As(err, &unique))
+
+	var rows []map[string]interface{}
+	_, err = c.Select("select * from missing_table", nil, &rows, nil)
+	assert.True(t, errors.As(err, &exception))
+	assert.Contains(t, exception.Err.Error(), "no such table")
+}
+
+func TestFirstOrFailAndFindOrFail(t *testing.T) {
+	defer createTxPosts(t)()
+	c := GetSqliteConnection()
+	_, err := c.Table("tx_posts").Insert(map[string]interface{}{"title": "a"})
+	assert.Nil(t, err)
+
+	var post TxPost
+	_, err = DB.Model(&post).FindOrFail(&post, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", post.Title)
+
+	var missing TxPost
+	_, err = DB.Model(&missing).FindOrFail(&missing, 2)
+	assert.True(t, errors.Is(err, goeloquent.ErrRecordNotFound))
+	var exception *goeloquent.QueryException
+	assert.True(t, errors.As(err, &exception))
+	assert.Equal(t, []interface{}{2}, exception.Bindings)
+
+	_, err = DB.Model(&missing).Where("title", "b").FirstOrFail(&missing)
+	assert.True(t, errors.Is(err, goeloquent.ErrRecordNotFound))
+
+	var rows []map[string]interface{}
+	_, err = c.Table("tx_posts").Where("title", "b").FirstOrFail(&rows)
+	assert.True(t, errors.Is(err, goeloquent.ErrRecordNotFound))
+	_, err = c.Table("tx_posts").Where("title", "a").FirstOrFail(&rows)
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+}
+
+type ErrorUser struct {
+	*goeloquent.EloquentModel
+	ID           int64         `goelo:"column:id;primaryKey"`
+	Email        string        `goelo:"column:email"`
+	Posts        []*ErrorPost  `goelo:"HasMany:PostsRelation"`
+	MissingPosts []*ErrorPost  `goelo:"HasMany:MissingPostsRelation"`
+	BadPosts     []*BadTagPost `goelo:"HasMany:BadPostsRelation"`
+	Images       []*ErrorPost  `goelo:"MorphMany:ImagesRelation"`
+}
+
+func (u *ErrorUser) TableName() string {
+	return "error_users"
+}
+func (u *ErrorUser) ConnectionName() string {
+	return "sqlite"
+}
+func (u *ErrorUser) PostsRelation() *goeloquent.HasManyRelation {
+	return u.HasMany(u, &ErrorPost{}, "id", "user_id")
+}
+func (u *ErrorUser) MissingPostsRelation() *goeloquent.HasManyRelation {
+	rb := u.HasMany(u, &ErrorPost{}, "id", "user_id")
+	rb.From("missing_posts")
+	return rb
+}
+func (u *ErrorUser) BadPostsRelation() *goeloquent.HasManyRelation {
+	return u.HasMany(u, &BadTagPost{}, "id", "user_id")
+}
+func (u *ErrorUser) ImagesRelation() *goeloquent.MorphManyRelation {
+	return u.MorphMany(u, &ErrorPost{}, "id", "imageable_id", "imageable_type")
+}
+
+type ErrorPost struct {
+	*goeloquent.EloquentModel
+	ID     int64 `goelo:"column:id;primaryKey"`
+	UserId int64 `goelo:"column:user_id"`
+}
+
+func (p *ErrorPost) TableName() string {
+	return "error_posts"
+}
+func (p *ErrorPost) ConnectionName() string {
+	return "sqlite"
+}
+
+func TestEagerLoadErrors(t *testing.T) {
+	defer createErrorTables(t)()
+	_, err := GetSqliteConnection().Table("error_users").Insert(map[string]interface{}{"email": "a@example.com"})
+	assert.Nil(t, err)
+
+	var users []ErrorUser
+	_, err = DB.Model(&ErrorUser{}).With("Posts").Get(&users)
+	assert.Nil(t, err)
+	_, err = DB.Model(&ErrorUser{}).With("MissingPosts").Get(&users)
+	var exception *goeloquent.QueryException
+	assert.True(t, errors.As(err, &exception))
+	assert.Contains(t, err.Error(), "no such table")
+	_, err = DB.Model(&ErrorUser{}).With("BadPosts").Get(&users)
+	assert.EqualError(t, err, "unknown tag unknown in model:BadTagPost field:ID")
+	_, err = DB.Model(&ErrorUser{}).With("Images").Get(&users)
+	assert.EqualError(t, err, "no registered morph model found for ErrorUser,check your code or register it")
+
+	var user ErrorUser
+	_, err = DB.Model(&user).Find(&user, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, user.Load("Posts"))
+	err = user.Load("MissingPosts")
+	assert.Contains(t, err.Error(), "no such table")
+}
+
+type BadTagPost struct {
+	*goeloquent.EloquentModel
+	ID int64 `goelo:"column:id;primaryKey;unknown"`
+}
+
+type MissingConnectionPost struct {
+	*goeloquent.EloquentModel
+	ID int64 `goelo:"column:id;primaryKey"`
+}
+
+func (p *MissingConnectionPost) ConnectionName() string {
+	return "missing"
+}
+
+func TestErrorsInsteadOfPanics(t *testing.T) {
+	defer createTxPosts(t)()
+	c := GetSqliteConnection()
+	_, err := c.Table("tx_posts").Insert([]map[string]interface{}{{"title": "a"}, {"title": "b"}})
+	assert.Nil(t, err)
+
+	var rows []map[string]interface{}
+	_, err = c.Table("tx_posts").GroupBy("title").Paginate(&rows, 10, 1)
+	assert.EqualError(t, err, "having/group pagination not supported")
+	var posts []TxPost
+	_, err = DB.Model(&TxPost{}).GroupBy("title").Paginate(&posts, 10, 1)
+	assert.EqualError(t, err, "having/group pagination not supported")
+
+	err = c.Table("tx_posts").Chunk(&rows, 1, func(dest interface{}) error { return nil })
+	assert.EqualError(t, err, "must specify an orderby clause when using Chunk method")
+	err = DB.Model(&TxPost{}).Chunk(&posts, 1, func(dest interface{}) error { return nil })
+	assert.EqualError(t, err, "must specify an orderby clause when using Chunk method")
+
+	_, err = goeloquent.ParseModel(&BadTagPost{})
+	assert.EqualError(t, err, "unknown tag unknown in model:BadTagPost field:ID")
+	assert.PanicsWithValue(t, "unknown tag unknown in model:BadTagPost field:ID", func() {
+		goeloquent.MustParseModel(&BadTagPost{})
+	})
+
+	_, err = goeloquent.GetRegisteredModel("MissingModel")
+	assert.EqualError(t, err, "no registered model found for MissingModel")
+	assert.Panics(t, func() {
+		goeloquent.MustGetRegisteredModel("MissingModel")
+	})
+
+	//errors met while making the builder are returned by the query methods
+	var bad []BadTagPost
+	_, err = DB.Model(&BadTagPost{}).Get(&bad)
+	assert.EqualError(t, err, "unknown tag unknown in model:BadTagPost field:ID")
+	_, err = DB.Model(&MissingConnectionPost{}).First(&MissingConnectionPost{})
+	assert.EqualError(t, err, "Database connection missing not configured.")
+	_, err = DB.Model().Get(&bad)
+	assert.EqualError(t, err, "unknown tag unknown in model:BadTagPost field:ID")
+	_, err = c.Table("tx_posts").Union(1).Get(&rows)
+	assert.EqualError(t, err, "union query must be [*Builder],[*EloquentBuilder],[func(builder *Builder)] or [func(builder *Builder) *Builder]")
+
+	//scan errors are returned
+	var ids []int
+	_, err = c.Table("tx_posts").Pluck(&ids, "title")
+	var exception *goeloquent.QueryException
+	assert.True(t, errors.As(err, &exception))
+	assert.Contains(t, err.Error(), "converting")
+}
+
+func TestQueryExceptionMessageLeavesOutBindings(t *testing.T) {
+	defer createErrorTables(t)()
+	c := GetSqliteConnection()
+	_, err := c.Table("error_users").Insert(map[string]interface{}{"email": "secret@example.com"})
+	assert.Nil(t, err)
+	_, err = c.Table("error_users").Insert(map[string]interface{}{"email": "secret@example.com"})
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret@example.com")
+	assert.NotContains(t, err.Error(), "\n")
+	assert.Contains(t, err.Error(), `UNIQUE constraint failed`)
+	assert.Contains(t, err.Error(), `(SQL: insert into "error_users" ("email") values (?))`)
+}
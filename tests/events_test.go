@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+type BootedPost struct {
+	*goeloquent.EloquentModel
+	ID    int64  `goelo:"column:id;primaryKey"`
+	Title string `goelo:"column:title"`
+}
+
+func (p *BootedPost) ConnectionName() string {
+	return "sqlite"
+}
+
+func TestListenerSignatureIsCheckedOnListen(t *testing.T) {
+	assert.PanicsWithValue(t, "listener for EventTransactionCommitted must accept goeloquent.TransactionCommittedEvent,got error", func() {
+		DB.Listen(goeloquent.EventTransactionCommitted, func(err error) {})
+	})
+	assert.Panics(t, func() {
+		DB.Listen(goeloquent.EventExecuted, func(result goeloquent.Result) int { return 0 })
+	})
+	assert.Panics(t, func() {
+		DB.Listen(goeloquent.EventSaving, "not a func")
+	})
+}
+
+func TestListenAndUnsubscribeConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	count := 0
+	subs := make([]*goeloquent.Subscription, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			subs[i] = DB.Listen("custom", func(payload interface{}) {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			})
+			DB.FireEvent("custom", i)
+		}(i)
+	}
+	wg.Wait()
+	count = 0
+	assert.Nil(t, DB.FireEvent("custom", nil))
+	assert.Equal(t, 20, count)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			subs[i].Unsubscribe()
+			subs[i].Unsubscribe()
+		}(i)
+	}
+	wg.Wait()
+	count = 0
+	assert.Nil(t, DB.FireEvent("custom", nil))
+	assert.Equal(t, 0, count)
+}
+
+func TestConnectionEventsAreFired(t *testing.T) {
+	c := GetSqliteConnection()
+	var events []string
+	var prepared []string
+	var failed []goeloquent.QueryErrorEvent
+	var committed []goeloquent.TransactionCommittedEvent
+	subs := []*goeloquent.Subscription{
+		DB.Listen(goeloquent.EventStatementPrepared, func(e goeloquent.StatementPreparedEvent) {
+			prepared = append(prepared, e.Sql)
+		}),
+		DB.Listen(goeloquent.EventErrorArised, func(e goeloquent.QueryErrorEvent) {
+			failed = append(failed, e)
+		}),
+		DB.Listen(goeloquent.EventTransactionBegin, func(e goeloquent.TransactionBeginEvent) {
+			events = append(events, "begin")
+		}),
+		DB.Listen(goeloquent.EventTransactionCommitted, func(e goeloquent.TransactionCommittedEvent) {
+			events = append(events, "committed")
+			committed = append(committed, e)
+		}),
+		DB.Listen(goeloquent.EventTransactionRollback, func(e goeloquent.TransactionRollbackEvent) {
+			events = append(events, "rollback")
+		}),
+	}
+	defer func() {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	}()
+
+	_, err := c.Statement("select 1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"select 1"}, prepared)
+
+	_, err = c.Statement("select * from missing_table", nil)
+	assert.NotNil(t, err)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "sqlite", failed[0].ConnectionName)
+	assert.Equal(t, err, failed[0].Error)
+
+	tx, err := c.BeginTransaction()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	tx, err = c.BeginTransaction()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	assert.Equal(t, []string{"begin", "committed", "begin", "rollback"}, events)
+	assert.Equal(t, tx.Connection, committed[0].Transaction.Connection)
+	assert.Nil(t, committed[0].Error)
+}
+
+func TestModelBootEventsAreFiredOnce(t *testing.T) {
+	var events []string
+	sub := DB.Listen(goeloquent.EventALL, func(e goeloquent.ModelEvent) {
+		if _, ok := e.Model.(*BootedPost); ok {
+			events = append(events, e.Name)
+		}
+	})
+	defer sub.Unsubscribe()
+	first := BootedPost{}
+	goeloquent.Init(&first)
+	second := BootedPost{}
+	goeloquent.Init(&second)
+	assert.Equal(t, []string{goeloquent.EventBooting, goeloquent.EventBoot, goeloquent.EventBooted, goeloquent.EventInitialized, goeloquent.EventInitialized}, events)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactBindings(t *testing.T) {
+	columns := []string{"password", "token"}
+	assert.Equal(t, []interface{}{"john", "[redacted]", 1}, goeloquent.RedactBindings(
+		`update "users" set "name" = ?, "password" = ? where "id" = ?`, []interface{}{"john", "secret", 1}, columns))
+	assert.Equal(t, []interface{}{"john", "[redacted]", "jane", "[redacted]"}, goeloquent.RedactBindings(
+		"insert into `users` (`name`, `password`) values (?, ?), (?, ?)", []interface{}{"john", "a", "jane", "b"}, columns))
+	assert.Equal(t, []interface{}{1, "[redacted]", "[redacted]"}, goeloquent.RedactBindings(
+		`select * from "users" where "id" = $1 and "users"."token" in ($2, $3)`, []interface{}{1, "a", "b"}, columns))
+	assert.Equal(t, []interface{}{"a", "b"}, goeloquent.RedactBindings(
+		`select * from "users" where "name" = 'password' and "email" = ? or "name" = ?`, []interface{}{"a", "b"}, columns))
+}
+
+func TestQueryLogger(t *testing.T) {
+	c := GetSqliteConnection()
+	var buf bytes.Buffer
+	config := *c.Config
+	defer func() {
+		*c.Config = config
+	}()
+	c.Config.EnableLog = true
+	c.Config.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	c.Config.RedactColumns = []string{"password"}
+
+	defer CreateSqliteTables(t, `create table "log_users" ("id" integer primary key autoincrement, "name" text, "password" text)`)()
+	buf.Reset()
+	//a map insert orders its columns by map iteration,so the statement is written out
+	_, err := c.Statement(`insert into "log_users" ("name", "password") values (?, ?)`, []interface{}{"john", "secret"})
+	assert.Nil(t, err)
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "query", record["msg"])
+	assert.Equal(t, "sqlite", record["connection"])
+	assert.Equal(t, `insert into "log_users" ("name", "password") values (?, ?)`, record["sql"])
+	assert.Equal(t, []interface{}{"john", "[redacted]"}, record["bindings"])
+	assert.Equal(t, float64(1), record["rows"])
+	assert.Contains(t, record, "duration")
+
+	buf.Reset()
+	_, err = c.Statement("select * from missing_table", nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Contains(t, record["error"], "no such table")
+
+	//slow queries are logged even if logging is off
+	c.Config.EnableLog = false
+	c.Config.SlowQueryThreshold = time.Nanosecond
+	buf.Reset()
+	_, err = c.Statement("select 1", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "slow query", record["msg"])
+
+	c.Config.SlowQueryThreshold = time.Hour
+	buf.Reset()
+	_, err = c.Statement("select 1", nil)
+	assert.Nil(t, err)
+	assert.Empty(t, strings.TrimSpace(buf.String()))
+}
+
+func TestQueryLogIsCollectedInMemory(t *testing.T) {
+	c := GetSqliteConnection()
+	DB.EnableQueryLog()
+	defer DB.FlushQueryLog()
+	_, err := c.Statement("select 1", nil)
+	assert.Nil(t, err)
+	var rows []map[string]interface{}
+	_, err = c.Select("select ? as a union select ?", []interface{}{1, 2}, &rows, nil)
+	assert.Nil(t, err)
+	tx, err := c.BeginTransaction()
+	assert.Nil(t, err)
+	_, err = tx.Statement("select 3", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	DB.DisableQueryLog()
+	_, err = c.Statement("select 4", nil)
+	assert.Nil(t, err)
+
+	logs := DB.GetQueryLog()
+	assert.Len(t, logs, 3)
+	assert.Equal(t, "select 1", logs[0].SQL)
+	assert.Equal(t, "sqlite", logs[0].ConnectionName)
+	assert.Equal(t, []interface{}{1, 2}, logs[1].Bindings)
+	assert.Equal(t, int64(2), logs[1].Rows)
+	assert.Equal(t, "select 3", logs[2].SQL)
+	DB.FlushQueryLog()
+	assert.Empty(t, DB.GetQueryLog())
+}
@@ -29,6 +29,7 @@ func TestInit(t *testing.T) {
 	assert.True(t, reflect.ValueOf(user2.EloquentModel).Elem().IsValid())
 }
 func TestCreateMethod(t *testing.T) {
+	RequireMysql(t)
 
 	info := UserInfo{
 		Verified: true,
@@ -108,6 +109,7 @@ func TestCreateMethod(t *testing.T) {
 	assert.Equal(t, len(r3.Bindings), 9)
 }
 func TestFindMethod(t *testing.T) {
+	RequireMysql(t)
 	//testFindMethod
 	DB.Raw().Exec("truncate table user_models")
 
@@ -151,6 +153,7 @@ func TestFindMethod(t *testing.T) {
 }
 
 func TestFirst(t *testing.T) {
+	RequireMysql(t)
 	//testFirstMethod
 	TestCreateMethod(t)
 	info := UserInfo{
@@ -181,6 +184,7 @@ func TestQualifyColumn(t *testing.T) {
 }
 
 func TestAttrs(t *testing.T) {
+	RequireMysql(t)
 	DB.Raw().Exec("truncate table user_models")
 
 	var u1 User
@@ -319,6 +323,7 @@ func (u *UserEvent) EloquentRetrieved() error {
 	return nil
 }
 func TestEvents(t *testing.T) {
+	RequireMysql(t)
 	DB.Raw().Exec("truncate table user_models")
 	DB.Raw().Exec("truncate table logs")
 	//test saving,saved
@@ -427,6 +432,7 @@ func TestEvents(t *testing.T) {
 	assert.Nil(t, e)
 }
 func TestTimeStamps(t *testing.T) {
+	RequireMysql(t)
 
 	CreateUsers()
 	//test timestamp is appended
@@ -475,6 +481,7 @@ func TestTimeStamps(t *testing.T) {
 	assert.Equal(t, u1.CreatedAt.Time.Unix(), u2.CreatedAt.Time.Unix())
 }
 func TestSoftDeletes(t *testing.T) {
+	RequireMysql(t)
 
 	var user User
 	DB.Init(&user)
@@ -517,17 +524,14 @@ func (u *UserDynamic) ResolveConnectionName(b *goeloquent.EloquentBuilder) strin
 	}
 }
 func TestDynamicResolver(t *testing.T) {
+	RequireMysql(t)
 
 	//test model dynamic table resolver
 	//test model dynamic connection resolver
 
-	assert.PanicsWithValuef(t, "Database connection users_2024 not configured.", func() {
-		var u2 UserDynamic
-		r, _ := DB.Model(&u2).Where("name", "a").First(&u2)
-		assert.Equal(t, r.Sql, "select * from `users_2024` where `name` = ? limit 1")
-
-	}, "Database connection users_2024 not configured.")
 	var u2 UserDynamic
+	_, err := DB.Model(&u2).Where("name", "a").First(&u2)
+	assert.EqualError(t, err, "Database connection users_2024 not configured.")
 	r, _ := DB.Model(&u2).WithContext(context.WithValue(context.Background(), "id", 1)).Where("name", "a").First(&u2)
 	assert.Equal(t, r.Sql, "select * from `users_1` where `name` = ? limit 1")
 
@@ -538,6 +542,7 @@ func TestPivotMapping(t *testing.T) {
 
 }
 func TestSaveMethod(t *testing.T) {
+	RequireMysql(t)
 	CreateUsers()
 
 	var u User
@@ -88,14 +88,14 @@ func (t *TableName) EloquentGetGuarded() map[string]struct{} {
 
 func TestParseModel(t *testing.T) {
 
-	parsed := goeloquent.GetParsedModel(&DefaultModel{})
+	parsed := goeloquent.MustParseModel(&DefaultModel{})
 	assert.Equal(t, "id", parsed.FieldsByStructName["ID"].ColumnName)
 	assert.Equal(t, "id", parsed.PrimaryKey.ColumnName)
 	assert.Equal(t, "name_alias", parsed.FieldsByStructName["Name"].ColumnName)
 	assert.Equal(t, 2, parsed.FieldsByStructName["Name"].Index)
 	assert.Equal(t, false, parsed.SoftDelete)
 
-	parsed1 := goeloquent.GetParsedModel(&TableName{})
+	parsed1 := goeloquent.MustParseModel(&TableName{})
 
 	assert.Equal(t, "t_name", parsed1.Table)
 	assert.Equal(t, "test", parsed1.ConnectionName)
@@ -120,7 +120,7 @@ func TestParseModel(t *testing.T) {
 
 }
 func TestParseRelation(t *testing.T) {
-	parsed1 := goeloquent.GetParsedModel(&TableName{})
+	parsed1 := goeloquent.MustParseModel(&TableName{})
 
 	assert.Contains(t, parsed1.EagerRelations, "Parent")
 
@@ -8,6 +8,7 @@ import (
 )
 
 func TestAggregate(t *testing.T) {
+	RequireMysql(t)
 	CreateUsers()
 	var u User
 	r, e := DB.Model(&u).WithMax("Posts", "status").Find(&u, 1)
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ObservedPost struct {
+	*goeloquent.EloquentModel
+	ID    int64  `goelo:"column:id;primaryKey"`
+	Title string `goelo:"column:title"`
+}
+
+func (p *ObservedPost) TableName() string {
+	return "observed_posts"
+}
+func (p *ObservedPost) ConnectionName() string {
+	return "sqlite"
+}
+
+type PostObserver struct {
+	Events []string
+}
+
+func (o *PostObserver) Saving(model interface{}) error {
+	if model.(*ObservedPost).Title == "" {
+		return errors.New("title required")
+	}
+	o.Events = append(o.Events, "saving")
+	return nil
+}
+func (o *PostObserver) Created(model interface{}) error {
+	o.Events = append(o.Events, "created")
+	return nil
+}
+func (o *PostObserver) Retrieved(model interface{}) error {
+	o.Events = append(o.Events, "retrieved:"+model.(*ObservedPost).Title)
+	return nil
+}
+
+func createObservedPosts(t *testing.T) func() {
+	return CreateSqliteTables(t, `create table "observed_posts" ("id" integer primary key autoincrement, "title" text)`)
+}
+
+func TestObserversAndModelEventListeners(t *testing.T) {
+	defer createObservedPosts(t)()
+	observer := &PostObserver{}
+	defer DB.Observe(&ObservedPost{}, observer).Unsubscribe()
+	var saving []string
+	defer DB.Listen(goeloquent.EventSaving, func(model interface{}) error {
+		saving = append(saving, model.(*ObservedPost).Title)
+		return nil
+	}).Unsubscribe()
+	var deleted int
+	defer DB.Listen(goeloquent.EventDeleted, goeloquent.ModelEventListener(func(model interface{}) error {
+		deleted++
+		return nil
+	})).Unsubscribe()
+
+	//an error from a "-ing" event aborts the save
+	post := ObservedPost{}
+	goeloquent.Init(&post)
+	_, err := post.Save()
+	assert.EqualError(t, err, "title required")
+	assert.Equal(t, int64(0), post.ID)
+
+	post.Title = "a"
+	_, err = post.Save()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"saving", "created"}, observer.Events)
+	assert.Equal(t, []string{"a"}, saving)
+
+	second := ObservedPost{Title: "b"}
+	goeloquent.Init(&second)
+	_, err = second.Save()
+	assert.Nil(t, err)
+
+	//retrieved fires for every scanned model
+	observer.Events = nil
+	var posts []*ObservedPost
+	_, err = DB.Model(&ObservedPost{}).OrderBy("id").Get(&posts)
+	assert.Nil(t, err)
+	var found ObservedPost
+	_, err = DB.Model(&found).Find(&found, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"retrieved:a", "retrieved:b", "retrieved:b"}, observer.Events)
+
+	_, err = found.Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	//muted events are not dispatched to observers and listeners
+	found.Mute(goeloquent.EventSaving)
+	found.Title = ""
+	_, err = found.Save()
+	assert.Nil(t, err)
+}
+
+type TypoObserver struct{}
+
+func (o *TypoObserver) Saving(post *ObservedPost) error {
+	return errors.New("never called")
+}
+
+func TestObserverMethodsAreCheckedOnRegistration(t *testing.T) {
+	assert.PanicsWithValue(t, "observer method Saving of *tests.TypoObserver must be func(model interface{}) error,got func(*tests.ObservedPost) error", func() {
+		DB.Observe(&ObservedPost{}, &TypoObserver{})
+	})
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func GetPostgresBuilder() *goeloquent.Builder {
+	return goeloquent.NewQueryBuilder(&goeloquent.Connection{Config: &goeloquent.DBConfig{Driver: goeloquent.DriverPostgres}})
+}
+
+func TestPostgresBasicSelect(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select("id", "name as n").From("users")
+	assert.Equal(t, `select "id", "name" as "n" from "users"`, b.ToSql())
+
+	b1 := GetPostgresBuilder()
+	b1.Grammar.SetTablePrefix("prefix_")
+	b1.Select("users.id").From("users")
+	assert.Equal(t, `select "prefix_users"."id" from "prefix_users"`, b1.ToSql())
+}
+
+func TestPostgresPlaceholders(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select().From("users").Where("id", 1).Where(func(builder *goeloquent.Builder) {
+		builder.Where("name", "ilike", "%foo%").OrWhere("email", "?")
+	}).WhereIn("role", []interface{}{"admin", "editor"}).Limit(10)
+	assert.Equal(t, `select * from "users" where "id" = $1 and ("name" ilike $2 or "email" = $3) and "role" in ($4,$5) limit 10`, b.ToSql())
+	assert.Equal(t, []interface{}{1, "%foo%", "?", "admin", "editor"}, b.GetBindings())
+
+	b1 := GetPostgresBuilder()
+	b1.Select().From("users").Where("name", "a?b").WhereIn("id", func(builder *goeloquent.Builder) {
+		builder.Select("user_id").From("posts").Where("votes", ">", 10)
+	}).WhereRaw(`"note" = '?'`)
+	assert.Equal(t, `select * from "users" where "name" = $1 and "id" in (select "user_id" from "posts" where "votes" > $2) and "note" = '?'`, b1.ToSql())
+}
+
+func TestPostgresJsonSelectors(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select("meta->address->city").From("users").Where("meta->age", ">", 18).Where("items->0->name", "foo")
+	assert.Equal(t, `select "meta"->'address'->>'city' from "users" where "meta"->>'age' > $1 and "items"->0->>'name' = $2`, b.ToSql())
+
+	b1 := GetPostgresBuilder()
+	b1.Select().From("users").WhereJsonContains("options", []string{"en"})
+	assert.Equal(t, `select * from "users" where ("options")::jsonb @> $1`, b1.ToSql())
+	assert.Equal(t, []interface{}{`["en"]`}, b1.GetBindings())
+}
+
+func TestPostgresDateBasedWheres(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select().From("users").WhereDate("created_at", "2021-01-01").WhereYear("created_at", ">", 2020).WhereMonth("created_at", "5")
+	assert.Equal(t, `select * from "users" where "created_at"::date = $1 and extract(year from "created_at") > $2 and extract(month from "created_at") = $3`, b.ToSql())
+}
+
+func TestPostgresInsertVariants(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Pretend()
+	b.From("users").InsertGetId(map[string]interface{}{"name": "foo"})
+	assert.Equal(t, `insert into "users" ("name") values ($1) returning "id"`, b.PreparedSql)
+
+	b1 := GetPostgresBuilder()
+	b1.Pretend()
+	b1.From("users").InsertOrIgnore(map[string]interface{}{"name": "foo"})
+	assert.Equal(t, `insert into "users" ("name") values ($1) on conflict do nothing`, b1.PreparedSql)
+
+	b2 := GetPostgresBuilder()
+	b2.Pretend()
+	b2.From("users").Where("id", 1).Update(map[string]interface{}{"name": "foo"})
+	assert.Equal(t, `update "users" set "name" = $1 where "id" = $2`, b2.PreparedSql)
+}
+
+func TestPostgresLockAndRandom(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select().From("users").Lock(false)
+	assert.Equal(t, `select * from "users" for share`, b.ToSql())
+
+	b1 := GetPostgresBuilder()
+	b1.Select().From("users").InRandomOrder()
+	assert.Equal(t, `select * from "users" order by RANDOM()`, b1.ToSql())
+}
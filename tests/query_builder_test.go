@@ -11,7 +11,7 @@ import (
 )
 
 func GetBuilder() *goeloquent.Builder {
-	return goeloquent.NewQueryBuilder(DB.Connection("default"))
+	return goeloquent.NewQueryBuilder(DB.MustConnection("default"))
 }
 
 func TestBasicSelect(t *testing.T) {
@@ -240,6 +240,7 @@ func TestDateBasedOrWheresAcceptsTwoArguments(t *testing.T) {
 }
 
 func TestWhereNested(t *testing.T) {
+	RequireMysql(t)
 
 	m := []map[string]interface{}{}
 	b := GetBuilder()
@@ -1085,6 +1086,7 @@ func TestRawExpressionsInSelect(t *testing.T) {
 	ShouldEqual(t, "select substr(foo,6) from `users`", b10)
 }
 func TestFindReturnsFirstResultByID(t *testing.T) {
+	RequireMysql(t)
 
 	var user = make(map[string]interface{})
 	b2 := DB.Query()
@@ -1151,6 +1153,7 @@ func TestSubqueriesBindings(t *testing.T) {
 	ElementsShouldMatch(t, []interface{}{"bar", 4, "%.com", "foo", 5}, f.GetBindings())
 }
 func TestInsertMethod(t *testing.T) {
+	RequireMysql(t)
 	//TestInsertMethod
 	createUsers, dropUsers := UserTableSql()
 	now := time.Now()
@@ -1195,6 +1198,7 @@ func TestInsertOrIgnoreUsingMethod(t *testing.T)  {}
 func TestInsertGetIdMethod(t *testing.T)          {}
 func TestInsertGetIdWithEmptyValues(t *testing.T) {}
 func TestInsertMethodRespectsRawBindings(t *testing.T) {
+	RequireMysql(t)
 	b4 := DB.Query().Pretend()
 	b4.From("users").Insert(map[string]interface{}{
 		"email": goeloquent.Raw("CURRENT TIMESTAMP"),
@@ -1220,6 +1224,7 @@ func TestInsertMethodRespectsRawBindings(t *testing.T) {
 	})
 }
 func TestMultipleInsertsWithExpressionValues(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	now := time.Now()
 	RunWithDB(createUsers, dropUsers, func() {
@@ -1238,6 +1243,7 @@ func TestMultipleInsertsWithExpressionValues(t *testing.T) {
 	})
 }
 func TestUpdateMethod(t *testing.T) {
+	RequireMysql(t)
 	//TestUpdateMethod
 	createUsers, dropUsers := UserTableSql()
 	now := time.Now()
@@ -1312,6 +1318,7 @@ func TestUpdateMethodRespectsRaw(t *testing.T)           {}
 func TestUpdateMethodWorksWithQueryAsValue(t *testing.T) {}
 func TestUpdateOrInsertMethod(t *testing.T)              {}
 func TestDeleteMethod(t *testing.T) {
+	RequireMysql(t)
 	//TestDeleteMethod
 	createUsers, dropUsers := UserTableSql()
 	now := time.Now()
@@ -1438,6 +1445,7 @@ func TestLowercaseLeadingBooleansAreRemoved(t *testing.T)       {}
 func TestCaseInsensitiveLeadingBooleansAreRemoved(t *testing.T) {}
 
 func TestChunkWithLastChunkComplete(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 		var ts []map[string]interface{}
@@ -1478,6 +1486,7 @@ func TestChunkPaginatesUsingIdWithCountZero(t *testing.T)         {}
 func TestChunkPaginatesUsingIdWithAlias(t *testing.T)             {}
 func TestChunkPaginatesUsingIdDesc(t *testing.T)                  {}
 func TestPaginate(t *testing.T) {
+	RequireMysql(t)
 	//TestPaginate
 	c, d := UserTableSql()
 
@@ -1635,6 +1644,7 @@ func TestCloneWithoutBindings(t *testing.T) {
 }
 
 func TestPluckMethod(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	now := time.Now()
 	u1 := map[string]interface{}{
@@ -1700,6 +1710,7 @@ func TestStruct(t *testing.T) {
 }
 
 func TestChunk(t *testing.T) {
+	RequireMysql(t)
 	//TestChunkWithLastChunkComplete
 	createUsers, dropUsers := UserTableSql()
 	//TestChunkCanBeStoppedByReturningError
@@ -1739,6 +1750,7 @@ func TestChunk(t *testing.T) {
 	})
 }
 func TestChunkById(t *testing.T) {
+	RequireMysql(t)
 	//TestChunkWithLastChunkComplete
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
@@ -1814,6 +1826,7 @@ func TestChunkById(t *testing.T) {
 	})
 }
 func TestBase(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 		var ts []map[string]interface{}
@@ -1897,6 +1910,7 @@ func TestBase(t *testing.T) {
 }
 
 func TestUpdateOrInsert(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 		var ts []map[string]interface{}
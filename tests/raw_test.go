@@ -6,6 +6,7 @@ import (
 )
 
 func TestRawMethods(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func openMemoryDB(t *testing.T, name string) *sql.DB {
+	db, err := sql.Open(string(goeloquent.DriverSqlite), ":memory:")
+	assert.Nil(t, err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`create table "hosts" ("id" integer primary key autoincrement, "name" text)`)
+	assert.Nil(t, err)
+	_, err = db.Exec(`insert into "hosts" ("name") values (?)`, name)
+	assert.Nil(t, err)
+	return db
+}
+
+func GetReadWriteConnection(t *testing.T, sticky bool) *goeloquent.Connection {
+	return &goeloquent.Connection{
+		DB:      openMemoryDB(t, "writer"),
+		ReadDBs: []*sql.DB{openMemoryDB(t, "reader")},
+		Config:  &goeloquent.DBConfig{Driver: goeloquent.DriverSqlite, Sticky: sticky},
+	}
+}
+
+func TestReadWriteSplitting(t *testing.T) {
+	c := GetReadWriteConnection(t, false)
+	var name string
+	_, err := c.Table("hosts").Where("id", 1).Value(&name, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "reader", name)
+
+	_, err = c.Table("hosts").Where("id", 1).UseWritePdo().Value(&name, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "writer", name)
+
+	_, err = c.Table("hosts").Where("id", 1).Lock().Value(&name, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "writer", name)
+
+	_, err = c.Table("hosts").Insert(map[string]interface{}{"name": "written"})
+	assert.Nil(t, err)
+	var count int
+	_, err = c.Table("hosts").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	_, err = c.Table("hosts").UseWritePdo().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestStickyReadsAfterWrite(t *testing.T) {
+	c := GetReadWriteConnection(t, true)
+	ctx := goeloquent.StickyContext(context.Background())
+	var count int
+	_, err := c.Table("hosts").WithContext(ctx).Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = c.Table("hosts").WithContext(ctx).Insert(map[string]interface{}{"name": "written"})
+	assert.Nil(t, err)
+	_, err = c.Table("hosts").WithContext(ctx).Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	//other requests keep reading from the read connection
+	_, err = c.Table("hosts").WithContext(goeloquent.StickyContext(context.Background())).Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
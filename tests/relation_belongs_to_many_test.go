@@ -13,6 +13,7 @@ import (
 )
 
 func TestBelongsToMany(t *testing.T) {
+	RequireMysql(t)
 
 	var rs []Role
 	//select `user_models`.*, `role_users`.`role_id` as `goelo_orm_pivot_role_id`, `role_users`.`user_id` as `goelo_orm_pivot_user_id`, `role_users`.`meta` as `goelo_pivot_meta`, `role_users`.`status` as `goelo_pivot_status`, `role_users`.`role_id` as `goelo_pivot_role_id`, `role_users`.`user_id` as `goelo_pivot_user_id` from `user_models` inner join `role_users` on `role_users`.`user_id` = `user_models`.`id` where `role_users`.`role_id` in (?,?,?) and (`user_models`.`status` = ? or `user_models`.`email` is not null) and `user_models`.`deleted_at` is null and `role_users`.`status` = ?
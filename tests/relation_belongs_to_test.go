@@ -8,6 +8,7 @@ import (
 )
 
 func TestBelongsTo(t *testing.T) {
+	RequireMysql(t)
 	CreateUsers()
 	//test get eager load
 	//select * from `user_models` where `user_models`.`id` is not null and `user_models`.`id` in (?,?,?,?,?,?,?,?) and `deleted_at` is null
@@ -11,6 +11,7 @@ func TestLoadingWithColumns(t *testing.T) {
 }
 
 func TestNestedRelation(t *testing.T) {
+	RequireMysql(t)
 	goeloquent.RegistMorphMap(map[string]interface{}{
 		"post": &Post{},
 	})
@@ -29,6 +30,7 @@ func TestNestedRelation(t *testing.T) {
 	}
 }
 func TestRelationPagination(t *testing.T) {
+	RequireMysql(t)
 	goeloquent.RegistMorphMap(map[string]interface{}{
 		"post": &Post{},
 	})
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ExistenceUser struct {
+	*goeloquent.EloquentModel
+	ID             int64  `goelo:"column:id;primaryKey"`
+	PublishedPosts []Post `goelo:"HasMany:PublishedPostsRelation"`
+}
+
+func (u *ExistenceUser) TableName() string {
+	return "user_models"
+}
+func (u *ExistenceUser) PublishedPostsRelation() *goeloquent.HasManyRelation {
+	r := u.HasMany(u, &Post{}, "id", "user_id")
+	r.Where("status", 1)
+	return r
+}
+
+func TestHas(t *testing.T) {
+	b := DB.Model(&User{}).Has("Posts")
+	assert.Equal(t, "select * from `user_models` where exists (select * from `posts` where `user_models`.`id` = `posts`.`user_id`)", b.ToSql())
+
+	b = DB.Model(&User{}).Where("status", 1).OrHas("Posts", ">=", 3)
+	assert.Equal(t, "select * from `user_models` where `status` = ? or (select count(*) from `posts` where `user_models`.`id` = `posts`.`user_id`) >= 3", b.ToSql())
+	assert.Equal(t, []interface{}{1}, b.GetBindings())
+
+	b = DB.Model(&User{}).DoesntHave("Posts")
+	assert.Equal(t, "select * from `user_models` where not exists (select * from `posts` where `user_models`.`id` = `posts`.`user_id`)", b.ToSql())
+
+	//related global scopes are applied
+	b = DB.Model(&Post{}).Has("User")
+	assert.Equal(t, "select * from `posts` where exists (select * from `user_models` where `user_models`.`id` = `posts`.`user_id` and `user_models`.`deleted_at` is null)", b.ToSql())
+}
+
+func TestWhereHas(t *testing.T) {
+	b := DB.Model(&User{}).WhereHas("Posts", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("status", 1)
+	}, ">", 2)
+	assert.Equal(t, "select * from `user_models` where (select count(*) from `posts` where `user_models`.`id` = `posts`.`user_id` and `status` = ?) > 2", b.ToSql())
+	assert.Equal(t, []interface{}{1}, b.GetBindings())
+
+	b = DB.Model(&User{}).Where("age", ">", 18).OrWhereDoesntHave("Posts", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("status", 1)
+	})
+	assert.Equal(t, "select * from `user_models` where `age` > ? or not exists (select * from `posts` where `user_models`.`id` = `posts`.`user_id` and `status` = ?)", b.ToSql())
+	assert.Equal(t, []interface{}{18, 1}, b.GetBindings())
+
+	b = DB.Model(&Role{}).WhereRelation("Users", "age", ">", 18)
+	assert.Equal(t, "select * from `roles` where exists (select * from `user_models` inner join `role_users` on `role_users`.`user_id` = `user_models`.`id` where `roles`.`id` = `role_users`.`role_id` and `age` > ? and `user_models`.`deleted_at` is null)", b.ToSql())
+	assert.Equal(t, []interface{}{18}, b.GetBindings())
+}
+
+func TestWhereHasNested(t *testing.T) {
+	goeloquent.RegistMorphMap(map[string]interface{}{
+		"image": &Image{},
+		"post":  &Post{},
+		"video": &Video{},
+		"user":  &User{},
+	})
+	b := DB.Model(&User{}).WhereHas("Posts.Images", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("size", ">", 10)
+	})
+	assert.Equal(t, "select * from `user_models` where exists (select * from `posts` where `user_models`.`id` = `posts`.`user_id` and exists (select * from `images` where `posts`.`id` = `images`.`imageable_id` and `images`.`imageable_type` = ? and `size` > ?))", b.ToSql())
+	assert.Equal(t, []interface{}{"post", 10}, b.GetBindings())
+
+	//only the outer relation is negated
+	b = DB.Model(&User{}).DoesntHave("Posts.Images")
+	assert.Equal(t, "select * from `user_models` where not exists (select * from `posts` where `user_models`.`id` = `posts`.`user_id` and exists (select * from `images` where `posts`.`id` = `images`.`imageable_id` and `images`.`imageable_type` = ?))", b.ToSql())
+}
+
+func TestWhereMorphRelation(t *testing.T) {
+	goeloquent.RegistMorphMap(map[string]interface{}{
+		"image": &Image{},
+		"post":  &Post{},
+		"video": &Video{},
+		"user":  &User{},
+	})
+	b := DB.Model(&Image{}).WhereMorphRelation("Imageable", []string{"post", "user"}, "status", 1)
+	assert.Equal(t, "select * from `images` where ((`images`.`imageable_type` = ? and exists (select * from `posts` where `posts`.`id` = `images`.`imageable_id` and `status` = ?)) or (`images`.`imageable_type` = ? and exists (select * from `user_models` where `user_models`.`id` = `images`.`imageable_id` and `status` = ? and `user_models`.`deleted_at` is null)))", b.ToSql())
+	assert.Equal(t, []interface{}{"post", 1, "user", 1}, b.GetBindings())
+
+	var images []Image
+	_, err := DB.Model(&Image{}).Has("Imageable").Pretend().Get(&images)
+	assert.EqualError(t, err, "relation [Imageable] is a MorphTo relation,use WhereHasMorph instead")
+	_, err = DB.Model(&Post{}).WhereHasMorph("User", []string{"user"}, nil).Pretend().Get(&images)
+	assert.EqualError(t, err, "relation [User] is not a MorphTo relation")
+	_, err = DB.Model(&Post{}).Has("Missing").Pretend().Get(&images)
+	assert.EqualError(t, err, "Relation method [Missing] not found in model:[Post]")
+	_, err = DB.Model(&Image{}).WhereHasMorph("Imageable", []string{"post", "missing"}, nil).Pretend().Get(&images)
+	assert.EqualError(t, err, "no registered model found for morph type missing")
+}
+
+func TestHasCountTypes(t *testing.T) {
+	b := DB.Model(&User{}).Has("Posts", ">", int64(2))
+	assert.Equal(t, "select * from `user_models` where (select count(*) from `posts` where `user_models`.`id` = `posts`.`user_id`) > 2", b.ToSql())
+	b = DB.Model(&User{}).Has("Posts", "<=", uint8(3))
+	assert.Equal(t, "select * from `user_models` where (select count(*) from `posts` where `user_models`.`id` = `posts`.`user_id`) <= 3", b.ToSql())
+
+	var users []User
+	_, err := DB.Model(&User{}).Has("Posts", ">", "2").Pretend().Get(&users)
+	assert.EqualError(t, err, "the count of a relation existence query must be an integer,got string")
+	_, err = DB.Model(&User{}).WhereHas("Posts", nil, 1).Pretend().Get(&users)
+	assert.EqualError(t, err, "the operator of a relation existence query must be a string,got int")
+	_, err = DB.Model(&User{}).Has("Posts", "; drop table posts --", 1).Pretend().Get(&users)
+	assert.EqualError(t, err, `invalid operator "; drop table posts --" for a relation existence query`)
+	b = DB.Model(&User{}).Has("Posts", "<>", 2)
+	assert.Nil(t, b.Err)
+}
+
+func TestHasKeepsClausesAddedAfterTheConstraints(t *testing.T) {
+	b := DB.Model(&ExistenceUser{}).Has("PublishedPosts")
+	assert.Equal(t, "select * from `user_models` where exists (select * from `posts` where `status` = ? and `user_models`.`id` = `posts`.`user_id`)", b.ToSql())
+	assert.Equal(t, []interface{}{1}, b.GetBindings())
+}
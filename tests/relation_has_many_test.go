@@ -9,6 +9,7 @@ import (
 )
 
 func TestHasMany(t *testing.T) {
+	RequireMysql(t)
 	CreateUsers()
 	//test get eager load
 	//select * from `posts` where `posts`.`user_id` is not null and `posts`.`user_id` in (?,?,?,?,?)
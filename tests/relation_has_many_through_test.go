@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"database/sql"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type ThroughCountry struct {
+	*goeloquent.EloquentModel
+	ID         int64          `goelo:"column:id;primaryKey"`
+	Name       string         `goelo:"column:name"`
+	Posts      []*ThroughPost `goelo:"HasManyThrough:PostsRelation"`
+	LatestPost ThroughPost    `goelo:"HasOneThrough:LatestPostRelation"`
+	LivePosts  []ThroughPost  `goelo:"HasManyThrough:LivePostsRelation"`
+}
+
+func (c *ThroughCountry) TableName() string {
+	return "through_countries"
+}
+func (c *ThroughCountry) ConnectionName() string {
+	return "sqlite"
+}
+func (c *ThroughCountry) PostsRelation() *goeloquent.HasManyThrough {
+	return c.HasManyThrough(c, &ThroughPost{}, &ThroughUser{}, "country_id", "user_id", "id", "id")
+}
+func (c *ThroughCountry) LatestPostRelation() *goeloquent.HasOneThrough {
+	rb := c.HasOneThrough(c, &ThroughPost{}, &ThroughUser{}, "country_id", "user_id", "id", "id")
+	rb.OrderBy("through_posts.id", "desc")
+	return rb
+}
+func (c *ThroughCountry) LivePostsRelation() *goeloquent.HasManyThrough {
+	return c.HasManyThrough(c, &ThroughPost{}, &SoftThroughUser{}, "country_id", "user_id", "id", "id")
+}
+
+type ThroughUser struct {
+	*goeloquent.EloquentModel
+	ID        int64  `goelo:"column:id;primaryKey"`
+	CountryId int64  `goelo:"column:country_id"`
+	Name      string `goelo:"column:name"`
+}
+
+func (u *ThroughUser) TableName() string {
+	return "through_users"
+}
+func (u *ThroughUser) ConnectionName() string {
+	return "sqlite"
+}
+
+type SoftThroughUser struct {
+	*goeloquent.EloquentModel
+	ID        int64        `goelo:"column:id;primaryKey"`
+	CountryId int64        `goelo:"column:country_id"`
+	DeletedAt sql.NullTime `goelo:"column:deleted_at;DELETED_AT"`
+}
+
+func (u *SoftThroughUser) TableName() string {
+	return "through_users"
+}
+func (u *SoftThroughUser) ConnectionName() string {
+	return "sqlite"
+}
+
+type ThroughPost struct {
+	*goeloquent.EloquentModel
+	ID     int64  `goelo:"column:id;primaryKey"`
+	UserId int64  `goelo:"column:user_id"`
+	Title  string `goelo:"column:title"`
+	Votes  int    `goelo:"column:votes"`
+}
+
+func (p *ThroughPost) TableName() string {
+	return "through_posts"
+}
+func (p *ThroughPost) ConnectionName() string {
+	return "sqlite"
+}
+
+func createThroughTables(t *testing.T) func() {
+	drop := CreateSqliteTables(t,
+		`create table "through_countries" ("id" integer primary key autoincrement, "name" text)`,
+		`create table "through_users" ("id" integer primary key autoincrement, "country_id" integer, "name" text, "deleted_at" datetime)`,
+		`create table "through_posts" ("id" integer primary key autoincrement, "user_id" integer, "title" text, "votes" integer)`,
+	)
+	c := GetSqliteConnection()
+	_, err := c.Table("through_countries").Insert([]map[string]interface{}{{"name": "jp"}, {"name": "us"}, {"name": "uk"}})
+	assert.Nil(t, err)
+	_, err = c.Table("through_users").Insert([]map[string]interface{}{
+		{"country_id": 1, "name": "a"}, {"country_id": 1, "name": "b"}, {"country_id": 2, "name": "c"},
+	})
+	assert.Nil(t, err)
+	_, err = c.Table("through_posts").Insert([]map[string]interface{}{
+		{"user_id": 1, "title": "a1", "votes": 1}, {"user_id": 2, "title": "b1", "votes": 2},
+		{"user_id": 2, "title": "b2", "votes": 3}, {"user_id": 3, "title": "c1", "votes": 4},
+	})
+	assert.Nil(t, err)
+	return drop
+}
+
+func TestHasManyThrough(t *testing.T) {
+	defer createThroughTables(t)()
+
+	//lazy load
+	var country ThroughCountry
+	_, err := DB.Model(&country).Find(&country, 1)
+	assert.Nil(t, err)
+	var posts []ThroughPost
+	rb := country.PostsRelation()
+	assert.Equal(t, `select "through_posts".*, "through_users"."country_id" as "goelo_orm_pivot_country_id" from "through_posts" inner join "through_users" on "through_users"."id" = "through_posts"."user_id" where "through_users"."country_id" = ?`, rb.ToSql())
+	_, err = rb.OrderBy("through_posts.id").Get(&posts)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(posts))
+	assert.Equal(t, "a1", posts[0].Title)
+	assert.Equal(t, "b2", posts[2].Title)
+
+	//eager load
+	var countries []ThroughCountry
+	_, err = DB.Model(&ThroughCountry{}).With("Posts", "LatestPost").OrderBy("id").Get(&countries)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(countries))
+	assert.Equal(t, 3, len(countries[0].Posts))
+	assert.Equal(t, "b2", countries[0].LatestPost.Title)
+	assert.Equal(t, 1, len(countries[1].Posts))
+	assert.Equal(t, "c1", countries[1].Posts[0].Title)
+	assert.Equal(t, "c1", countries[1].LatestPost.Title)
+	assert.Equal(t, 0, len(countries[2].Posts))
+	assert.Nil(t, countries[2].LatestPost.EloquentModel)
+}
+
+func TestHasManyThroughAggregatesAndExistence(t *testing.T) {
+	defer createThroughTables(t)()
+
+	var countries []ThroughCountry
+	_, err := DB.Model(&ThroughCountry{}).WithCount("Posts").OrderBy("id").Get(&countries)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), countries[0].WithAggregates["PostsCount"])
+	assert.Equal(t, float64(1), countries[1].WithAggregates["PostsCount"])
+	assert.Equal(t, float64(0), countries[2].WithAggregates["PostsCount"])
+
+	var withPosts []ThroughCountry
+	_, err = DB.Model(&ThroughCountry{}).Has("Posts").WithSum("Posts", "votes").OrderBy("id").Get(&withPosts)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(withPosts))
+	assert.Equal(t, float64(6), withPosts[0].WithAggregates["PostsSumVotes"])
+	assert.Equal(t, float64(4), withPosts[1].WithAggregates["PostsSumVotes"])
+
+	b := DB.Model(&ThroughCountry{}).WhereHas("Posts", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("votes", ">", 3)
+	})
+	assert.Equal(t, `select * from "through_countries" where exists (select * from "through_posts" inner join "through_users" on "through_users"."id" = "through_posts"."user_id" where "through_countries"."id" = "through_users"."country_id" and "votes" > ?)`, b.ToSql())
+	var names []ThroughCountry
+	_, err = b.Get(&names)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(names))
+	assert.Equal(t, "us", names[0].Name)
+
+	var count int
+	_, err = DB.Model(&ThroughCountry{}).DoesntHave("LatestPost").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestHasManyThroughSkipsSoftDeletedThroughModels(t *testing.T) {
+	defer createThroughTables(t)()
+	_, err := GetSqliteConnection().Table("through_users").Where("id", 2).Update(map[string]interface{}{"deleted_at": "2024-01-01 00:00:00"})
+	assert.Nil(t, err)
+
+	var country ThroughCountry
+	_, err = DB.Model(&country).Find(&country, 1)
+	assert.Nil(t, err)
+	var posts []ThroughPost
+	_, err = country.LivePostsRelation().Get(&posts)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(posts))
+
+	var countries []ThroughCountry
+	_, err = DB.Model(&ThroughCountry{}).With("LivePosts").OrderBy("id").Get(&countries)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(countries[0].LivePosts))
+	assert.Equal(t, "a1", countries[0].LivePosts[0].Title)
+
+	b := DB.Model(&ThroughCountry{}).Has("LivePosts", ">", 1)
+	assert.Equal(t, `select * from "through_countries" where (select count(*) from "through_posts" inner join "through_users" on "through_users"."id" = "through_posts"."user_id" where "through_users"."deleted_at" is null and "through_countries"."id" = "through_users"."country_id") > 1`, b.ToSql())
+	var count int
+	_, err = b.Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
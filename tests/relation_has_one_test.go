@@ -7,6 +7,7 @@ import (
 )
 
 func TestHasOne(t *testing.T) {
+	RequireMysql(t)
 
 	CreateUsers()
 	//test get eager load
@@ -7,6 +7,7 @@ import (
 )
 
 func TestMorphOne(t *testing.T) {
+	RequireMysql(t)
 
 	//select * from `images` where `imageable_type` = ? and `imageable_id` is not null and `imageable_id` in (?,?,?,?,?) and `driver` = ?
 	//[user 1 2 3 4 5 s3]
@@ -8,6 +8,7 @@ import (
 )
 
 func TestMorphToMany(t *testing.T) {
+	RequireMysql(t)
 	//select `tags`.*, `tagables`.`tag_id` as `goelo_orm_pivot_tag_id`, `tagables`.`tagable_id` as `goelo_orm_pivot_tagable_id`, `tagables`.`tag_url` as `goelo_pivot_tag_url`, `tagables`.`show_in_list` as `goelo_pivot_show_in_list` from `tags` inner join `tagables` on `tagables`.`tag_id` = `tags`.`id` where `tagable_type` = ? and `tagables`.`tag_id` in (?,?) and (`tags`.`related` > ? or `tags`.`id` < ?) and `show_in_list` = ?
 	//[post 1 2 0 3 1]
 	var ps []Post
@@ -7,6 +7,7 @@ import (
 )
 
 func TestMorphTo(t *testing.T) {
+	RequireMysql(t)
 	goeloquent.RegistMorphMap(map[string]interface{}{
 		"image": &Image{},
 		"post":  &Post{},
@@ -9,6 +9,7 @@ import (
 )
 
 func TestMorphedByMany(t *testing.T) {
+	RequireMysql(t)
 
 	goeloquent.RegistMorphMap(map[string]interface{}{
 		"image": &Image{},
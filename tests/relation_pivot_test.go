@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"database/sql"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type PivotRole struct {
+	*goeloquent.EloquentModel
+	ID    int64       `goelo:"column:id;primaryKey"`
+	Name  string      `goelo:"column:name"`
+	Users []PivotUser `goelo:"BelongsToMany:UsersRelation"`
+}
+
+func (r *PivotRole) TableName() string {
+	return "pivot_roles"
+}
+func (r *PivotRole) ConnectionName() string {
+	return "sqlite"
+}
+func (r *PivotRole) UsersRelation() *goeloquent.BelongsToManyRelation {
+	rb := r.BelongsToMany(r, &PivotUser{}, "pivot_role_users", "role_id", "user_id", "id", "id")
+	rb.WithTimestamps = true
+	return rb
+}
+
+type PivotUser struct {
+	*goeloquent.EloquentModel
+	ID   int64  `goelo:"column:id;primaryKey"`
+	Name string `goelo:"column:name"`
+}
+
+func (u *PivotUser) TableName() string {
+	return "pivot_users"
+}
+func (u *PivotUser) ConnectionName() string {
+	return "sqlite"
+}
+
+type pivotRecord struct {
+	RoleId    int64  `goelo:"column:role_id"`
+	UserId    int64  `goelo:"column:user_id"`
+	Status    int    `goelo:"column:status"`
+	CreatedAt string `goelo:"column:created_at"`
+}
+
+func createPivotTables(t *testing.T) func() {
+	drop := CreateSqliteTables(t,
+		`create table "pivot_roles" ("id" integer primary key autoincrement, "name" text)`,
+		`create table "pivot_users" ("id" integer primary key autoincrement, "name" text)`,
+		`create table "pivot_role_users" ("role_id" integer, "user_id" integer, "status" integer default 0, "created_at" datetime, "updated_at" datetime)`,
+	)
+	c := GetSqliteConnection()
+	_, err := c.Table("pivot_roles").Insert(map[string]interface{}{"name": "admin"})
+	assert.Nil(t, err)
+	_, err = c.Table("pivot_users").Insert([]map[string]interface{}{{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}})
+	assert.Nil(t, err)
+	return drop
+}
+
+func pivotRecords(t *testing.T) []pivotRecord {
+	var records []pivotRecord
+	_, err := GetSqliteConnection().Table("pivot_role_users").OrderBy("user_id").Get(&records)
+	assert.Nil(t, err)
+	return records
+}
+
+func TestAttachDetach(t *testing.T) {
+	defer createPivotTables(t)()
+	var role PivotRole
+	_, err := DB.Model(&role).Find(&role, 1)
+	assert.Nil(t, err)
+
+	_, err = role.UsersRelation().Attach(1, map[string]interface{}{"status": 2})
+	assert.Nil(t, err)
+	_, err = role.UsersRelation().Attach([]int64{2, 3})
+	assert.Nil(t, err)
+	records := pivotRecords(t)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, pivotRecord{RoleId: 1, UserId: 1, Status: 2, CreatedAt: records[0].CreatedAt}, records[0])
+	assert.NotEmpty(t, records[0].CreatedAt)
+
+	var users []PivotUser
+	_, err = role.UsersRelation().OrderBy("pivot_users.id").Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(users))
+
+	_, err = role.UsersRelation().UpdateExistingPivot(2, map[string]interface{}{"status": 5})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, pivotRecords(t)[1].Status)
+
+	_, err = role.UsersRelation().Detach(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pivotRecords(t)))
+	_, err = role.UsersRelation().Detach()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pivotRecords(t)))
+}
+
+func TestSyncToggle(t *testing.T) {
+	defer createPivotTables(t)()
+	var role PivotRole
+	_, err := DB.Model(&role).Find(&role, 1)
+	assert.Nil(t, err)
+	_, err = role.UsersRelation().Attach([]int64{1, 2})
+	assert.Nil(t, err)
+
+	changes, err := role.UsersRelation().Sync(map[int64]map[string]interface{}{2: {"status": 1}, 3: {"status": 3}})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{int64(3)}, changes.Attached)
+	assert.Equal(t, []interface{}{int64(1)}, changes.Detached)
+	assert.Equal(t, []interface{}{int64(2)}, changes.Updated)
+	records := pivotRecords(t)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, 1, records[0].Status)
+	assert.Equal(t, 3, records[1].Status)
+
+	changes, err = role.UsersRelation().SyncWithoutDetaching([]int64{1})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{int64(1)}, changes.Attached)
+	assert.Nil(t, changes.Detached)
+	assert.Equal(t, 3, len(pivotRecords(t)))
+
+	changes, err = role.UsersRelation().Toggle([]int64{1, 4})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{int64(4)}, changes.Attached)
+	assert.Equal(t, []interface{}{int64(1)}, changes.Detached)
+	records = pivotRecords(t)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, int64(4), records[2].UserId)
+}
+
+type PivotTeam struct {
+	*goeloquent.EloquentModel
+	ID      int64        `goelo:"column:id;primaryKey"`
+	Created sql.NullTime `goelo:"column:created;CREATED_AT"`
+	Users   []PivotUser  `goelo:"BelongsToMany:UsersRelation"`
+}
+
+func (r *PivotTeam) TableName() string {
+	return "pivot_roles"
+}
+func (r *PivotTeam) ConnectionName() string {
+	return "sqlite"
+}
+func (r *PivotTeam) UsersRelation() *goeloquent.BelongsToManyRelation {
+	rb := r.BelongsToMany(r, &PivotUser{}, "pivot_role_users", "role_id", "user_id", "id", "id")
+	rb.WithTimestamps = true
+	return rb
+}
+
+func TestPivotTimestampColumns(t *testing.T) {
+	defer createPivotTables(t)()
+	c := GetSqliteConnection()
+	_, err := c.Statement(`alter table "pivot_roles" add column "created" datetime`, nil)
+	assert.Nil(t, err)
+	_, err = c.Statement(`alter table "pivot_role_users" add column "attached_at" datetime`, nil)
+	assert.Nil(t, err)
+	var team PivotTeam
+	_, err = DB.Model(&team).Find(&team, 1)
+	assert.Nil(t, err)
+	//the created_at column of the self model isn't used for the pivot table
+	_, err = team.UsersRelation().Attach(1)
+	assert.Nil(t, err)
+	var count int
+	_, err = c.Table("pivot_role_users").Where("user_id", 1).WhereNotNull("created_at").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	rb := team.UsersRelation()
+	rb.PivotCreatedAt = "attached_at"
+	_, err = rb.Attach(2)
+	assert.Nil(t, err)
+	_, err = c.Table("pivot_role_users").Where("user_id", 2).WhereNotNull("attached_at").WhereNull("created_at").WhereNotNull("updated_at").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSyncRunsInTransaction(t *testing.T) {
+	defer createPivotTables(t)()
+	var role PivotRole
+	_, err := DB.Model(&role).Find(&role, 1)
+	assert.Nil(t, err)
+	_, err = role.UsersRelation().Attach([]int64{1, 2})
+	assert.Nil(t, err)
+
+	//attaching 3 fails after 1 and 2 are detached,the detach is rolled back
+	_, err = role.UsersRelation().Sync(map[int64]map[string]interface{}{3: {"missing": 1}})
+	assert.NotNil(t, err)
+	records := pivotRecords(t)
+	assert.Equal(t, 2, len(records))
+	_, err = role.UsersRelation().Toggle(map[int64]map[string]interface{}{1: {}, 3: {"missing": 1}})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(pivotRecords(t)))
+}
+
+func TestAttachInTransaction(t *testing.T) {
+	defer createPivotTables(t)()
+	var role PivotRole
+	_, err := DB.Model(&role).Find(&role, 1)
+	assert.Nil(t, err)
+
+	tx, err := GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	role.Tx = tx
+	_, err = role.UsersRelation().Attach([]int64{1, 2})
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	role.Tx = nil
+	assert.Equal(t, 0, len(pivotRecords(t)))
+}
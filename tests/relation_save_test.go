@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type SaveUser struct {
+	*goeloquent.EloquentModel
+	ID     int64       `goelo:"column:id;primaryKey"`
+	Name   string      `goelo:"column:name"`
+	Posts  []SavePost  `goelo:"HasMany:PostsRelation"`
+	Avatar *SaveImage  `goelo:"MorphOne:AvatarRelation"`
+	Images []SaveImage `goelo:"MorphMany:ImagesRelation"`
+}
+
+func (u *SaveUser) TableName() string {
+	return "save_users"
+}
+func (u *SaveUser) ConnectionName() string {
+	return "sqlite"
+}
+func (u *SaveUser) PostsRelation() *goeloquent.HasManyRelation {
+	return u.HasMany(u, &SavePost{}, "id", "user_id")
+}
+func (u *SaveUser) AvatarRelation() *goeloquent.MorphOneRelation {
+	return u.MorphOne(u, &SaveImage{}, "id", "imageable_id", "imageable_type")
+}
+func (u *SaveUser) ImagesRelation() *goeloquent.MorphManyRelation {
+	return u.MorphMany(u, &SaveImage{}, "id", "imageable_id", "imageable_type")
+}
+
+type SavePost struct {
+	*goeloquent.EloquentModel
+	ID     int64     `goelo:"column:id;primaryKey"`
+	UserId int64     `goelo:"column:user_id"`
+	Title  string    `goelo:"column:title"`
+	User   *SaveUser `goelo:"BelongsTo:UserRelation"`
+}
+
+func (p *SavePost) TableName() string {
+	return "save_posts"
+}
+func (p *SavePost) ConnectionName() string {
+	return "sqlite"
+}
+func (p *SavePost) UserRelation() *goeloquent.BelongsToRelation {
+	return p.BelongsTo(p, &SaveUser{}, "user_id", "id")
+}
+
+type SaveImage struct {
+	*goeloquent.EloquentModel
+	ID            int64       `goelo:"column:id;primaryKey"`
+	Url           string      `goelo:"column:url"`
+	ImageableId   int64       `goelo:"column:imageable_id"`
+	ImageableType string      `goelo:"column:imageable_type"`
+	Imageable     interface{} `goelo:"MorphTo:ImageableRelation"`
+}
+
+func (i *SaveImage) TableName() string {
+	return "save_images"
+}
+func (i *SaveImage) ConnectionName() string {
+	return "sqlite"
+}
+func (i *SaveImage) ImageableRelation() *goeloquent.MorphToRelation {
+	return i.MorphTo(i, "imageable_id", "imageable_type", "id")
+}
+
+func createSaveTables(t *testing.T) func() {
+	goeloquent.RegistMorphMap(map[string]interface{}{
+		"save_user": &SaveUser{},
+		"save_post": &SavePost{},
+	})
+	drop := CreateSqliteTables(t,
+		`create table "save_users" ("id" integer primary key autoincrement, "name" text)`,
+		`create table "save_posts" ("id" integer primary key autoincrement, "user_id" integer, "title" text)`,
+		`create table "save_images" ("id" integer primary key autoincrement, "url" text, "imageable_id" integer, "imageable_type" text)`,
+	)
+	_, err := GetSqliteConnection().Table("save_users").Insert([]map[string]interface{}{{"name": "a"}, {"name": "b"}})
+	assert.Nil(t, err)
+	return drop
+}
+
+func TestRelationSaveAndCreate(t *testing.T) {
+	defer createSaveTables(t)()
+	var user SaveUser
+	_, err := DB.Model(&user).Find(&user, 1)
+	assert.Nil(t, err)
+
+	post := SavePost{Title: "first"}
+	_, err = user.PostsRelation().Save(&post)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), post.UserId)
+	assert.True(t, post.ID > 0)
+
+	err = user.PostsRelation().SaveMany([]*SavePost{{Title: "second"}, {Title: "third"}})
+	assert.Nil(t, err)
+	created, err := user.PostsRelation().Create(map[string]interface{}{"title": "fourth"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), created.(*SavePost).UserId)
+	_, err = user.PostsRelation().CreateMany([]map[string]interface{}{{"title": "fifth"}})
+	assert.Nil(t, err)
+
+	var posts []SavePost
+	_, err = user.PostsRelation().Get(&posts)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(posts))
+
+	avatar, err := user.AvatarRelation().Create(map[string]interface{}{"url": "a.png"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), avatar.(*SaveImage).ImageableId)
+	assert.Equal(t, "save_user", avatar.(*SaveImage).ImageableType)
+	err = user.ImagesRelation().SaveMany(&[]SaveImage{{Url: "b.png"}, {Url: "c.png"}})
+	assert.Nil(t, err)
+	var count int
+	_, err = user.ImagesRelation().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestRelationAssociate(t *testing.T) {
+	defer createSaveTables(t)()
+	var user SaveUser
+	_, err := DB.Model(&user).Find(&user, 2)
+	assert.Nil(t, err)
+
+	post := SavePost{Title: "orphan"}
+	_, err = post.UserRelation().Associate(&user).Save()
+	assert.Nil(t, err)
+	var saved SavePost
+	_, err = DB.Model(&saved).With("User").Find(&saved, post.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", saved.User.Name)
+
+	_, err = saved.UserRelation().Dissociate().Save()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), saved.UserId)
+
+	image := SaveImage{Url: "d.png"}
+	image.ImageableRelation().Associate(&saved)
+	assert.Equal(t, saved.ID, image.ImageableId)
+	assert.Equal(t, "save_post", image.ImageableType)
+	image.ImageableRelation().Dissociate()
+	assert.Equal(t, int64(0), image.ImageableId)
+	assert.Equal(t, "", image.ImageableType)
+}
+
+func TestPush(t *testing.T) {
+	defer createSaveTables(t)()
+	var user SaveUser
+	_, err := DB.Model(&user).Find(&user, 1)
+	assert.Nil(t, err)
+	_, err = user.PostsRelation().CreateMany([]map[string]interface{}{{"title": "a"}, {"title": "b"}})
+	assert.Nil(t, err)
+
+	var loaded SaveUser
+	_, err = DB.Model(&loaded).With("Posts").Find(&loaded, 1)
+	assert.Nil(t, err)
+	loaded.Name = "pushed"
+	loaded.Posts[0].Title = "a1"
+	loaded.Posts[1].Title = "b1"
+	assert.Nil(t, loaded.Push())
+
+	var titles []string
+	_, err = GetSqliteConnection().Table("save_posts").OrderBy("id").Pluck(&titles, "title")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a1", "b1"}, titles)
+	var name string
+	_, err = GetSqliteConnection().Table("save_users").Where("id", 1).Value(&name, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "pushed", name)
+}
+
+func TestPushCreatesNewRelatedModels(t *testing.T) {
+	defer createSaveTables(t)()
+	var user SaveUser
+	_, err := DB.Model(&user).With("Posts").Find(&user, 1)
+	assert.Nil(t, err)
+	user.Posts = append(user.Posts, SavePost{Title: "new", UserId: user.ID})
+	user.Avatar = &SaveImage{Url: "a.png", ImageableId: user.ID, ImageableType: "SaveUser"}
+	assert.Nil(t, user.Push())
+	assert.True(t, user.Posts[0].Exists)
+	assert.NotZero(t, user.Posts[0].ID)
+	assert.True(t, user.Avatar.Exists)
+
+	var posts []SavePost
+	_, err = DB.Model(&SavePost{}).Where("user_id", user.ID).Get(&posts)
+	assert.Nil(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, "new", posts[0].Title)
+	var count int
+	_, err = GetSqliteConnection().Table("save_images").Where("url", "a.png").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -60,6 +60,7 @@ func (j *JsonStruct) Scan(src any) error {
 
 }
 func TestBasicScan(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 		DB.Raw("default").Exec("insert into `users` (`name`,`age`) values ('Alice',33)")
@@ -124,6 +125,7 @@ func TestBasicScan(t *testing.T) {
 }
 
 func TestScanInterface(t *testing.T) {
+	RequireMysql(t)
 	createUsers, dropUsers := UserTableSql()
 	RunWithDB(createUsers, dropUsers, func() {
 
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type ScopedUser struct {
+	*goeloquent.EloquentModel
+	ID        int64     `goelo:"column:id;primaryKey"`
+	Name      string    `goelo:"column:name"`
+	Status    int       `goelo:"column:status"`
+	DeletedAt time.Time `goelo:"column:deleted_at;DELETED_AT"`
+}
+
+func (u *ScopedUser) TableName() string {
+	return "scoped_users"
+}
+func (u *ScopedUser) EloquentAddGlobalScopes() map[string]goeloquent.ScopeFunc {
+	return map[string]goeloquent.ScopeFunc{
+		"Visible": func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+			return builder.Where("visible", 1)
+		},
+	}
+}
+func (u *ScopedUser) ScopeActive(builder *goeloquent.EloquentBuilder, args ...interface{}) *goeloquent.EloquentBuilder {
+	return builder.Where("status", 1)
+}
+func (u *ScopedUser) ScopeOfType(builder *goeloquent.EloquentBuilder, t string) {
+	builder.Where("type", t)
+}
+func (u *ScopedUser) ScopeOlderThan(builder *goeloquent.EloquentBuilder, ages ...int) {
+	for _, age := range ages {
+		builder.Where("age", ">", age)
+	}
+}
+
+func TestLocalScopes(t *testing.T) {
+	var users []ScopedUser
+	b := DB.Model(&ScopedUser{}).Scope("Active").Scope("OfType", "admin").WithOutGlobalScopes()
+	_, err := b.Pretend().Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from `scoped_users` where `status` = ? and `type` = ?", b.PreparedSql)
+	assert.Equal(t, []interface{}{1, "admin"}, b.GetBindings())
+
+	b = DB.Model(&ScopedUser{}).Scope("OlderThan", 18, 20).WithOutGlobalScopes()
+	_, err = b.Pretend().Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from `scoped_users` where `age` > ? and `age` > ?", b.PreparedSql)
+	assert.Equal(t, []interface{}{18, 20}, b.GetBindings())
+
+	_, err = DB.Model(&ScopedUser{}).Scope("Missing").Pretend().Get(&users)
+	assert.EqualError(t, err, "scope Missing on model ScopedUser not found")
+	_, err = DB.Model(&ScopedUser{}).Scope("OfType").Pretend().Get(&users)
+	assert.EqualError(t, err, "not enough arguments for scope OfType on model ScopedUser")
+	_, err = DB.Model(&ScopedUser{}).Scope("OfType", []int{1}).Pretend().Get(&users)
+	assert.EqualError(t, err, "argument 0 of scope OfType on model ScopedUser should be string,got []int")
+}
+
+func TestGlobalScopesAreMergedWithSoftDelete(t *testing.T) {
+	var users []ScopedUser
+	b := DB.Model(&ScopedUser{})
+	_, err := b.Pretend().Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from `scoped_users` where `visible` = ? and `scoped_users`.`deleted_at` is null", b.PreparedSql)
+}
+
+func TestAddGlobalScope(t *testing.T) {
+	goeloquent.AddGlobalScope(&ScopedUser{}, "Tenant", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("tenant_id", 7)
+	})
+	defer goeloquent.RemoveGlobalScope(&ScopedUser{}, "Tenant")
+
+	var users []ScopedUser
+	b := DB.Model(&ScopedUser{})
+	_, err := b.Pretend().Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from `scoped_users` where `tenant_id` = ? and `visible` = ? and `scoped_users`.`deleted_at` is null", b.PreparedSql)
+	assert.Equal(t, []interface{}{7, 1}, b.GetBindings())
+
+	b = DB.Model(&ScopedUser{}).WithOutGlobalScopes("Tenant")
+	_, err = b.Pretend().Get(&users)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from `scoped_users` where `visible` = ? and `scoped_users`.`deleted_at` is null", b.PreparedSql)
+	assert.Equal(t, []interface{}{1}, b.GetBindings())
+}
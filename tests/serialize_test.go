@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type SerializeUser struct {
+	*goeloquent.EloquentModel
+	ID        int64           `goelo:"column:id;primaryKey"`
+	FirstName string          `goelo:"column:first_name"`
+	LastName  string          `goelo:"column:last_name"`
+	Email     string          `goelo:"column:email"`
+	Password  string          `goelo:"column:password"`
+	Nickname  sql.NullString  `goelo:"column:nickname"`
+	Posts     []SerializePost `goelo:"HasMany:PostsRelation"`
+}
+
+func (u *SerializeUser) TableName() string {
+	return "serialize_users"
+}
+func (u *SerializeUser) ConnectionName() string {
+	return "sqlite"
+}
+func (u *SerializeUser) PostsRelation() *goeloquent.HasManyRelation {
+	return u.HasMany(u, &SerializePost{}, "id", "user_id")
+}
+func (u *SerializeUser) GetFullNameAttribute() interface{} {
+	return u.FirstName + " " + u.LastName
+}
+func (u *SerializeUser) GetEmailAttribute() interface{} {
+	return strings.ToLower(u.Email)
+}
+func (u *SerializeUser) SetPasswordAttribute(value string) {
+	u.Password = "hashed:" + value
+}
+func (u *SerializeUser) EloquentGetAppends() map[string]struct{} {
+	return map[string]struct{}{"full_name": {}}
+}
+func (u *SerializeUser) EloquentGetHidden() map[string]struct{} {
+	return map[string]struct{}{"password": {}}
+}
+
+type SerializePost struct {
+	*goeloquent.EloquentModel
+	ID     int64  `goelo:"column:id;primaryKey"`
+	UserId int64  `goelo:"column:user_id"`
+	Title  string `goelo:"column:title"`
+	Body   string `goelo:"column:body"`
+}
+
+func (p *SerializePost) TableName() string {
+	return "serialize_posts"
+}
+func (p *SerializePost) ConnectionName() string {
+	return "sqlite"
+}
+func (p *SerializePost) EloquentGetVisible() map[string]struct{} {
+	return map[string]struct{}{"id": {}, "title": {}}
+}
+
+func createSerializeTables(t *testing.T) func() {
+	return CreateSqliteTables(t,
+		`create table "serialize_users" ("id" integer primary key autoincrement, "first_name" text, "last_name" text, "email" text, "password" text, "nickname" text)`,
+		`create table "serialize_posts" ("id" integer primary key autoincrement, "user_id" integer, "title" text, "body" text)`,
+	)
+}
+
+func TestFillCallsMutators(t *testing.T) {
+	user := SerializeUser{}
+	goeloquent.Init(&user)
+	user.Fill(map[string]interface{}{"first_name": "John", "password": "secret"})
+	assert.Equal(t, "John", user.FirstName)
+	assert.Equal(t, "hashed:secret", user.Password)
+}
+
+func TestToMapAndToJSON(t *testing.T) {
+	defer createSerializeTables(t)()
+	c := GetSqliteConnection()
+	_, err := c.Table("serialize_users").Insert(map[string]interface{}{"first_name": "John", "last_name": "Doe", "email": "John@Example.com", "password": "x"})
+	assert.Nil(t, err)
+	_, err = c.Table("serialize_posts").Insert([]map[string]interface{}{{"user_id": 1, "title": "a", "body": "aa"}, {"user_id": 1, "title": "b", "body": "bb"}})
+	assert.Nil(t, err)
+
+	var user SerializeUser
+	_, err = DB.Model(&user).With("Posts").Find(&user, 1)
+	assert.Nil(t, err)
+	result := user.ToMap()
+	assert.Equal(t, map[string]interface{}{
+		"id":         int64(1),
+		"first_name": "John",
+		"last_name":  "Doe",
+		"email":      "john@example.com",
+		"nickname":   nil,
+		"full_name":  "John Doe",
+		"posts": []map[string]interface{}{
+			{"id": int64(1), "title": "a"},
+			{"id": int64(2), "title": "b"},
+		},
+	}, result)
+
+	b, err := user.ToJSON()
+	assert.Nil(t, err)
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "John Doe", decoded["full_name"])
+	assert.NotContains(t, decoded, "password")
+
+	//relations not loaded are not included
+	var users []SerializeUser
+	_, err = DB.Model(&SerializeUser{}).WithCount("Posts").Get(&users)
+	assert.Nil(t, err)
+	assert.Len(t, users, 1)
+	result = users[0].ToMap()
+	assert.NotContains(t, result, "posts")
+	assert.Equal(t, float64(2), result["posts_count"])
+}
+
+type BadAppendUser struct {
+	*goeloquent.EloquentModel
+	ID int64 `goelo:"column:id;primaryKey"`
+}
+
+func (u *BadAppendUser) EloquentGetAppends() map[string]struct{} {
+	return map[string]struct{}{"full_name": {}}
+}
+
+func TestSerializeErrors(t *testing.T) {
+	defer createSerializeTables(t)()
+	user := SerializeUser{}
+	goeloquent.Init(&user)
+	user.Fill(map[string]interface{}{"first_name": "John", "password": 1.5})
+	assert.Equal(t, "John", user.FirstName)
+	_, err := user.Save()
+	assert.EqualError(t, err, "mutator SetPasswordAttribute on model SerializeUser needs string,got float64")
+	assert.False(t, user.Exists)
+	//the error is reported once,the corrected model can be saved
+	user.Fill(map[string]interface{}{"password": "secret"})
+	_, err = user.Save()
+	assert.Nil(t, err)
+	assert.True(t, user.Exists)
+
+	assert.False(t, user.IsDirty("missing"))
+	assert.False(t, user.WasChanged("missing"))
+	assert.Nil(t, user.GetOriginal("missing"))
+
+	_, err = goeloquent.ParseModel(&BadAppendUser{})
+	assert.EqualError(t, err, "accessor GetFullNameAttribute for appended attribute full_name on model BadAppendUser not found")
+}
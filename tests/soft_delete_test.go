@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type SoftPost struct {
+	*goeloquent.EloquentModel
+	ID        int64        `goelo:"column:id;primaryKey"`
+	Title     string       `goelo:"column:title"`
+	UpdatedAt sql.NullTime `goelo:"column:updated_at;UPDATED_AT"`
+	DeletedAt sql.NullTime `goelo:"column:deleted_at;DELETED_AT"`
+	Events    []string
+}
+
+func (p *SoftPost) TableName() string {
+	return "soft_posts"
+}
+func (p *SoftPost) ConnectionName() string {
+	return "sqlite"
+}
+func (p *SoftPost) EloquentRestoring() error {
+	p.Events = append(p.Events, "restoring")
+	return nil
+}
+func (p *SoftPost) EloquentRestored() error {
+	p.Events = append(p.Events, "restored")
+	return nil
+}
+func (p *SoftPost) EloquentForceDeleting() error {
+	if p.Title == "keep" {
+		return errors.New("can not force delete")
+	}
+	p.Events = append(p.Events, "forceDeleting")
+	return nil
+}
+func (p *SoftPost) EloquentForceDeleted() error {
+	p.Events = append(p.Events, "forceDeleted")
+	return nil
+}
+
+func createSoftPosts(t *testing.T) func() {
+	drop := CreateSqliteTables(t, `create table "soft_posts" ("id" integer primary key autoincrement, "title" text, "updated_at" datetime, "deleted_at" datetime)`)
+	_, err := GetSqliteConnection().Table("soft_posts").Insert([]map[string]interface{}{{"title": "a"}, {"title": "b"}, {"title": "keep"}})
+	assert.Nil(t, err)
+	return drop
+}
+
+func countSoftPosts(b *goeloquent.EloquentBuilder) (int, error) {
+	var posts []SoftPost
+	_, err := b.Get(&posts)
+	return len(posts), err
+}
+
+func TestSoftDeleteLifecycle(t *testing.T) {
+	defer createSoftPosts(t)()
+	var post SoftPost
+	_, err := DB.Model(&post).Find(&post, 1)
+	assert.Nil(t, err)
+	assert.False(t, post.Trashed())
+
+	_, err = post.Delete()
+	assert.Nil(t, err)
+	assert.True(t, post.Trashed())
+	var count int
+	count, err = countSoftPosts(DB.Model(&SoftPost{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	_, err = DB.Model(&SoftPost{}).OnlyTrashed().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = post.Restore()
+	assert.Nil(t, err)
+	assert.False(t, post.Trashed())
+	assert.Equal(t, []string{"restoring", "restored"}, post.Events)
+	count, err = countSoftPosts(DB.Model(&SoftPost{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	_, err = post.ForceDelete()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"restoring", "restored", "forceDeleting", "forceDeleted"}, post.Events)
+	_, err = DB.Model(&SoftPost{}).WithTrashed().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	var keep SoftPost
+	_, err = DB.Model(&keep).Where("title", "keep").First(&keep)
+	assert.Nil(t, err)
+	_, err = keep.ForceDelete()
+	assert.EqualError(t, err, "can not force delete")
+	_, err = DB.Model(&SoftPost{}).WithTrashed().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestSoftDeleteMassOperations(t *testing.T) {
+	defer createSoftPosts(t)()
+	var count int
+	b := DB.Model(&SoftPost{}).Where("title", "!=", "keep")
+	_, err := b.Delete()
+	assert.Nil(t, err)
+	count, err = countSoftPosts(DB.Model(&SoftPost{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	//mass deletes of soft deletable models keep the rows
+	assert.Regexp(t, `^update "soft_posts" set .*"deleted_at" = \? .*where "title" != \? and "soft_posts"."deleted_at" is null$`, b.PreparedSql)
+	_, err = DB.Model(&SoftPost{}).WithTrashed().Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	res, err := DB.Model(&SoftPost{}).Where("title", "a").Restore()
+	assert.Nil(t, err)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+	count, err = countSoftPosts(DB.Model(&SoftPost{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	//trashed records are excluded unless WithTrashed is used
+	res, err = DB.Model(&SoftPost{}).ForceDelete()
+	assert.Nil(t, err)
+	affected, _ = res.RowsAffected()
+	assert.Equal(t, int64(2), affected)
+	res, err = DB.Model(&SoftPost{}).WithTrashed().ForceDelete()
+	assert.Nil(t, err)
+	affected, _ = res.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+}
+
+func TestRestoreWithoutSoftDelete(t *testing.T) {
+	defer createSaveTables(t)()
+	post := SavePost{Title: "a"}
+	goeloquent.Init(&post)
+	_, err := post.Save()
+	assert.Nil(t, err)
+	_, err = post.Restore()
+	assert.EqualError(t, err, "model: SavePost doesn't use soft delete")
+	_, err = DB.Model(&SavePost{}).Restore()
+	assert.EqualError(t, err, "model: SavePost doesn't use soft delete")
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func GetSqliteBuilder() *goeloquent.Builder {
+	return goeloquent.NewQueryBuilder(&goeloquent.Connection{Config: &goeloquent.DBConfig{Driver: goeloquent.DriverSqlite}})
+}
+
+func TestSqliteBasicSelect(t *testing.T) {
+	b := GetSqliteBuilder()
+	b.Select("id", "name as n").From("users").Where("id", 1).Lock()
+	assert.Equal(t, `select "id", "name" as "n" from "users" where "id" = ?`, b.ToSql())
+}
+
+func TestSqliteDateBasedWheres(t *testing.T) {
+	b := GetSqliteBuilder()
+	b.Select().From("users").WhereDate("created_at", "2021-01-01").WhereDay("created_at", 1).WhereYear("created_at", ">", 2020)
+	assert.Equal(t, `select * from "users" where strftime('%Y-%m-%d', "created_at") = cast(? as text) and cast(strftime('%d', "created_at") as integer) = cast(? as integer) and cast(strftime('%Y', "created_at") as integer) > cast(? as integer)`, b.ToSql())
+}
+
+func TestSqliteJsonSelectors(t *testing.T) {
+	b := GetSqliteBuilder()
+	b.Select("meta->address->city").From("users").Where("items->0->name", "foo")
+	assert.Equal(t, `select json_extract("meta", '$."address"."city"') from "users" where json_extract("items", '$[0]."name"') = ?`, b.ToSql())
+}
+
+func TestSqliteJsonContainsIsNotSupported(t *testing.T) {
+	var names []string
+	_, err := GetSqliteConnection().Table("users").WhereJsonContains("options", []string{"en"}).Pluck(&names, "name")
+	assert.EqualError(t, err, "this database engine does not support json contains operations")
+}
+
+func TestSqliteInsertVariants(t *testing.T) {
+	b := GetSqliteBuilder()
+	b.Pretend()
+	b.From("users").InsertOrIgnore(map[string]interface{}{"name": "foo"})
+	assert.Equal(t, `insert or ignore into "users" ("name") values (?)`, b.PreparedSql)
+
+	b1 := GetSqliteBuilder()
+	b1.From("users")
+	sql := b1.Grammar.CompileUpsert([]map[string]interface{}{{"email": "foo"}}, []string{"email"}, map[string]interface{}{"votes": 1})
+	assert.Equal(t, `insert into "users" ("email") values (?) on conflict ("email") do update set "votes" = ?`, sql)
+	assert.Equal(t, []interface{}{"foo", 1}, b1.GetBindings())
+}
+
+func TestSqliteInMemoryConnection(t *testing.T) {
+	defer CreateSqliteTables(t, `create table "embedded_users" ("id" integer primary key autoincrement, "name" text unique, "meta" text, "created_at" datetime)`)()
+	c := GetSqliteConnection()
+
+	id, err := c.Table("embedded_users").InsertGetId(map[string]interface{}{"name": "john", "meta": `{"age":18}`, "created_at": "2021-05-01 10:00:00"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), id)
+	_, err = c.Table("embedded_users").Insert(map[string]interface{}{"name": "jane", "meta": `{"age":20}`, "created_at": "2022-06-01 10:00:00"})
+	assert.Nil(t, err)
+	_, err = c.Table("embedded_users").InsertOrIgnore(map[string]interface{}{"name": "jane"})
+	assert.Nil(t, err)
+
+	var count int
+	_, err = c.Table("embedded_users").Count(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	var names []string
+	_, err = c.Table("embedded_users").WhereYear("created_at", 2022).WhereMonth("created_at", "6").Pluck(&names, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jane"}, names)
+
+	names = []string{}
+	_, err = c.Table("embedded_users").Where("meta->age", ">", 18).Pluck(&names, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jane"}, names)
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TenantPost struct {
+	*goeloquent.EloquentModel
+	ID       int64  `goelo:"column:id;primaryKey"`
+	TenantID int64  `goelo:"column:tenant_id;TENANT"`
+	Title    string `goelo:"column:title"`
+}
+
+func (p *TenantPost) TableName() string {
+	return "tenant_posts"
+}
+func (p *TenantPost) ConnectionName() string {
+	return "sqlite"
+}
+
+type TenantAuthor struct {
+	*goeloquent.EloquentModel
+	ID    int64        `goelo:"column:id;primaryKey"`
+	Name  string       `goelo:"column:name"`
+	Posts []TenantPost `goelo:"HasMany:PostsRelation"`
+}
+
+func (a *TenantAuthor) TableName() string {
+	return "tenant_authors"
+}
+func (a *TenantAuthor) ConnectionName() string {
+	return "sqlite"
+}
+func (a *TenantAuthor) PostsRelation() *goeloquent.HasManyRelation {
+	return a.HasMany(a, &TenantPost{}, "id", "author_id")
+}
+
+func createTenantPosts(t *testing.T) func() {
+	drop := CreateSqliteTables(t,
+		`create table "tenant_posts" ("id" integer primary key autoincrement, "tenant_id" integer, "author_id" integer, "title" text)`,
+		`create table "tenant_authors" ("id" integer primary key autoincrement, "name" text)`,
+	)
+	c := GetSqliteConnection()
+	_, err := c.Table("tenant_posts").Insert([]map[string]interface{}{
+		{"tenant_id": 1, "author_id": 1, "title": "a"},
+		{"tenant_id": 1, "author_id": 1, "title": "b"},
+		{"tenant_id": 2, "author_id": 2, "title": "c"},
+	})
+	assert.Nil(t, err)
+	_, err = c.Table("tenant_authors").Insert([]map[string]interface{}{{"name": "x"}, {"name": "y"}})
+	assert.Nil(t, err)
+	return drop
+}
+
+func TestTenantScope(t *testing.T) {
+	defer createTenantPosts(t)()
+	ctx := goeloquent.WithTenant(context.Background(), 1)
+	var posts []TenantPost
+	b := DB.Model(&TenantPost{}).WithContext(ctx)
+	_, err := b.Get(&posts)
+	assert.Nil(t, err)
+	assert.Equal(t, `select * from "tenant_posts" where "tenant_posts"."tenant_id" = ?`, b.PreparedSql)
+	assert.Len(t, posts, 2)
+
+	posts = nil
+	_, err = DB.Model(&TenantPost{}).WithContext(ctx).WithoutTenant().Get(&posts)
+	assert.Nil(t, err)
+	assert.Len(t, posts, 3)
+
+	//reads without a tenant id are refused
+	_, err = DB.Model(&TenantPost{}).Get(&posts)
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantMissing))
+	var post TenantPost
+	_, err = DB.Model(&TenantPost{}).First(&post)
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantMissing))
+}
+
+func TestTenantScopeInRelationExistenceQueries(t *testing.T) {
+	defer createTenantPosts(t)()
+	var authors []TenantAuthor
+	b := DB.Model(&TenantAuthor{}).WithContext(goeloquent.WithTenant(context.Background(), 1)).WhereHas("Posts", func(builder *goeloquent.EloquentBuilder) *goeloquent.EloquentBuilder {
+		return builder.Where("title", "a")
+	})
+	_, err := b.Get(&authors)
+	assert.Nil(t, err)
+	assert.Equal(t, `select * from "tenant_authors" where exists (select * from "tenant_posts" where "tenant_authors"."id" = "tenant_posts"."author_id" and "title" = ? and "tenant_posts"."tenant_id" = ?)`, b.PreparedSql)
+	assert.Len(t, authors, 1)
+
+	authors = nil
+	_, err = DB.Model(&TenantAuthor{}).WithContext(goeloquent.WithTenant(context.Background(), 2)).Has("Posts").Get(&authors)
+	assert.Nil(t, err)
+	assert.Len(t, authors, 1)
+	assert.Equal(t, "y", authors[0].Name)
+
+	_, err = DB.Model(&TenantAuthor{}).Has("Posts").Get(&authors)
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantMissing))
+}
+
+func TestTenantIsFilledOnCreate(t *testing.T) {
+	defer createTenantPosts(t)()
+	post := TenantPost{Title: "d"}
+	goeloquent.Init(&post)
+	post.WithContext(goeloquent.WithTenant(context.Background(), int64(2)))
+	_, err := post.Save()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), post.TenantID)
+
+	var posts []TenantPost
+	_, err = DB.Model(&TenantPost{}).WithContext(goeloquent.WithTenant(context.Background(), 2)).Get(&posts)
+	assert.Nil(t, err)
+	assert.Len(t, posts, 2)
+}
+
+func TestTenantWrites(t *testing.T) {
+	defer createTenantPosts(t)()
+	ctx := goeloquent.WithTenant(context.Background(), 1)
+
+	res, err := DB.Model(&TenantPost{}).WithContext(ctx).Update(map[string]interface{}{"title": "x"})
+	assert.Nil(t, err)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(2), affected)
+
+	//stripping the tenant scope without bypassing it is refused
+	_, err = DB.Model(&TenantPost{}).WithContext(ctx).WithOutGlobalScopes().Update(map[string]interface{}{"title": "y"})
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantScopeRemoved))
+	_, err = DB.Model(&TenantPost{}).WithContext(ctx).WithOutGlobalScopes(goeloquent.GlobalScopeTenant).Delete()
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantScopeRemoved))
+	_, err = DB.Model(&TenantPost{}).Delete()
+	assert.True(t, errors.Is(err, goeloquent.ErrTenantMissing))
+
+	var post TenantPost
+	_, err = DB.Model(&TenantPost{}).WithContext(goeloquent.WithTenant(context.Background(), 2)).First(&post)
+	assert.Nil(t, err)
+	//a model loaded for another tenant can't be deleted
+	post.WithContext(ctx)
+	res, err = post.Delete()
+	assert.Nil(t, err)
+	affected, _ = res.RowsAffected()
+	assert.Equal(t, int64(0), affected)
+
+	res, err = DB.Model(&TenantPost{}).WithoutTenant().Where("title", "c").Delete()
+	assert.Nil(t, err)
+	affected, _ = res.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+}
@@ -4,33 +4,59 @@ import (
 	"fmt"
 	"github.com/glitterlip/goeloquent"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"regexp"
 	"strings"
 	"testing"
 )
 
 var DB *goeloquent.DatabaseManager
+var mysqlUnavailable error
 
-func Setup() {
-	fmt.Println("test setup")
-	defaultConfig := map[string]goeloquent.DBConfig{
-		"default": GetDefaultConfig(),
+/*
+RequireMysql skip the test if the mysql test database can't be reached,
+the test fails instead when the CI env is set so the mysql tests can't be skipped there without notice
+*/
+func RequireMysql(t *testing.T) {
+	if mysqlUnavailable == nil {
+		return
 	}
-	DB = goeloquent.Open(defaultConfig)
-	chatConfig := GetChatConfig()
-	DB.AddConfig("chat", &chatConfig)
+	if os.Getenv("CI") != "" {
+		t.Fatal("mysql test database unavailable:", mysqlUnavailable)
+	}
+	t.Skip("mysql test database unavailable:", mysqlUnavailable)
+}
 
-	DB.Listen(goeloquent.EventExecuted, func(result goeloquent.Result) {
-		fmt.Println(result.Sql)
-		fmt.Println(result.Bindings)
-		fmt.Println(result.Time)
-	})
+func Setup() {
+	fmt.Println("test setup")
+	openTestDB()
 }
 func init() {
-	defaultConfig := map[string]goeloquent.DBConfig{
-		"default": GetDefaultConfig(),
-	}
-	DB = goeloquent.Open(defaultConfig)
+	openTestDB()
+}
+
+/*
+openTestDB connect to the mysql test database,if it can't be reached the mysql connections are registered without a database,
+so the sqlite tests still run in-process and the mysql tests which only compile sql still pass
+*/
+func openTestDB() {
+	defaultConfig := GetDefaultConfig()
 	chatConfig := GetChatConfig()
+	db, err := goeloquent.Open(map[string]goeloquent.DBConfig{
+		"default": defaultConfig,
+	})
+	mysqlUnavailable = err
+	if err != nil {
+		db = &goeloquent.DatabaseManager{
+			Configs: map[string]*goeloquent.DBConfig{"default": &defaultConfig},
+			Connections: map[string]*goeloquent.Connection{
+				"default": {Config: &defaultConfig, ConnectionName: "default"},
+				"chat":    {Config: &chatConfig, ConnectionName: "chat"},
+			},
+		}
+		goeloquent.DB = db
+	}
+	DB = db
 	DB.AddConfig("chat", &chatConfig)
 	DB.Listen(goeloquent.EventExecuted, func(result goeloquent.Result) {
 		fmt.Println(result.Sql)
@@ -101,6 +127,40 @@ func RunWithDB(create, drop interface{}, test func()) {
 
 }
 
+func GetSqliteConnection() *goeloquent.Connection {
+	if _, ok := DB.Configs["sqlite"]; !ok {
+		DB.AddConfig("sqlite", &goeloquent.DBConfig{
+			Driver:   goeloquent.DriverSqlite,
+			Database: ":memory:",
+		})
+	}
+	return DB.MustConnection("sqlite")
+}
+
+var createTableName = regexp.MustCompile(`(?i)^\s*create table\s+"?([^"\s(]+)"?`)
+
+/*
+CreateSqliteTables run the statements on the sqlite connection,the returned func drops the created tables in reverse order
+
+	defer CreateSqliteTables(t, `create table "posts" ("id" integer primary key autoincrement, "title" text)`)()
+*/
+func CreateSqliteTables(t *testing.T, statements ...string) func() {
+	c := GetSqliteConnection()
+	var tables []string
+	for _, statement := range statements {
+		_, err := c.Statement(statement, nil)
+		assert.Nil(t, err)
+		if m := createTableName.FindStringSubmatch(statement); m != nil {
+			tables = append(tables, m[1])
+		}
+	}
+	return func() {
+		for i := len(tables) - 1; i >= 0; i-- {
+			c.Statement(fmt.Sprintf(`drop table "%s"`, tables[i]), nil)
+		}
+	}
+}
+
 func UserTableSql() (create, drop string) {
 	create = `
 DROP TABLE IF EXISTS users;
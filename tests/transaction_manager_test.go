@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type TxPost struct {
+	*goeloquent.EloquentModel
+	ID    int64  `goelo:"column:id;primaryKey"`
+	Title string `goelo:"column:title"`
+}
+
+func (p *TxPost) TableName() string {
+	return "tx_posts"
+}
+func (p *TxPost) ConnectionName() string {
+	return "sqlite"
+}
+
+func createTxPosts(t *testing.T) func() {
+	return CreateSqliteTables(t, `create table "tx_posts" ("id" integer primary key autoincrement, "title" text)`)
+}
+
+func txPostTitles(t *testing.T) []string {
+	var titles []string
+	_, err := GetSqliteConnection().Table("tx_posts").OrderBy("id").Pluck(&titles, "title")
+	assert.Nil(t, err)
+	return titles
+}
+
+// createTxPost a service function which wraps its work in a transaction
+func createTxPost(ctx context.Context, title string, fail bool) error {
+	_, err := GetSqliteConnection().TransactionContext(ctx, func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		post := TxPost{Title: title}
+		goeloquent.Init(&post)
+		if _, err := post.WithContext(tx.GetContext()).Save(); err != nil {
+			return goeloquent.Result{}, err
+		}
+		if fail {
+			return goeloquent.Result{}, errors.New("failed")
+		}
+		return goeloquent.Result{}, nil
+	})
+	return err
+}
+
+func TestNestedTransactionsUseSavepoints(t *testing.T) {
+	defer createTxPosts(t)()
+	_, err := GetSqliteConnection().TransactionContext(context.Background(), func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		assert.Equal(t, 1, tx.TransactionLevel())
+		ctx := tx.GetContext()
+		assert.Nil(t, createTxPost(ctx, "a", false))
+		assert.EqualError(t, createTxPost(ctx, "b", true), "failed")
+		_, err := tx.Transaction(func(nested *goeloquent.Transaction) (goeloquent.Result, error) {
+			assert.Equal(t, 2, nested.TransactionLevel())
+			assert.True(t, nested.IsNested())
+			assert.Nil(t, createTxPost(nested.GetContext(), "c", false))
+			panic("nested panic")
+		})
+		assert.EqualError(t, err, "error occurred during transaction")
+		assert.Nil(t, createTxPost(ctx, "d", false))
+		//queries made with the context join the transaction
+		var count int
+		_, err = goeloquent.DB.Model(&TxPost{}).WithContext(ctx).Count(&count)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+		return goeloquent.Result{}, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "d"}, txPostTitles(t))
+}
+
+func TestOuterRollbackDiscardsNestedTransactions(t *testing.T) {
+	defer createTxPosts(t)()
+	tx, err := GetSqliteConnection().BeginTransaction()
+	assert.Nil(t, err)
+	assert.Nil(t, createTxPost(tx.GetContext(), "a", false))
+	nested, err := GetSqliteConnection().BeginTransactionContext(tx.GetContext(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, nested.TransactionLevel())
+	_, err = nested.Table("tx_posts").Insert(map[string]interface{}{"title": "b"})
+	assert.Nil(t, err)
+	assert.Nil(t, nested.Commit())
+	assert.Nil(t, tx.Rollback())
+	assert.Empty(t, txPostTitles(t))
+
+	//a closure returning an error rolls back
+	_, err = GetSqliteConnection().Transaction(func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		_, err := tx.Table("tx_posts").Insert(map[string]interface{}{"title": "c"})
+		assert.Nil(t, err)
+		return goeloquent.Result{}, errors.New("rollback")
+	})
+	assert.EqualError(t, err, "rollback")
+	assert.Empty(t, txPostTitles(t))
+}
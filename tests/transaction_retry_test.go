@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"github.com/glitterlip/goeloquent"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestErrorInspectors(t *testing.T) {
+	inspector := goeloquent.GetErrorInspector(goeloquent.DriverMysql)
+	assert.True(t, inspector.IsDeadlock(fmt.Errorf("save order: %w", &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})))
+	assert.True(t, inspector.IsLockWaitTimeout(&mysql.MySQLError{Number: 1205}))
+	assert.False(t, inspector.IsDeadlock(&mysql.MySQLError{Number: 1062}))
+	assert.True(t, inspector.IsDeadlock(errors.New("Error 1213: Deadlock found when trying to get lock; try restarting transaction")))
+
+	inspector = goeloquent.GetErrorInspector(goeloquent.DriverPostgres)
+	assert.True(t, inspector.IsDeadlock(&pq.Error{Code: "40P01"}))
+	assert.True(t, inspector.IsDeadlock(&pq.Error{Code: "40001"}))
+	assert.True(t, inspector.IsLockWaitTimeout(&pq.Error{Code: "55P03"}))
+	assert.False(t, inspector.IsDeadlock(&pq.Error{Code: "23505"}))
+
+	inspector = goeloquent.GetErrorInspector(goeloquent.DriverSqlite)
+	assert.True(t, inspector.IsLockWaitTimeout(errors.New("database is locked (5) (SQLITE_BUSY)")))
+}
+
+func TestTransactionWithRetry(t *testing.T) {
+	defer createTxPosts(t)()
+	c := GetSqliteConnection()
+	runs := 0
+	_, err := c.TransactionWithRetry(func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		runs++
+		_, err := tx.Table("tx_posts").Insert(map[string]interface{}{"title": fmt.Sprintf("run %d", runs)})
+		assert.Nil(t, err)
+		if runs < 3 {
+			return goeloquent.Result{}, errors.New("database is locked")
+		}
+		return goeloquent.Result{}, nil
+	}, 3, time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, runs)
+	assert.Equal(t, []string{"run 3"}, txPostTitles(t))
+
+	//attempts are exhausted
+	runs = 0
+	_, err = c.TransactionWithRetry(func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		runs++
+		return goeloquent.Result{}, errors.New("database is locked")
+	}, 2, time.Millisecond)
+	assert.EqualError(t, err, "database is locked")
+	assert.Equal(t, 2, runs)
+
+	//other errors are not retried
+	runs = 0
+	_, err = c.TransactionWithRetry(func(tx *goeloquent.Transaction) (goeloquent.Result, error) {
+		runs++
+		return goeloquent.Result{}, errors.New("invalid order")
+	}, 5, time.Millisecond)
+	assert.EqualError(t, err, "invalid order")
+	assert.Equal(t, 1, runs)
+}
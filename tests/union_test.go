@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnions(t *testing.T) {
+	b := GetBuilder()
+	b.Select("name").From("users").Where("id", 1).Union(GetBuilder().Select("name").From("admins").Where("id", 2))
+	assert.Equal(t, "(select `name` from `users` where `id` = ?) union (select `name` from `admins` where `id` = ?)", b.ToSql())
+	assert.Equal(t, []interface{}{1, 2}, b.GetBindings())
+
+	b1 := GetBuilder()
+	b1.Select("name").From("users").UnionAll(func(builder *goeloquent.Builder) {
+		builder.Select("name").From("admins")
+	}).Union(func(builder *goeloquent.Builder) *goeloquent.Builder {
+		return builder.Select("name").From("guests")
+	})
+	assert.Equal(t, "(select `name` from `users`) union all (select `name` from `admins`) union (select `name` from `guests`)", b1.ToSql())
+}
+
+func TestUnionOrdersLimitAndOffset(t *testing.T) {
+	b := GetBuilder()
+	b.Select("name").From("users").OrderBy("id").Limit(5).
+		Union(GetBuilder().Select("name").From("admins")).
+		OrderBy("name", "desc").OrderByRaw("field(name, ?)", []interface{}{"foo"}).Limit(10).Offset(20)
+	assert.Equal(t, "(select `name` from `users` order by `id` asc limit 5) union (select `name` from `admins`) order by `name` desc, field(name, ?) limit 10 offset 20", b.ToSql())
+	assert.Equal(t, []interface{}{"foo"}, b.GetBindings())
+
+	b.ReOrder()
+	assert.Equal(t, "(select `name` from `users` limit 5) union (select `name` from `admins`) limit 10 offset 20", b.ToSql())
+}
+
+func TestUnionAggregate(t *testing.T) {
+	b := GetBuilder()
+	b.Pretend()
+	var count int
+	b.Select("name").From("users").Union(GetBuilder().Select("name").From("admins")).Count(&count)
+	assert.Equal(t, "select count(*) as aggregate from ((select `name` from `users`) union (select `name` from `admins`)) as `temp_table`", b.PreparedSql)
+}
+
+func TestUnionDialects(t *testing.T) {
+	b := GetPostgresBuilder()
+	b.Select("name").From("users").Where("id", 1).Union(GetPostgresBuilder().Select("name").From("admins").Where("id", 2)).Limit(10)
+	assert.Equal(t, `(select "name" from "users" where "id" = $1) union (select "name" from "admins" where "id" = $2) limit 10`, b.ToSql())
+
+	b1 := GetSqliteBuilder()
+	b1.Select("name").From("users").UnionAll(GetSqliteBuilder().Select("name").From("admins")).OrderBy("name")
+	assert.Equal(t, `select * from (select "name" from "users") union all select * from (select "name" from "admins") order by "name" asc`, b1.ToSql())
+}
+
+func TestUnionPaginate(t *testing.T) {
+	defer CreateSqliteTables(t,
+		`create table "union_posts" ("id" integer primary key autoincrement, "title" text)`,
+		`create table "union_comments" ("id" integer primary key autoincrement, "body" text)`,
+	)()
+	c := GetSqliteConnection()
+	_, err := c.Table("union_posts").Insert([]map[string]interface{}{{"title": "a"}, {"title": "c"}, {"title": "e"}})
+	assert.Nil(t, err)
+	_, err = c.Table("union_comments").Insert([]map[string]interface{}{{"body": "b"}, {"body": "d"}})
+	assert.Nil(t, err)
+
+	var feed []string
+	p, err := c.Table("union_posts").Select("title as content").
+		UnionAll(c.Table("union_comments").Select("body as content")).
+		OrderBy("content").Paginate(&feed, 2, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), p.Total)
+	assert.Equal(t, []string{"c", "d"}, feed)
+}
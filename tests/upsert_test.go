@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"database/sql"
+	"github.com/glitterlip/goeloquent"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type UpsertUser struct {
+	*goeloquent.EloquentModel
+	ID        int64        `goelo:"column:id;primaryKey"`
+	Email     string       `goelo:"column:email"`
+	Votes     int          `goelo:"column:votes"`
+	CreatedAt sql.NullTime `goelo:"column:created_at;CREATED_AT"`
+	UpdatedAt sql.NullTime `goelo:"column:updated_at;UPDATED_AT"`
+}
+
+func (u *UpsertUser) TableName() string {
+	return "upsert_users"
+}
+func (u *UpsertUser) ConnectionName() string {
+	return "sqlite"
+}
+
+func TestUpsertCompile(t *testing.T) {
+	b := GetBuilder()
+	b.Pretend()
+	b.From("users").Upsert(map[string]interface{}{"email": "foo"}, []string{"email"}, nil)
+	//nothing left to update once the unique columns are skipped
+	assert.Equal(t, "insert ignore into `users` (`email`) values (?)", b.PreparedSql)
+	assert.Equal(t, []interface{}{"foo"}, b.GetBindings())
+
+	b1 := GetBuilder()
+	b1.Pretend()
+	b1.From("users").Upsert([]map[string]interface{}{{"email": "foo"}, {"email": "bar"}}, []string{"email"}, map[string]interface{}{"votes": 1})
+	assert.Equal(t, "insert into `users` (`email`) values (?), (?) on duplicate key update `votes` = ?", b1.PreparedSql)
+	assert.Equal(t, []interface{}{"foo", "bar", 1}, b1.GetBindings())
+
+	b2 := GetBuilder()
+	b2.Pretend()
+	b2.From("users").Upsert(map[string]interface{}{"email": "foo"}, []string{"email"}, map[string]interface{}{"votes": goeloquent.Raw("votes + 1")})
+	assert.Equal(t, "insert into `users` (`email`) values (?) on duplicate key update `votes` = votes + 1", b2.PreparedSql)
+	assert.Equal(t, []interface{}{"foo"}, b2.GetBindings())
+
+	//nil update columns skip the unique ones and are sorted,so are the map keys
+	b3 := GetBuilder()
+	b3.Pretend()
+	b3.From("users").Upsert(map[string]interface{}{"email": "foo", "votes": 1, "name": "bar", "age": 2}, []string{"email"}, nil)
+	assert.Contains(t, b3.PreparedSql, " on duplicate key update `age` = values(`age`), `name` = values(`name`), `votes` = values(`votes`)")
+
+	b4 := GetBuilder()
+	b4.Pretend()
+	b4.From("users").Upsert(map[string]interface{}{"email": "foo"}, []string{"email"}, map[string]interface{}{"votes": 1, "name": "bar", "age": 2})
+	assert.Equal(t, "insert into `users` (`email`) values (?) on duplicate key update `age` = ?, `name` = ?, `votes` = ?", b4.PreparedSql)
+	assert.Equal(t, []interface{}{"foo", 2, "bar", 1}, b4.GetBindings())
+
+	b5 := GetBuilder()
+	b5.Pretend()
+	b5.From("users").Upsert(map[string]interface{}{"email": "foo"}, []string{"email"}, "votes")
+	assert.NotNil(t, b5.Err)
+	assert.Equal(t, "upsert update columns must be nil,[]string or map[string]interface{},got string", b5.Err.Error())
+}
+
+func TestUpsertModels(t *testing.T) {
+	defer CreateSqliteTables(t, `create table "upsert_users" ("id" integer primary key autoincrement, "email" text unique, "votes" integer, "created_at" datetime, "updated_at" datetime)`)()
+	c := GetSqliteConnection()
+
+	_, err := c.Table("upsert_users").Upsert([]map[string]interface{}{{"email": "foo", "votes": 1}}, []string{"email"}, []string{"votes"})
+	assert.Nil(t, err)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	users := []*UpsertUser{
+		{Email: "foo", Votes: 2, CreatedAt: sql.NullTime{Time: yesterday, Valid: true}},
+		{Email: "bar", Votes: 3},
+	}
+	_, err = DB.Model(&UpsertUser{}).Upsert(&users, []string{"email"}, []string{"votes"})
+	assert.Nil(t, err)
+
+	var result []UpsertUser
+	_, err = DB.Model(&UpsertUser{}).OrderBy("email").Get(&result)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "bar", result[0].Email)
+	assert.Equal(t, 3, result[0].Votes)
+	assert.True(t, result[0].CreatedAt.Valid)
+	assert.Equal(t, "foo", result[1].Email)
+	assert.Equal(t, 2, result[1].Votes)
+	//created_at is kept while updated_at is refreshed on conflict
+	assert.False(t, result[1].CreatedAt.Valid)
+	assert.True(t, result[1].UpdatedAt.Valid)
+}
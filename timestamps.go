@@ -1,5 +1,11 @@
 package goeloquent
 
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
 type HasTimestamps interface {
 	//Update the model's update timestamp
 	Touch() bool
@@ -9,3 +15,67 @@ type HasTimestamps interface {
 	GetCreatedAtColumn() Field
 	GetUpdatedAtColumn() Field
 }
+
+/*
+upsertAttributes get the column values of a model for an upsert,the primary key is skipped when it's empty
+*/
+func (m *Model) upsertAttributes(model reflect.Value) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(m.FieldsByDbName))
+	for column, field := range m.FieldsByDbName {
+		value := model.Field(field.Index)
+		if m.PrimaryKey != nil && column == m.PrimaryKey.ColumnName && value.IsZero() {
+			continue
+		}
+		v := value.Interface()
+		if valuer, ok := v.(driver.Valuer); ok {
+			v, _ = valuer.Value()
+		}
+		attrs[column] = v
+	}
+	return attrs
+}
+
+/*
+addTimestampsToUpsert fill empty CreatedAt/UpdatedAt values and make sure UpdatedAt is updated,
+returns the update columns to use
+*/
+func (m *Model) addTimestampsToUpsert(items []map[string]interface{}, uniqueColumns []string, updateColumns interface{}) interface{} {
+	now := time.Now()
+	for _, item := range items {
+		for _, column := range []string{m.CreatedAt, m.UpdatedAt} {
+			if column == "" {
+				continue
+			}
+			if v, ok := item[column]; !ok || v == nil || reflect.ValueOf(v).IsZero() {
+				item[column] = now
+			}
+		}
+	}
+	switch columns := updateColumns.(type) {
+	case nil:
+		skipped := append([]string{m.CreatedAt}, uniqueColumns...)
+		if m.PrimaryKey != nil {
+			skipped = append(skipped, m.PrimaryKey.ColumnName)
+		}
+		return upsertUpdateColumns(items, skipped)
+	case []string:
+		if m.UpdatedAt != "" {
+			for _, column := range columns {
+				if column == m.UpdatedAt {
+					return columns
+				}
+			}
+			return append(append([]string{}, columns...), m.UpdatedAt)
+		}
+	case map[string]interface{}:
+		if _, ok := columns[m.UpdatedAt]; !ok && m.UpdatedAt != "" {
+			update := make(map[string]interface{}, len(columns)+1)
+			for column, value := range columns {
+				update[column] = value
+			}
+			update[m.UpdatedAt] = now
+			return update
+		}
+	}
+	return updateColumns
+}
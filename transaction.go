@@ -1,32 +1,65 @@
 package goeloquent
 
 import (
+	"context"
 	"database/sql"
+	"sync"
+	"time"
 )
 
 type Transaction struct {
 	*sql.Tx
 	ConnectionName string
 	*Connection
+	Context context.Context //context the transaction began with carrying the transaction,used by builders from Query()
+	Level   int             //1 for a database transaction,2 and above for savepoints
+	Parent  *Transaction    //the transaction a savepoint is created in
+
+	callbacksMu   sync.Mutex
+	afterCommit   []func()
+	afterRollback []func()
 }
 
 type TxClosure func(tx *Transaction) (Result, error)
 
 func (t *Transaction) Select(query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
+	return t.SelectContext(t.GetContext(), query, bindings, dest, mapping)
+}
+
+/*
+SelectContext run a select statement within the transaction,the query is cancelled with ctx
+*/
+func (t *Transaction) SelectContext(ctx context.Context, query string, bindings []interface{}, dest interface{}, mapping map[string]interface{}) (result Result, err error) {
 	var stmt *sql.Stmt
 	var rows *sql.Rows
-	stmt, err = t.Tx.Prepare(query)
+	now := time.Now()
+	defer func() {
+		t.logQuery(ctx, query, bindings, now, &result, err)
+	}()
+	stmt, err = t.Tx.PrepareContext(ctx, query)
 	if err != nil {
+		err = t.queryError(query, bindings, err)
 		return
 	}
 	defer stmt.Close()
-	rows, err = stmt.Query(bindings...)
+	DB.FireEvent(EventStatementPrepared, StatementPreparedEvent{ConnectionName: t.ConnectionName, Sql: query, Bindings: bindings, Transaction: t})
+	rows, err = stmt.QueryContext(ctx, bindings...)
 	if err != nil {
+		err = t.queryError(query, bindings, err)
 		return
 	}
 	defer rows.Close()
 
-	return ScanAll(rows, dest, mapping), nil
+	result = ScanAll(rows, dest, mapping)
+	result.Sql = query
+	result.Bindings = bindings
+	result.Time = time.Since(now)
+	if result.Error != nil {
+		err = t.queryError(query, bindings, result.Error)
+		result.Error = err
+	}
+	DB.FireEvent(EventExecuted, result)
+	return result, err
 }
 
 func (t *Transaction) Insert(query string, bindings []interface{}) (result Result, err error) {
@@ -41,22 +74,40 @@ func (t *Transaction) Delete(query string, bindings []interface{}) (result Resul
 }
 
 func (t *Transaction) AffectingStatement(query string, bindings []interface{}) (result Result, err error) {
-	stmt, errP := t.Tx.Prepare(query)
+	return t.AffectingStatementContext(t.GetContext(), query, bindings)
+}
+
+/*
+AffectingStatementContext run a statement within the transaction,the query is cancelled with ctx
+*/
+func (t *Transaction) AffectingStatementContext(ctx context.Context, query string, bindings []interface{}) (result Result, err error) {
+	now := time.Now()
+	defer func() {
+		t.logQuery(ctx, query, bindings, now, &result, err)
+	}()
+	stmt, errP := t.Tx.PrepareContext(ctx, query)
 	if errP != nil {
-		err = errP
+		err = t.queryError(query, bindings, errP)
 		return
 	}
 	defer stmt.Close()
-	rawResult, err := stmt.Exec(bindings...)
+	DB.FireEvent(EventStatementPrepared, StatementPreparedEvent{ConnectionName: t.ConnectionName, Sql: query, Bindings: bindings, Transaction: t})
+	rawResult, err := stmt.ExecContext(ctx, bindings...)
 	if err != nil {
+		err = t.queryError(query, bindings, err)
 		return
 	}
 
-	return Result{
-		Count: 0,
-		Raw:   rawResult,
-		Error: nil,
-	}, nil
+	result = Result{
+		Count:    0,
+		Raw:      rawResult,
+		Error:    nil,
+		Sql:      query,
+		Time:     time.Since(now),
+		Bindings: bindings,
+	}
+	DB.FireEvent(EventExecuted, result)
+	return result, nil
 }
 
 func (t *Transaction) Table(tableName string) *Builder {
@@ -75,29 +126,97 @@ func (t *Transaction) Query() *Builder {
 func (t *Transaction) GetConfig() *DBConfig {
 	return t.Config
 }
+
+/*
+GetContext get the context the transaction began with
+*/
+func (t *Transaction) GetContext() context.Context {
+	if t.Context == nil {
+		return context.Background()
+	}
+	return t.Context
+}
 func NewTxBuilder(tx *Transaction) *Builder {
 	b := Builder{
 		Components: make(map[string]struct{}),
 		Tx:         tx,
 		Bindings:   make(map[string][]interface{}),
+		Context:    tx.GetContext(),
 	}
-	b.Grammar = &MysqlGrammar{}
+	b.SetGrammar(NewGrammar(tx.Config.Driver))
 	b.Grammar.SetTablePrefix(tx.Config.Prefix)
-	b.Grammar.SetBuilder(&b)
 	return &b
 }
 
+/*
+Commit commit the transaction,a savepoint is released
+*/
 func (t *Transaction) Commit() error {
-
+	if t.IsNested() {
+		_, err := t.Tx.ExecContext(t.GetContext(), "RELEASE SAVEPOINT "+t.savepointName())
+		if err == nil {
+			t.Parent.mergeCallbacks(t)
+		}
+		return err
+	}
 	err := t.Tx.Commit()
-	DB.FireEvent(EventTransactionCommitted, err)
+	DB.FireEvent(EventTransactionCommitted, TransactionCommittedEvent{Transaction: t, Error: err})
+	if err == nil {
+		t.runAfterCommit()
+	} else {
+		t.runAfterRollback()
+	}
 	return err
 }
+
+/*
+Rollback roll back the transaction,a savepoint only rolls back the changes made after it
+*/
 func (t *Transaction) Rollback() error {
+	if t.IsNested() {
+		_, err := t.Tx.ExecContext(t.GetContext(), "ROLLBACK TO SAVEPOINT "+t.savepointName())
+		if err == nil {
+			t.runAfterRollback()
+		}
+		return err
+	}
 	err := t.Tx.Rollback()
-	DB.FireEvent(EventTransactionRollback, err)
+	DB.FireEvent(EventTransactionRollback, TransactionRollbackEvent{Transaction: t, Error: err})
+	t.runAfterRollback()
 	return err
 }
+
+/*
+BeginTransaction create a savepoint in the transaction
+*/
+func (t *Transaction) BeginTransaction() (*Transaction, error) {
+	return t.beginSavepoint(t.GetContext())
+}
+
+/*
+BeginTransactionContext create a savepoint in the transaction
+*/
+func (t *Transaction) BeginTransactionContext(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return t.beginSavepoint(ctx)
+}
+
+/*
+Transaction execute the closure in a savepoint,only the changes made in the closure are rolled back if it fails
+*/
+func (t *Transaction) Transaction(closure TxClosure) (interface{}, error) {
+	return t.TransactionContext(t.GetContext(), closure)
+}
+
+/*
+TransactionContext execute the closure in a savepoint created with ctx
+*/
+func (t *Transaction) TransactionContext(ctx context.Context, closure TxClosure) (interface{}, error) {
+	nested, err := t.beginSavepoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runTransaction(nested, closure)
+}
 func (t *Transaction) Model(model interface{}) *EloquentBuilder {
 	return NewEloquentBuilder(model)
 }